@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/flow"
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type BanCoinRequest struct {
+	ExchangeParam
+
+	Body struct {
+		CoinID   string `minLength:"1"`
+		Duration string `doc:"Ban duration (e.g. 72h)"`
+		Note     string `required:"false"`
+	}
+}
+
+type BanCoinResponse struct {
+	Body *BannedCoinBody `doc:"Body" json:"body"`
+}
+
+type UnbanCoinRequest struct {
+	ExchangeParam
+
+	CoinID string `path:"coinID"`
+}
+
+type UnbanCoinResponse struct {
+}
+
+type AllowedCoinBody struct {
+	CoinID    string
+	AllowedAt time.Time
+	Note      string
+}
+
+func NewAllowedCoinBody(allowedCoin *domain.AllowedCoin) *AllowedCoinBody {
+	return &AllowedCoinBody{
+		CoinID:    string(allowedCoin.CoinID()),
+		AllowedAt: allowedCoin.AllowedAt(),
+		Note:      allowedCoin.Note(),
+	}
+}
+
+type ListAllowedCoinsBody struct {
+	AllowedCoins []*AllowedCoinBody
+}
+
+type ListAllowedCoinsRequest struct {
+	ExchangeParam
+}
+
+type ListAllowedCoinsResponse struct {
+	Body *ListAllowedCoinsBody `doc:"Body" json:"body"`
+}
+
+type AllowCoinRequest struct {
+	ExchangeParam
+
+	CoinID string `path:"coinID"`
+	Body   struct {
+		Note string `required:"false"`
+	}
+}
+
+type AllowCoinResponse struct {
+	Body *AllowedCoinBody `doc:"Body" json:"body"`
+}
+
+type DisallowCoinRequest struct {
+	ExchangeParam
+
+	CoinID string `path:"coinID"`
+}
+
+type DisallowCoinResponse struct {
+}
+
+func AddAdminRoutes(api huma.API, service *flow.Service, prohibitor *prohibitor.Prohibitor) { //nolint:funlen
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID:   "ban.coin",
+		Summary:       "Ban coin",
+		Method:        http.MethodPost,
+		Path:          "/admin/banned-coins",
+		DefaultStatus: http.StatusCreated,
+	}, func(ctx context.Context, input *BanCoinRequest) (*BanCoinResponse, error) {
+		period, err := time.ParseDuration(input.Body.Duration)
+		if err != nil || period <= 0 {
+			return nil, huma.Error400BadRequest("invalid duration", err)
+		}
+		ret, err := prohibitor.BanCoin(ctx, input.QualifyCoinID(input.Body.CoinID), period, input.Body.Note)
+		if err != nil {
+			return nil, problem(err)
+		}
+		resp := &BanCoinResponse{
+			Body: NewBannedCoinBody(ret),
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID:   "unban.coin",
+		Summary:       "Unban coin",
+		Method:        http.MethodDelete,
+		Path:          "/admin/banned-coins/{coinID}",
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *UnbanCoinRequest) (*UnbanCoinResponse, error) {
+		err := prohibitor.UnbanCoin(ctx, input.QualifyCoinID(input.CoinID))
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &UnbanCoinResponse{}, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "list.allowed-coins",
+		Summary:     "List allowed coins",
+		Method:      http.MethodGet,
+		Path:        "/admin/allowed-coins",
+	}, func(ctx context.Context, input *ListAllowedCoinsRequest) (*ListAllowedCoinsResponse, error) {
+		ret, err := service.ListAllowedCoins(ctx)
+		if err != nil {
+			return nil, problem(err)
+		}
+		var allowedCoins []*AllowedCoinBody
+		for _, allowedCoin := range ret {
+			if !input.Match(allowedCoin.CoinID()) {
+				continue
+			}
+			allowedCoins = append(allowedCoins, NewAllowedCoinBody(allowedCoin))
+		}
+		resp := &ListAllowedCoinsResponse{
+			Body: &ListAllowedCoinsBody{
+				AllowedCoins: allowedCoins,
+			},
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "allow.coin",
+		Summary:     "Add coin to allowlist",
+		Method:      http.MethodPut,
+		Path:        "/admin/allowed-coins/{coinID}",
+	}, func(ctx context.Context, input *AllowCoinRequest) (*AllowCoinResponse, error) {
+		ret, err := prohibitor.AllowCoin(ctx, input.QualifyCoinID(input.CoinID), input.Body.Note)
+		if err != nil {
+			return nil, problem(err)
+		}
+		resp := &AllowCoinResponse{
+			Body: NewAllowedCoinBody(ret),
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID:   "disallow.coin",
+		Summary:       "Remove coin from allowlist",
+		Method:        http.MethodDelete,
+		Path:          "/admin/allowed-coins/{coinID}",
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *DisallowCoinRequest) (*DisallowCoinResponse, error) {
+		err := prohibitor.DisallowCoin(ctx, input.QualifyCoinID(input.CoinID))
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &DisallowCoinResponse{}, nil
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/flow"
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAdminRoutes_BanAndUnban(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("bithumb:KRW-BTC", time.Now()))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+	_, api := humatest.New(t)
+	AddAdminRoutes(api, flow.NewService(repo), p)
+
+	banned := api.Post("/admin/banned-coins?exchange=bithumb", map[string]any{
+		"CoinID":   "KRW-BTC",
+		"Duration": "1h",
+		"Note":     "maintenance",
+	})
+	unbanned := api.Delete("/admin/banned-coins/KRW-BTC?exchange=bithumb")
+	unbannedAgain := api.Delete("/admin/banned-coins/KRW-BTC?exchange=bithumb")
+
+	require.Equal(t, http.StatusCreated, banned.Code, banned.Body.String())
+	require.Contains(t, banned.Body.String(), `"CoinID":"bithumb:KRW-BTC"`)
+	require.Equal(t, http.StatusNoContent, unbanned.Code, unbanned.Body.String())
+	require.Equal(t, http.StatusNotFound, unbannedAgain.Code, unbannedAgain.Body.String())
+	_, err = repo.GetBannedCoin(ctx, "bithumb:KRW-BTC")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+}
+
+func TestAdminRoutes_BanRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+	repo := realrepositorytest.New(t)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+	_, api := humatest.New(t)
+	AddAdminRoutes(api, flow.NewService(repo), p)
+	tests := map[string]struct {
+		body map[string]any
+		want int
+	}{
+		"unknown coin":     {body: map[string]any{"CoinID": "KRW-NONE", "Duration": "1h"}, want: http.StatusNotFound},
+		"empty coin":       {body: map[string]any{"CoinID": "", "Duration": "1h"}, want: http.StatusUnprocessableEntity},
+		"invalid duration": {body: map[string]any{"CoinID": "KRW-BTC", "Duration": "soon"}, want: http.StatusBadRequest},
+		"negative period":  {body: map[string]any{"CoinID": "KRW-BTC", "Duration": "-1h"}, want: http.StatusBadRequest},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := api.Post("/admin/banned-coins", test.body)
+
+			require.Equal(t, test.want, resp.Code, resp.Body.String())
+		})
+	}
+}
+
+func TestAdminRoutes_AllowAndDisallow(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	reasons := []domain.BanReason{domain.BanReasonCaution}
+	_, err := repo.CreateBannedCoin(ctx, domain.NewBannedCoin("upbit:KRW-BTC", time.Now(), time.Hour, reasons))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+	_, api := humatest.New(t)
+	AddAdminRoutes(api, flow.NewService(repo), p)
+
+	allowed := api.Put("/admin/allowed-coins/KRW-BTC", map[string]any{"Note": "listed on purpose"})
+	allowedAgain := api.Put("/admin/allowed-coins/KRW-BTC", map[string]any{"Note": "again"})
+	listed := api.Get("/admin/allowed-coins")
+	disallowed := api.Delete("/admin/allowed-coins/KRW-BTC")
+	disallowedAgain := api.Delete("/admin/allowed-coins/KRW-BTC")
+
+	require.Equal(t, http.StatusOK, allowed.Code, allowed.Body.String())
+	require.Equal(t, http.StatusOK, allowedAgain.Code, allowedAgain.Body.String())
+	require.JSONEq(t, allowed.Body.String(), allowedAgain.Body.String())
+	require.Contains(t, listed.Body.String(), `"CoinID":"upbit:KRW-BTC"`)
+	require.Equal(t, http.StatusNoContent, disallowed.Code, disallowed.Body.String())
+	require.Equal(t, http.StatusNotFound, disallowedAgain.Code, disallowedAgain.Body.String())
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-BTC")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+}
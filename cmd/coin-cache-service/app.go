@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/app/broadcaster"
+	"github.com/biosvos/coin-cache-service/internal/app/deadletter"
+	"github.com/biosvos/coin-cache-service/internal/app/flow"
+	"github.com/biosvos/coin-cache-service/internal/app/miner"
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/app/relay"
+	"github.com/biosvos/coin-cache-service/internal/app/trader"
+	"github.com/biosvos/coin-cache-service/internal/app/watcher"
+	"github.com/biosvos/coin-cache-service/internal/pkg/bithumb"
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	natsbus "github.com/biosvos/coin-cache-service/internal/pkg/buses/nats"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinservice"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/badger"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/sqliterepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/telemetry"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// serviceName trace와 이벤트 봉투에 남기는 서비스 이름.
+const serviceName = "coin-cache-service"
+
+// application 서비스를 구성하는 컴포넌트를 묶는다.
+// 거래소마다 miner와 trader를 하나씩 둔다.
+type application struct {
+	tracer      *telemetry.Tracer
+	repo        coinrepository.CoinRepository
+	closeRepo   func()
+	bus         eventBus
+	miners      []*miner.Miner
+	traders     []*trader.Trader
+	prohibitor  *prohibitor.Prohibitor
+	watcher     *watcher.Watcher // 업비트를 캐시하지 않으면 nil이다.
+	broadcaster *broadcaster.Broadcaster
+	relay       *relay.Relay
+	deadLetters *deadletter.Service
+	flow        *flow.Service
+}
+
+func newApplication(ctx context.Context, logger *zap.Logger, options *Options) (*application, error) {
+	traderOptions, err := parseSchedules(options.Intervals)
+	if err != nil {
+		return nil, err
+	}
+	traderOptions = append(traderOptions, trader.WithBackfill(options.BackfillHorizon))
+	quotes, err := parseQuotes(options.Quotes)
+	if err != nil {
+		return nil, err
+	}
+	prohibitorOptions, err := parsePriceRules(options.PriceRules)
+	if err != nil {
+		return nil, err
+	}
+	exchanges, err := parseExchanges(options.Exchanges)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer, err := telemetry.NewTracer(ctx, serviceName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	repo, closeRepo, err := newRepository(ctx, logger, options)
+	if err != nil {
+		return nil, err
+	}
+	eventBus, err := newEventBus(ctx, tracer, logger, options, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &application{
+		tracer:      tracer,
+		repo:        repo,
+		closeRepo:   closeRepo,
+		bus:         eventBus,
+		miners:      nil,
+		traders:     nil,
+		prohibitor:  prohibitor.NewProhibitor(tracer, logger, eventBus, repo, prohibitorOptions...),
+		watcher:     nil,
+		broadcaster: broadcaster.NewBroadcaster(logger, eventBus, repo),
+		relay:       relay.NewRelay(logger, eventBus, repo),
+		deadLetters: deadletter.NewService(repo, eventBus),
+		flow:        flow.NewService(repo),
+	}
+	for _, exchange := range exchanges {
+		service := newCoinService(exchange, tracer, quotes)
+		ret.miners = append(ret.miners, miner.NewMiner(tracer, logger, service, repo))
+		ret.traders = append(ret.traders, trader.NewTrader(tracer, logger, eventBus, service, repo, traderOptions...))
+		if exchange == domain.ExchangeUpbit {
+			ret.watcher = watcher.NewWatcher(logger, eventBus, repo, upbit.NewTickerStream(upbit.WithTracer(tracer)))
+		}
+	}
+	return ret, nil
+}
+
+// eventBus 발행을 확인할 수 있고, 실패한 이벤트를 다시 전달할 수 있고, 멈출 때 닫는 버스.
+type eventBus interface {
+	bus.Bus
+	relay.Publisher
+	deadletter.Redeliverer
+	Close()
+}
+
+// newEventBus nats를 고르면 miner, trader, prohibitor를 서로 다른 프로세스로 띄울 수 있다.
+func newEventBus(
+	ctx context.Context,
+	tracer tracer.Tracer,
+	logger *zap.Logger,
+	options *Options,
+	repo coinrepository.CoinRepository,
+) (eventBus, error) {
+	retryPolicy := bus.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = options.RetryAttempts
+	retryPolicy.InitialBackoff = options.RetryBackoff
+	retryPolicy.MaxBackoff = options.RetryMaxBackoff
+	switch options.Bus {
+	case "local":
+		return local.NewBus(
+			logger,
+			local.WithAsync(options.BusQueueSize, options.BusWorkers),
+			local.WithRetryPolicy(retryPolicy),
+			local.WithDeadLetters(repo),
+			local.WithTracer(tracer, serviceName),
+		), nil
+	case "nats":
+		ret, err := natsbus.NewBus(
+			ctx,
+			logger,
+			options.NatsURL,
+			natsbus.WithRetryPolicy(retryPolicy),
+			natsbus.WithRetention(options.NatsMaxAge, options.NatsMaxBytes),
+			natsbus.WithDeadLetters(repo),
+			natsbus.WithTracer(tracer, serviceName),
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return ret, nil
+	default:
+		return nil, errors.Errorf("unknown bus %q", options.Bus)
+	}
+}
+
+// newRepository 저장소와 함께 저장소를 닫는 함수를 반환한다.
+// badger는 이전 스키마로 저장된 레코드를 시작할 때 현재 버전으로 다시 쓴다.
+func newRepository(
+	ctx context.Context,
+	logger *zap.Logger,
+	options *Options,
+) (coinrepository.CoinRepository, func(), error) {
+	switch options.Repository {
+	case "badger":
+		repo, closeRepo, err := openBadgerRepository(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		results, err := repo.Migrate(ctx, false)
+		if err != nil {
+			closeRepo()
+			return nil, nil, err
+		}
+		for _, result := range results {
+			if result.Upgraded > 0 {
+				logger.Info("migrate records", zap.String("kind", result.Kind), zap.Int("upgraded", result.Upgraded))
+			}
+		}
+		return repo, closeRepo, nil
+	case "sqlite":
+		repo, err := sqliterepository.NewRepository(ctx, options.SqlitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, func() { _ = repo.Close() }, nil
+	default:
+		return nil, nil, errors.Errorf("unknown repository %q", options.Repository)
+	}
+}
+
+func openBadgerRepository(options *Options) (*realrepository.Repository, func(), error) {
+	kv, err := badger.NewStore(options.BadgerPath)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	repo, err := realrepository.NewRepository(kv)
+	if err != nil {
+		kv.Close()
+		return nil, nil, err
+	}
+	return repo, kv.Close, nil
+}
+
+func newCoinService(exchange domain.Exchange, tracer *telemetry.Tracer, quotes []domain.Quote) coinservice.CoinService {
+	switch exchange {
+	case domain.ExchangeBithumb:
+		return bithumb.NewService(bithumb.WithQuotes(quotes...))
+	default:
+		return upbit.NewService(upbit.WithTracer(tracer), upbit.WithQuotes(quotes...))
+	}
+}
+
+func (a *application) Start(ctx context.Context) error {
+	// 처음 채우는 코인의 이벤트도 기록한다.
+	err := a.broadcaster.Start(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, miner := range a.miners {
+		err := miner.Start()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, trader := range a.traders {
+		err = trader.Start(ctx)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	err = a.prohibitor.Start(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if a.watcher != nil {
+		err = a.watcher.Start(ctx)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	// 모두 구독한 뒤에 멈춘 동안 쌓인 이벤트를 발행한다.
+	err = a.relay.Start(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Stop 이벤트를 만들거나 발행하는 miner, trader, prohibitor, watcher를 먼저 멈추고 relay, 버스 순으로 멈춘다.
+// 버스를 닫은 뒤에는 아무도 발행하지 않는다. 그동안 outbox에 기록된 이벤트는 다음에 시작할 때 발행한다.
+func (a *application) Stop() {
+	for _, miner := range a.miners {
+		miner.Stop()
+	}
+	for _, trader := range a.traders {
+		trader.Stop()
+	}
+	a.prohibitor.Stop()
+	if a.watcher != nil {
+		a.watcher.Stop()
+	}
+	a.relay.Stop()
+	a.bus.Close()
+	a.broadcaster.Stop()
+	a.closeRepo()
+	a.tracer.Shutdown()
+}
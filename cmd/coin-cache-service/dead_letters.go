@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/deadletter"
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/pkg/errors"
+)
+
+type DeliveryAttemptBody struct {
+	AttemptedAt time.Time
+	Error       string
+}
+
+type DeadLetterBody struct {
+	ID           string
+	Subscription string
+	EventID      string `json:",omitempty"`
+	Topic        string
+	Payload      json.RawMessage
+	Attempts     []*DeliveryAttemptBody
+	FailedAt     time.Time
+}
+
+func NewDeadLetterBody(deadLetter *domain.DeadLetter) *DeadLetterBody {
+	attempts := []*DeliveryAttemptBody{}
+	for _, attempt := range deadLetter.Attempts() {
+		attempts = append(attempts, &DeliveryAttemptBody{
+			AttemptedAt: attempt.AttemptedAt(),
+			Error:       attempt.Error(),
+		})
+	}
+	return &DeadLetterBody{
+		ID:           deadLetter.ID(),
+		Subscription: deadLetter.Subscription(),
+		EventID:      deadLetter.EventID(),
+		Topic:        deadLetter.Topic(),
+		Payload:      deadLetter.Payload(),
+		Attempts:     attempts,
+		FailedAt:     deadLetter.FailedAt(),
+	}
+}
+
+type ListDeadLettersBody struct {
+	DeadLetters []*DeadLetterBody
+}
+
+type ListDeadLettersRequest struct {
+	Topic string `doc:"Only dead letters of this topic" query:"topic" required:"false"`
+}
+
+type ListDeadLettersResponse struct {
+	Body *ListDeadLettersBody `doc:"Body" json:"body"`
+}
+
+type GetDeadLetterRequest struct {
+	ID string `path:"id"`
+}
+
+type GetDeadLetterResponse struct {
+	Body *DeadLetterBody `doc:"Body" json:"body"`
+}
+
+type ReplayDeadLetterRequest struct {
+	ID string `path:"id"`
+}
+
+type ReplayDeadLetterResponse struct {
+}
+
+type DiscardDeadLetterRequest struct {
+	ID string `path:"id"`
+}
+
+type DiscardDeadLetterResponse struct {
+}
+
+func AddDeadLetterRoutes(api huma.API, service *deadletter.Service) { //nolint:funlen
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "list.dead-letters",
+		Summary:     "List events that exhausted their retries",
+		Method:      http.MethodGet,
+		Path:        "/admin/dead-letters",
+	}, func(ctx context.Context, input *ListDeadLettersRequest) (*ListDeadLettersResponse, error) {
+		ret, err := service.ListDeadLetters(ctx)
+		if err != nil {
+			return nil, problem(err)
+		}
+		deadLetters := []*DeadLetterBody{}
+		for _, deadLetter := range ret {
+			if input.Topic != "" && deadLetter.Topic() != input.Topic {
+				continue
+			}
+			deadLetters = append(deadLetters, NewDeadLetterBody(deadLetter))
+		}
+		resp := &ListDeadLettersResponse{
+			Body: &ListDeadLettersBody{
+				DeadLetters: deadLetters,
+			},
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "get.dead-letter",
+		Summary:     "Get dead letter with its attempt history",
+		Method:      http.MethodGet,
+		Path:        "/admin/dead-letters/{id}",
+	}, func(ctx context.Context, input *GetDeadLetterRequest) (*GetDeadLetterResponse, error) {
+		ret, err := service.GetDeadLetter(ctx, input.ID)
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &GetDeadLetterResponse{Body: NewDeadLetterBody(ret)}, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID:   "replay.dead-letter",
+		Summary:       "Redeliver dead letter to the subscription that failed it",
+		Method:        http.MethodPost,
+		Path:          "/admin/dead-letters/{id}/replay",
+		DefaultStatus: http.StatusAccepted,
+	}, func(ctx context.Context, input *ReplayDeadLetterRequest) (*ReplayDeadLetterResponse, error) {
+		err := service.Replay(ctx, input.ID)
+		if err != nil {
+			if errors.Is(err, bus.ErrSubscriptionNotFound) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+			return nil, problem(err)
+		}
+		return &ReplayDeadLetterResponse{}, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID:   "discard.dead-letter",
+		Summary:       "Discard dead letter",
+		Method:        http.MethodDelete,
+		Path:          "/admin/dead-letters/{id}",
+		DefaultStatus: http.StatusNoContent,
+	}, func(ctx context.Context, input *DiscardDeadLetterRequest) (*DiscardDeadLetterResponse, error) {
+		err := service.Discard(ctx, input.ID)
+		if err != nil {
+			return nil, problem(err)
+		}
+		return &DiscardDeadLetterResponse{}, nil
+	})
+}
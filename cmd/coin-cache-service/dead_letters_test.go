@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/deadletter"
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDeadLetterRoutes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	attempts := []*domain.DeliveryAttempt{domain.NewDeliveryAttempt(time.Now(), "timeout")}
+	for _, deadLetter := range []*domain.DeadLetter{
+		domain.NewDeadLetter("1", "trader", "", domain.CoinCreatedEventTopic, []byte(`{}`), attempts, time.Now()),
+		domain.NewDeadLetter("2", "gone", "", domain.CoinCreatedEventTopic, []byte(`{}`), attempts, time.Now()),
+		domain.NewDeadLetter("3", "trader", "", domain.CoinDeletedEventTopic, []byte(`{}`), attempts, time.Now()),
+	} {
+		require.NoError(t, repo.CreateDeadLetter(ctx, deadLetter))
+	}
+	b := local.NewBus(zap.NewNop())
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, _ domain.Event) error {
+		return nil
+	}, bus.WithName("trader")))
+	_, api := humatest.New(t)
+	AddDeadLetterRoutes(api, deadletter.NewService(repo, b))
+
+	listed := api.Get("/admin/dead-letters?topic=" + domain.CoinCreatedEventTopic)
+	got := api.Get("/admin/dead-letters/1")
+	replayed := api.Post("/admin/dead-letters/1/replay")
+	conflicted := api.Post("/admin/dead-letters/2/replay")
+	replayedUnknown := api.Post("/admin/dead-letters/none/replay")
+	discarded := api.Delete("/admin/dead-letters/3")
+	discardedAgain := api.Delete("/admin/dead-letters/3")
+	gotUnknown := api.Get("/admin/dead-letters/none")
+
+	require.Equal(t, http.StatusOK, listed.Code, listed.Body.String())
+	require.Contains(t, listed.Body.String(), `"ID":"1"`)
+	require.Contains(t, listed.Body.String(), `"ID":"2"`)
+	require.NotContains(t, listed.Body.String(), `"ID":"3"`)
+	require.Equal(t, http.StatusOK, got.Code, got.Body.String())
+	require.Contains(t, got.Body.String(), `"Error":"timeout"`)
+	require.Equal(t, http.StatusAccepted, replayed.Code, replayed.Body.String())
+	require.Equal(t, http.StatusConflict, conflicted.Code, conflicted.Body.String())
+	require.Equal(t, http.StatusNotFound, replayedUnknown.Code, replayedUnknown.Body.String())
+	require.Equal(t, http.StatusNoContent, discarded.Code, discarded.Body.String())
+	require.Equal(t, http.StatusNotFound, discardedAgain.Code, discardedAgain.Body.String())
+	require.Equal(t, http.StatusNotFound, gotUnknown.Code, gotUnknown.Body.String())
+	_, err := repo.GetDeadLetter(ctx, "1")
+	require.ErrorIs(t, err, coinrepository.ErrDeadLetterNotFound)
+	_, err = repo.GetDeadLetter(ctx, "2")
+	require.NoError(t, err)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/pkg/errors"
+)
+
+// problem 저장소 오류를 RFC 7807 문제 응답으로 바꾼다.
+// 없는 레코드는 404, 이미 있거나 동시에 고친 레코드는 409로 응답한다. 그 밖의 오류는 내용을 숨기고 500으로 응답한다.
+func problem(err error) error {
+	switch {
+	case errors.Is(err, coinrepository.ErrNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, coinrepository.ErrAlreadyExists), errors.Is(err, coinrepository.ErrConflict):
+		return huma.Error409Conflict(err.Error())
+	default:
+		return huma.Error500InternalServerError(http.StatusText(http.StatusInternalServerError))
+	}
+}
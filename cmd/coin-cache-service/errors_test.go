@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/flow"
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/memory"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProblem(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	repo := realrepositorytest.Open(t, store)
+	require.NoError(t, store.Create(realrepository.BannedCoinKey("upbit:KRW-BROKEN"), []byte(`{"id":`)))
+	_, err := repo.CreateCoin(context.Background(), domain.NewCoin("upbit:KRW-BTC", time.Now()))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+	_, api := humatest.New(t)
+	AddRoutes(api, flow.NewService(repo))
+	AddAdminRoutes(api, flow.NewService(repo), p)
+	ban := map[string]any{"CoinID": "KRW-BTC", "Duration": "1h"}
+	tests := map[string]struct {
+		method string
+		path   string
+		body   map[string]any
+		want   int
+		detail string
+	}{
+		"not found": {
+			method: http.MethodGet,
+			path:   "/candles/KRW-NONE",
+			body:   nil,
+			want:   http.StatusNotFound,
+			detail: "trades not found",
+		},
+		"already exists": {
+			method: http.MethodPost,
+			path:   "/admin/banned-coins",
+			body:   ban,
+			want:   http.StatusConflict,
+			detail: "banned coin already exists",
+		},
+		"internal": {
+			method: http.MethodGet,
+			path:   "/banned-coins/KRW-BROKEN",
+			body:   nil,
+			want:   http.StatusInternalServerError,
+			detail: http.StatusText(http.StatusInternalServerError),
+		},
+	}
+	require.Equal(t, http.StatusCreated, api.Post("/admin/banned-coins", ban).Code)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := api.Do(test.method, test.path, test.body)
+
+			require.Equal(t, test.want, resp.Code, resp.Body.String())
+			require.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+			var model huma.ErrorModel
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &model))
+			require.Equal(t, test.want, model.Status)
+			require.Equal(t, http.StatusText(test.want), model.Title)
+			require.Contains(t, model.Detail, test.detail)
+			require.Empty(t, model.Errors)
+		})
+	}
+}
+
+func TestProblem_HidesInternalError(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	repo := realrepositorytest.Open(t, store)
+	require.NoError(t, store.Create(realrepository.BannedCoinKey("upbit:KRW-BROKEN"), []byte(`{"id":`)))
+	_, api := humatest.New(t)
+	AddRoutes(api, flow.NewService(repo))
+
+	resp := api.Get("/banned-coins/KRW-BROKEN")
+	listed := api.Get("/banned-coins")
+
+	require.Equal(t, http.StatusInternalServerError, resp.Code)
+	require.Equal(t, http.StatusInternalServerError, listed.Code)
+	for _, body := range []string{resp.Body.String(), listed.Body.String()} {
+		require.NotContains(t, body, "JSON")
+		require.NotContains(t, body, "KRW-BROKEN")
+		require.NotContains(t, body, "banned")
+	}
+	_, err := repo.GetBannedCoin(context.Background(), "upbit:KRW-BROKEN")
+	require.Error(t, err)
+}
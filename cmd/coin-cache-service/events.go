@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/broadcaster"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/go-chi/chi/v5"
+)
+
+// AddEventRoutes huma는 응답을 한 번에 쓰므로 SSE 스트림은 chi에 직접 등록한다.
+//
+//	GET /events?topic=banned_coin.created&topic=coin.created
+//
+// Last-Event-ID 헤더를 보내면 그 다음 이벤트부터 이어서 받는다. 그 사이의 이벤트가 로그에서 이미 지워졌다면
+// 먼저 reset 이벤트를 보낸다. 받은 쪽은 상태를 새로 읽어야 한다.
+func AddEventRoutes(router chi.Router, b *broadcaster.Broadcaster, heartbeat time.Duration) {
+	router.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		serveEvents(w, r, b, heartbeat)
+	})
+}
+
+func serveEvents(w http.ResponseWriter, r *http.Request, b *broadcaster.Broadcaster, heartbeat time.Duration) {
+	topics, err := parseTopics(r.URL.Query()["topic"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var lastEventID uint64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		lastEventID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+	backlog, subscription, err := b.Subscribe(r.Context(), topics, lastEventID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer b.Unsubscribe(subscription)
+
+	controller := http.NewResponseController(w)
+	_ = controller.SetWriteDeadline(time.Time{}) // 스트림은 서버의 WriteTimeout보다 오래 열려 있다.
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if subscription.ResetID != 0 {
+		writeReset(w, subscription.ResetID)
+	}
+	for _, event := range backlog {
+		writeEvent(w, event)
+	}
+	if controller.Flush() != nil {
+		return
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			writeEvent(w, event)
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		if controller.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *domain.LoggedEvent) {
+	_, _ = fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %s\n\n", event.ID(), event.Topic(), event.Payload())
+}
+
+// writeReset 다시 연결하면 남아 있는 로그부터 받도록 id를 옮긴다. data가 비면 EventSource가 무시하므로 채운다.
+func writeReset(w http.ResponseWriter, id uint64) {
+	_, _ = fmt.Fprintf(w, "id: %v\nevent: reset\ndata: {}\n\n", id)
+}
+
+// parseTopics topic은 여러 번 쓰거나 쉼표로 이을 수 있다.
+func parseTopics(values []string) ([]string, error) {
+	var ret []string
+	for _, value := range values {
+		for _, topic := range strings.Split(value, ",") {
+			topic = strings.TrimSpace(topic)
+			if topic == "" {
+				continue
+			}
+			if !slices.Contains(domain.Topics(), topic) {
+				return nil, fmt.Errorf("unknown topic: %v", topic) //nolint:err113
+			}
+			ret = append(ret, topic)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/broadcaster"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newEventServer 이벤트 스트림만 여는 서버와 이벤트를 발행할 버스.
+func newEventServer(t *testing.T, heartbeat time.Duration) (*httptest.Server, *local.Bus) {
+	t.Helper()
+	logger := zap.NewNop()
+	bus := local.NewBus(logger)
+	b := broadcaster.NewBroadcaster(logger, bus, realrepositorytest.New(t))
+	require.NoError(t, b.Start(context.Background()))
+	router := chi.NewMux()
+	AddEventRoutes(router, b, heartbeat)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	t.Cleanup(b.Stop)
+	return server, bus
+}
+
+// openEvents 스트림을 열고 한 줄씩 읽을 수 있게 한다. 테스트가 끝나면 연결을 끊는다.
+func openEvents(t *testing.T, url string, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// readFrame 빈 줄까지 읽어 한 프레임의 줄들을 반환한다.
+func readFrame(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var ret []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return ret
+		}
+		ret = append(ret, line)
+	}
+}
+
+func eventFrame(id int, topic string, coinID domain.CoinID) []string {
+	return []string{
+		fmt.Sprintf("id: %v", id),
+		"event: " + topic,
+		fmt.Sprintf(`data: {"coin_id":%q}`, coinID),
+	}
+}
+
+func TestEventRoutes_ResumesAfterLastEventID(t *testing.T) {
+	t.Parallel()
+	server, bus := newEventServer(t, time.Hour)
+	ctx := context.Background()
+	bus.Publish(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-A"))
+	bus.Publish(ctx, domain.NewBannedCoinCreatedEvent("upbit:KRW-A"))
+	bus.Publish(ctx, domain.NewBannedCoinCreatedEvent("upbit:KRW-B"))
+	bus.Publish(ctx, domain.NewBannedCoinDeletedEvent("upbit:KRW-A"))
+
+	resp, reader := openEvents(t, server.URL+"/events?topic=banned_coin.created,banned_coin.deleted", "2")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Equal(t, eventFrame(3, domain.BannedCoinCreatedEventTopic, "upbit:KRW-B"), readFrame(t, reader))
+	require.Equal(t, eventFrame(4, domain.BannedCoinDeletedEventTopic, "upbit:KRW-A"), readFrame(t, reader))
+	bus.Publish(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-C"))
+	bus.Publish(ctx, domain.NewBannedCoinCreatedEvent("upbit:KRW-C"))
+	require.Equal(t, eventFrame(6, domain.BannedCoinCreatedEventTopic, "upbit:KRW-C"), readFrame(t, reader))
+}
+
+func TestEventRoutes_ResetsWhenLastEventIDIsTrimmed(t *testing.T) {
+	t.Parallel()
+	server, bus := newEventServer(t, time.Hour)
+	ctx := context.Background()
+	for i := range coinrepository.EventLogSize + 2 {
+		bus.Publish(ctx, domain.NewBannedCoinCreatedEvent(domain.CoinID(fmt.Sprintf("upbit:KRW-%v", i+1))))
+	}
+
+	resp, reader := openEvents(t, server.URL+"/events", "1")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"id: 2", "event: reset", "data: {}"}, readFrame(t, reader))
+	require.Equal(t, eventFrame(3, domain.BannedCoinCreatedEventTopic, "upbit:KRW-3"), readFrame(t, reader))
+}
+
+func TestEventRoutes_Heartbeat(t *testing.T) {
+	t.Parallel()
+	server, _ := newEventServer(t, 10*time.Millisecond)
+
+	resp, reader := openEvents(t, server.URL+"/events", "")
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{": heartbeat"}, readFrame(t, reader))
+}
+
+func TestEventRoutes_BadRequest(t *testing.T) {
+	t.Parallel()
+	server, _ := newEventServer(t, time.Hour)
+	tests := map[string]struct {
+		query       string
+		lastEventID string
+	}{
+		"unknown topic":          {query: "?topic=coin.created,coin.exploded", lastEventID: ""},
+		"invalid last event id":  {query: "", lastEventID: "abc"},
+		"negative last event id": {query: "", lastEventID: "-1"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, _ := openEvents(t, server.URL+"/events"+test.query, test.lastEventID)
+
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestParseTopics(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		values []string
+		want   []string
+	}{
+		"none":      {values: nil, want: nil},
+		"repeated":  {values: []string{"coin.created", "coin.deleted"}, want: []string{"coin.created", "coin.deleted"}},
+		"separated": {values: []string{"coin.created, coin.deleted,"}, want: []string{"coin.created", "coin.deleted"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			topics, err := parseTopics(test.values)
+
+			require.NoError(t, err)
+			require.Equal(t, test.want, topics)
+		})
+	}
+}
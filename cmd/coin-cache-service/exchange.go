@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+// ExchangeParam 모든 요청이 받는 거래소 필터.
+type ExchangeParam struct {
+	Exchange string `doc:"Only this exchange; unqualified coin IDs belong to it (default upbit)" enum:"upbit,bithumb" query:"exchange" required:"false"`
+}
+
+// QualifyCoinID 거래소가 붙지 않은 coinID에 요청한 거래소를 붙인다.
+func (e *ExchangeParam) QualifyCoinID(coinID string) domain.CoinID {
+	return domain.ParseCoinID(coinID, domain.Exchange(e.Exchange))
+}
+
+// Match 거래소를 지정하지 않았다면 모든 코인이 해당한다.
+func (e *ExchangeParam) Match(coinID domain.CoinID) bool {
+	return e.Exchange == "" || coinID.Exchange() == domain.Exchange(e.Exchange)
+}
@@ -5,34 +5,82 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/app/flow"
-	"github.com/biosvos/coin-cache-service/internal/app/miner"
-	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
-	"github.com/biosvos/coin-cache-service/internal/app/trader"
-	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
-	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository"
-	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
-	"github.com/biosvos/coin-cache-service/pkg/tracer/telemetry"
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 	"github.com/danielgtaylor/huma/v2/humacli"
 	"github.com/go-chi/chi/v5"
-	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 type Options struct {
-	Port int `default:"8888" help:"Port to listen on" short:"p"`
+	Port      int    `default:"8888" help:"Port to listen on" short:"p"`
+	Intervals string `default:"1d=10m" doc:"Candle intervals to cache, with optional refresh cadence (e.g. 1d=10m,15m)"`
+
+	BackfillHorizon time.Duration `default:"0s" doc:"How far back to backfill candle history (e.g. 17520h for 2 years, 0 to disable)"`
+
+	Exchanges  string `default:"upbit"          doc:"Exchanges to cache (e.g. upbit,bithumb)"`
+	Quotes     string `default:"KRW"            doc:"Quote currencies whose markets are cached (e.g. KRW,BTC,USDT)"`
+	PriceRules string `default:"KRW=100:100000" doc:"Allowed last price range per quote, min:max with either side optional (e.g. KRW=100:100000,USDT=0.1:)"`
+
+	Repository string `default:"badger"        doc:"Storage backend (badger, sqlite)"`
+	BadgerPath string `default:"/tmp/coins"    doc:"Badger directory used when repository is badger"`
+	SqlitePath string `default:"/tmp/coins.db" doc:"SQLite database file used when repository is sqlite"`
+
+	EventHeartbeat time.Duration `default:"15s" doc:"Interval between heartbeat comments on the /events stream"`
+
+	Bus          string `default:"local"                 doc:"Event bus, local runs in process and nats shares events through JetStream (local, nats)"`
+	NatsURL      string `default:"nats://127.0.0.1:4222" doc:"NATS server URL used when bus is nats"`
+	BusQueueSize int    `default:"1024"                  doc:"Events queued per subscriber before publishers wait"`
+	BusWorkers   int    `default:"1"                     doc:"Goroutines handling events per subscriber (events of a topic stay in order)"`
+
+	NatsMaxAge   time.Duration `default:"168h" doc:"How long the NATS stream keeps events (0 keeps them until max bytes is reached)"`
+	NatsMaxBytes int64         `default:"-1"   doc:"Size limit of the NATS stream, oldest events are dropped first (-1 for no limit)"`
+
+	RetryAttempts   int           `default:"5"  doc:"Attempts per event before it is moved to the dead-letter store"`
+	RetryBackoff    time.Duration `default:"1s" doc:"Wait after the first failed attempt, doubled on each further failure"`
+	RetryMaxBackoff time.Duration `default:"1m" doc:"Upper bound on the wait between attempts"`
+}
+
+type CoinBody struct {
+	CoinID      string
+	KoreanName  string
+	EnglishName string
+	Warning     bool
+	Cautions    []string
+	ModifiedAt  time.Time
+}
+
+func NewCoinBody(coin *domain.Coin) *CoinBody {
+	cautions := []string{}
+	for _, caution := range coin.Cautions() {
+		cautions = append(cautions, string(caution))
+	}
+	return &CoinBody{
+		CoinID:      string(coin.ID()),
+		KoreanName:  coin.KoreanName(),
+		EnglishName: coin.EnglishName(),
+		Warning:     coin.IsWarning(),
+		Cautions:    cautions,
+		ModifiedAt:  coin.ModifiedAt(),
+	}
 }
 
 type ListCoinsBody struct {
-	Coins []string
+	Coins   []string
+	Details []*CoinBody `json:",omitempty"`
 }
 
 type ListCoinsRequest struct {
+	ExchangeParam
+
+	View  string `default:"ids" doc:"ids returns coin IDs only, full also returns names and warnings" enum:"ids,full" query:"view"`
+	Quote string `doc:"Only coins traded in this quote currency (e.g. KRW)" query:"quote" required:"false"`
 }
 
 type ListCoinsResponse struct {
@@ -49,6 +97,8 @@ type ListTradesBody struct {
 }
 
 type ListTradesRequest struct {
+	ExchangeParam
+
 	CoinID string `path:"coinID"`
 }
 
@@ -56,21 +106,127 @@ type ListTradesResponse struct {
 	Body *ListTradesBody `doc:"Body" json:"body"`
 }
 
-func AddRoutes(api huma.API, service *flow.Service) {
+type CandleBody struct {
+	Date             time.Time
+	Open             string
+	High             string
+	Low              string
+	Close            string
+	Volume           string
+	TradedValue      string
+	PrevClosingPrice string
+	ChangePrice      string
+	ChangeRate       string
+}
+
+func NewCandleBody(trade *domain.Trade) *CandleBody {
+	return &CandleBody{
+		Date:             trade.Date(),
+		Open:             string(trade.OpeningPrice()),
+		High:             string(trade.MaxPrice()),
+		Low:              string(trade.MinPrice()),
+		Close:            string(trade.LastPrice()),
+		Volume:           string(trade.Volume()),
+		TradedValue:      string(trade.TradedValue()),
+		PrevClosingPrice: string(trade.PrevClosingPrice()),
+		ChangePrice:      string(trade.ChangePrice()),
+		ChangeRate:       string(trade.ChangeRate()),
+	}
+}
+
+type ListCandlesBody struct {
+	Candles []*CandleBody
+}
+
+type ListCandlesRequest struct {
+	ExchangeParam
+
+	CoinID   string    `path:"coinID"`
+	Interval string    `default:"1d" doc:"Candle interval (1m, 3m, 5m, 15m, 30m, 60m, 240m, 1d, 1w, 1mo)" query:"interval"`
+	From     time.Time `doc:"Only candles at or after this time (RFC 3339)" query:"from" required:"false"`
+	To       time.Time `doc:"Only candles at or before this time (RFC 3339)" query:"to" required:"false"`
+	Limit    int       `default:"200" doc:"Most recent candles to return" maximum:"1000" minimum:"1" query:"limit"`
+}
+
+type ListCandlesResponse struct {
+	Body *ListCandlesBody `doc:"Body" json:"body"`
+}
+
+type BannedCoinBody struct {
+	CoinID    string
+	BannedAt  time.Time
+	ExpiredAt time.Time
+	Period    string
+	Reasons   []string
+	Note      string
+}
+
+func NewBannedCoinBody(bannedCoin *domain.BannedCoin) *BannedCoinBody {
+	var reasons []string
+	for _, reason := range bannedCoin.Reasons() {
+		reasons = append(reasons, string(reason))
+	}
+	return &BannedCoinBody{
+		CoinID:    string(bannedCoin.CoinID()),
+		BannedAt:  bannedCoin.BannedAt(),
+		ExpiredAt: bannedCoin.ExpiredAt(),
+		Period:    bannedCoin.Period().String(),
+		Reasons:   reasons,
+		Note:      bannedCoin.Note(),
+	}
+}
+
+type ListBannedCoinsBody struct {
+	BannedCoins []*BannedCoinBody
+}
+
+type ListBannedCoinsRequest struct {
+	ExchangeParam
+}
+
+type ListBannedCoinsResponse struct {
+	Body *ListBannedCoinsBody `doc:"Body" json:"body"`
+}
+
+type GetBannedCoinRequest struct {
+	ExchangeParam
+
+	CoinID string `path:"coinID"`
+}
+
+type GetBannedCoinResponse struct {
+	Body *BannedCoinBody `doc:"Body" json:"body"`
+}
+
+func AddRoutes(api huma.API, service *flow.Service) { //nolint:funlen
 	huma.Register(api, huma.Operation{ //nolint:exhaustruct
 		OperationID: "list.coins",
 		Summary:     "List coins",
 		Method:      http.MethodGet,
 		Path:        "/coins",
-	}, func(ctx context.Context, _ *ListCoinsRequest) (*ListCoinsResponse, error) {
-		ret, err := service.ListCoins(ctx)
+	}, func(ctx context.Context, input *ListCoinsRequest) (*ListCoinsResponse, error) {
+		ret, err := service.ListCoinDetails(ctx)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, problem(err)
+		}
+		body := &ListCoinsBody{
+			Coins:   nil,
+			Details: nil,
+		}
+		for _, coin := range ret {
+			if !input.Match(coin.ID()) {
+				continue
+			}
+			if input.Quote != "" && !strings.EqualFold(string(coin.Quote()), input.Quote) {
+				continue
+			}
+			body.Coins = append(body.Coins, string(coin.ID()))
+			if input.View == "full" {
+				body.Details = append(body.Details, NewCoinBody(coin))
+			}
 		}
 		resp := &ListCoinsResponse{
-			Body: &ListCoinsBody{
-				Coins: ret,
-			},
+			Body: body,
 		}
 		return resp, nil
 	})
@@ -80,9 +236,9 @@ func AddRoutes(api huma.API, service *flow.Service) {
 		Method:      http.MethodGet,
 		Path:        "/trades/{coinID}",
 	}, func(ctx context.Context, input *ListTradesRequest) (*ListTradesResponse, error) {
-		ret, err := service.ListTrades(ctx, domain.CoinID(input.CoinID))
+		ret, err := service.ListTrades(ctx, input.QualifyCoinID(input.CoinID), domain.IntervalDay)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, problem(err)
 		}
 		var trades []*TradeBody
 		for _, trade := range ret.Trades() {
@@ -98,6 +254,74 @@ func AddRoutes(api huma.API, service *flow.Service) {
 		}
 		return resp, nil
 	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "list.candles",
+		Summary:     "List candles",
+		Method:      http.MethodGet,
+		Path:        "/candles/{coinID}",
+	}, func(ctx context.Context, input *ListCandlesRequest) (*ListCandlesResponse, error) {
+		interval, err := domain.ParseInterval(input.Interval)
+		if err != nil {
+			return nil, huma.Error400BadRequest("invalid interval", err)
+		}
+		ret, err := service.ListTradesInRange(ctx, input.QualifyCoinID(input.CoinID), interval, coinrepository.TradeRange{
+			From:  input.From,
+			To:    input.To,
+			Limit: input.Limit,
+		})
+		if err != nil {
+			return nil, problem(err)
+		}
+		var candles []*CandleBody
+		for _, trade := range ret.Trades() {
+			candles = append(candles, NewCandleBody(trade))
+		}
+		resp := &ListCandlesResponse{
+			Body: &ListCandlesBody{
+				Candles: candles,
+			},
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "list.banned-coins",
+		Summary:     "List banned coins",
+		Method:      http.MethodGet,
+		Path:        "/banned-coins",
+	}, func(ctx context.Context, input *ListBannedCoinsRequest) (*ListBannedCoinsResponse, error) {
+		ret, err := service.ListBannedCoins(ctx)
+		if err != nil {
+			return nil, problem(err)
+		}
+		var bannedCoins []*BannedCoinBody
+		for _, bannedCoin := range ret {
+			if !input.Match(bannedCoin.CoinID()) {
+				continue
+			}
+			bannedCoins = append(bannedCoins, NewBannedCoinBody(bannedCoin))
+		}
+		resp := &ListBannedCoinsResponse{
+			Body: &ListBannedCoinsBody{
+				BannedCoins: bannedCoins,
+			},
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "get.banned-coin",
+		Summary:     "Get banned coin",
+		Method:      http.MethodGet,
+		Path:        "/banned-coins/{coinID}",
+	}, func(ctx context.Context, input *GetBannedCoinRequest) (*GetBannedCoinResponse, error) {
+		ret, err := service.GetBannedCoin(ctx, input.QualifyCoinID(input.CoinID))
+		if err != nil {
+			return nil, problem(err)
+		}
+		resp := &GetBannedCoinResponse{
+			Body: NewBannedCoinBody(ret),
+		}
+		return resp, nil
+	})
 }
 
 func main() {
@@ -110,79 +334,79 @@ func main() {
 	}()
 	ctx := context.Background()
 
-	tracer, err := telemetry.NewTracer(ctx, "coin-cache-service")
-	if err != nil {
-		panic(err)
-	}
-	defer tracer.Shutdown()
-
-	service := upbit.NewService()
-	repo := realrepository.NewRepository("/tmp/coins")
-	bus := local.NewBus(logger)
-
-	mine := miner.NewMiner(tracer, logger, service, repo, bus)
-	err = mine.Start()
-	if err != nil {
-		panic(err)
-	}
-	defer mine.Stop()
-
-	trader := trader.NewTrader(tracer, logger, bus, service, repo)
-	trader.Start(ctx)
-	defer trader.Stop()
-
-	prohibitor := prohibitor.NewProhibitor(logger, bus, repo)
-	err = prohibitor.Start(ctx)
-	if err != nil {
-		panic(err)
-	}
-	defer prohibitor.Stop()
-
-	flowService := flow.NewService(repo)
-
-	cli := newClient(ctx, flowService)
+	cli := newClient(ctx, logger)
 
 	cli.Run()
 }
 
-func newClient(ctx context.Context, flowService *flow.Service) humacli.CLI {
-	return humacli.New(func(hooks humacli.Hooks, options *Options) {
-		router := chi.NewMux()
-		api := humachi.New(router, huma.DefaultConfig("My API", "1.0.0"))
-
-		AddRoutes(api, flowService)
-
-		const (
-			readTimeout       = 5 * time.Second
-			writeTimeout      = 5 * time.Second
-			idleTimeout       = 30 * time.Second
-			readHeaderTimeout = 2 * time.Second
-			giveUpTimeout     = 5 * time.Second
-		)
-		server := &http.Server{ //nolint:exhaustruct
-			ReadTimeout:       readTimeout,
-			WriteTimeout:      writeTimeout,
-			IdleTimeout:       idleTimeout,
-			ReadHeaderTimeout: readHeaderTimeout,
-
-			Addr:    fmt.Sprintf(":%v", options.Port),
-			Handler: router,
-		}
+func newClient(ctx context.Context, logger *zap.Logger) humacli.CLI {
+	ret := humacli.New(func(hooks humacli.Hooks, options *Options) {
+		// 하위 명령에서도 불리므로 서비스는 시작할 때 만든다.
+		var app *application
+		var server *http.Server
+		ready := make(chan struct{})
 
 		hooks.OnStart(func() {
-			err := server.ListenAndServe()
+			var err error
+			app, err = newApplication(ctx, logger, options)
+			if err != nil {
+				panic(err)
+			}
+			server = newServer(app, options)
+			close(ready)
+			err = app.Start(ctx)
+			if err != nil {
+				panic(err)
+			}
+			err = server.ListenAndServe()
 			if err != nil {
 				log.Printf("failed to start server: %v", err)
 			}
 		})
 
 		hooks.OnStop(func() {
+			const giveUpTimeout = 5 * time.Second
+			<-ready
 			ctx, cancel := context.WithTimeout(ctx, giveUpTimeout)
 			defer cancel()
 			err := server.Shutdown(ctx)
 			if err != nil {
 				log.Printf("failed to shutdown server: %v", err)
 			}
+			app.Stop()
 		})
 	})
+	ret.Root().AddCommand(newMigrateCommand(ctx))
+	return ret
+}
+
+func newServer(app *application, options *Options) *http.Server {
+	router := chi.NewMux()
+	api := humachi.New(router, huma.DefaultConfig("My API", "1.0.0"))
+
+	AddRoutes(api, app.flow)
+	AddAdminRoutes(api, app.flow, app.prohibitor)
+	AddDeadLetterRoutes(api, app.deadLetters)
+	if app.watcher != nil {
+		AddTickerRoutes(api, app.watcher)
+	}
+	AddEventRoutes(router, app.broadcaster, options.EventHeartbeat)
+
+	const (
+		readTimeout       = 5 * time.Second
+		writeTimeout      = 5 * time.Second
+		idleTimeout       = 30 * time.Second
+		readHeaderTimeout = 2 * time.Second
+	)
+	server := &http.Server{ //nolint:exhaustruct
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+
+		Addr:    fmt.Sprintf(":%v", options.Port),
+		Handler: router,
+	}
+	server.RegisterOnShutdown(app.broadcaster.Stop) // 열려 있는 SSE 스트림을 닫아야 Shutdown이 끝난다.
+	return server
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/flow"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutes_ListCoinsByQuote(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	for _, coinID := range []domain.CoinID{"upbit:KRW-BTC", "upbit:USDT-BTC", "bithumb:KRW-ETH", "upbit:BTC-ETH"} {
+		_, err := repo.CreateCoin(ctx, domain.NewCoin(coinID, time.Now()))
+		require.NoError(t, err)
+	}
+	_, api := humatest.New(t)
+	AddRoutes(api, flow.NewService(repo))
+	tests := map[string]struct {
+		query string
+		want  string
+	}{
+		"all":                 {query: "", want: `["bithumb:KRW-ETH","upbit:BTC-ETH","upbit:KRW-BTC","upbit:USDT-BTC"]`},
+		"usdt":                {query: "?quote=USDT", want: `["upbit:USDT-BTC"]`},
+		"case insensitive":    {query: "?quote=krw", want: `["bithumb:KRW-ETH","upbit:KRW-BTC"]`},
+		"with exchange":       {query: "?quote=KRW&exchange=upbit", want: `["upbit:KRW-BTC"]`},
+		"quote without coins": {query: "?quote=EUR", want: `null`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := api.Get("/coins" + test.query)
+
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+			require.JSONEq(t, `{"Coins":`+test.want+`}`, resp.Body.String())
+		})
+	}
+}
+
+func TestRoutes_ListCandlesInRange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	var candles []*domain.Trade
+	for day := 20; day <= 24; day++ {
+		candles = append(candles, domain.NewTrade(time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC), "100", "100", "100", "100"))
+	}
+	_, err := repo.SaveTrades(ctx, domain.NewTrades("upbit:KRW-BTC", domain.IntervalDay, time.Now(), candles))
+	require.NoError(t, err)
+	_, api := humatest.New(t)
+	AddRoutes(api, flow.NewService(repo))
+	tests := map[string]struct {
+		query string
+		want  []string
+	}{
+		"default":     {query: "", want: []string{"20", "21", "22", "23", "24"}},
+		"limit":       {query: "?limit=2", want: []string{"23", "24"}},
+		"from and to": {query: "?from=2025-01-21T00:00:00Z&to=2025-01-22T00:00:00Z", want: []string{"21", "22"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := api.Get("/candles/KRW-BTC" + test.query)
+
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+			var body ListCandlesBody
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+			var days []string
+			for _, candle := range body.Candles {
+				days = append(days, candle.Date.Format("02"))
+			}
+			require.Equal(t, test.want, days)
+		})
+	}
+}
+
+func TestRoutes_ListCandlesRejectsInvalidLimit(t *testing.T) {
+	t.Parallel()
+	_, api := humatest.New(t)
+	AddRoutes(api, flow.NewService(realrepositorytest.New(t)))
+
+	resp := api.Get("/candles/KRW-BTC?limit=0")
+
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2/humacli"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand 서비스를 멈춘 채 badger에 저장된 레코드를 현재 스키마 버전으로 올린다.
+// sqlite는 데이터베이스를 열 때 migration을 적용하므로 따로 올릴 것이 없다.
+func newMigrateCommand(ctx context.Context) *cobra.Command {
+	var dryRun bool
+	ret := &cobra.Command{ //nolint:exhaustruct
+		Use:   "migrate",
+		Short: "Upgrade stored records to the current schema version",
+		Args:  cobra.NoArgs,
+		Run: humacli.WithOptions(func(cmd *cobra.Command, _ []string, options *Options) {
+			cobra.CheckErr(migrate(ctx, cmd, options, dryRun))
+		}),
+	}
+	ret.Flags().BoolVar(&dryRun, "dry-run", false, "Report records that would be upgraded without writing them")
+	return ret
+}
+
+func migrate(ctx context.Context, cmd *cobra.Command, options *Options, dryRun bool) error {
+	if options.Repository != "badger" {
+		return errors.Errorf("repository %q migrates its schema when opened", options.Repository)
+	}
+	repo, closeRepo, err := openBadgerRepository(options)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+	results, err := repo.Migrate(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+	verb := "upgraded"
+	if dryRun {
+		verb = "would upgrade"
+	}
+	for _, result := range results {
+		cmd.Printf("%v: scanned %v, %v %v\n", result.Kind, result.Scanned, verb, result.Upgraded)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/app/trader"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+// parseSchedules "1d=10m,15m" 형태의 문자열을 trader 옵션으로 변환한다.
+// 주기를 생략하면 캔들 단위에 맞는 기본 주기를 사용한다.
+func parseSchedules(s string) ([]trader.Option, error) {
+	var ret []trader.Option
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		intervalString, everyString, hasEvery := strings.Cut(item, "=")
+		interval, err := domain.ParseInterval(strings.TrimSpace(intervalString))
+		if err != nil {
+			return nil, err
+		}
+		var every time.Duration
+		if hasEvery {
+			every, err = time.ParseDuration(strings.TrimSpace(everyString))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if every <= 0 {
+				return nil, errors.Errorf("refresh cadence of %v must be positive", interval)
+			}
+		}
+		ret = append(ret, trader.WithSchedule(interval, every))
+	}
+	return ret, nil
+}
+
+// parseQuotes "KRW,BTC,USDT" 형태의 문자열을 기준 화폐 목록으로 변환한다.
+func parseQuotes(s string) ([]domain.Quote, error) {
+	var ret []domain.Quote
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		quote, err := domain.ParseQuote(item)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, quote)
+	}
+	return ret, nil
+}
+
+// parsePriceRules "KRW=100:100000,USDT=0.1:" 형태의 문자열을 prohibitor 옵션으로 변환한다.
+// 최소나 최대 가격을 생략하면 그쪽은 제한하지 않는다.
+func parsePriceRules(s string) ([]prohibitor.Option, error) {
+	var ret []prohibitor.Option
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		quoteString, rangeString, _ := strings.Cut(item, "=")
+		quote, err := domain.ParseQuote(quoteString)
+		if err != nil {
+			return nil, err
+		}
+		minString, maxString, _ := strings.Cut(rangeString, ":")
+		var rule prohibitor.PriceRule
+		if minString != "" {
+			rule.MinPrice, err = strconv.ParseFloat(minString, 64)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		if maxString != "" {
+			rule.MaxPrice, err = strconv.ParseFloat(maxString, 64)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		ret = append(ret, prohibitor.WithPriceRule(quote, rule))
+	}
+	return ret, nil
+}
+
+// parseExchanges "upbit,bithumb" 형태의 문자열을 거래소 목록으로 변환한다.
+func parseExchanges(s string) ([]domain.Exchange, error) {
+	var ret []domain.Exchange
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		exchange, err := domain.ParseExchange(item)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, exchange)
+	}
+	return ret, nil
+}
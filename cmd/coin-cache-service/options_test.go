@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/app/trader"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedules(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input string
+		want  []trader.Schedule
+	}{
+		"empty":           {input: "", want: nil},
+		"default cadence": {input: "1d", want: []trader.Schedule{{Interval: domain.IntervalDay, Every: 10 * time.Minute}}},
+		"short interval":  {input: "1m", want: []trader.Schedule{{Interval: domain.IntervalMinute1, Every: time.Minute}}},
+		"several": {
+			input: "1d=10m, 15m=1m",
+			want: []trader.Schedule{
+				{Interval: domain.IntervalDay, Every: 10 * time.Minute},
+				{Interval: domain.IntervalMinute15, Every: time.Minute},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := parseSchedules(test.input)
+
+			require.NoError(t, err)
+			options := trader.NewOptions()
+			for _, opt := range opts {
+				opt(options)
+			}
+			require.Equal(t, test.want, options.Schedules)
+		})
+	}
+}
+
+func TestParseSchedules_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"1d=", "=10m", "2h", "1d=10", "1d=-1m", "1d=0s"} {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseSchedules(input)
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParsePriceRules(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		input string
+		want  map[domain.Quote]prohibitor.PriceRule
+	}{
+		"default": {
+			input: "",
+			want:  map[domain.Quote]prohibitor.PriceRule{domain.QuoteKRW: {MinPrice: 100, MaxPrice: 100_000}},
+		},
+		"several": {
+			input: "KRW=10:1000, usdt=0.1:10",
+			want: map[domain.Quote]prohibitor.PriceRule{
+				domain.QuoteKRW:  {MinPrice: 10, MaxPrice: 1000},
+				domain.QuoteUSDT: {MinPrice: 0.1, MaxPrice: 10},
+			},
+		},
+		"open-ended max": {
+			input: "USDT=0.1:",
+			want: map[domain.Quote]prohibitor.PriceRule{
+				domain.QuoteKRW:  {MinPrice: 100, MaxPrice: 100_000},
+				domain.QuoteUSDT: {MinPrice: 0.1, MaxPrice: 0},
+			},
+		},
+		"open-ended min": {
+			input: "BTC=:0.5",
+			want: map[domain.Quote]prohibitor.PriceRule{
+				domain.QuoteKRW: {MinPrice: 100, MaxPrice: 100_000},
+				domain.QuoteBTC: {MinPrice: 0, MaxPrice: 0.5},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := parsePriceRules(test.input)
+
+			require.NoError(t, err)
+			options := prohibitor.NewOptions()
+			for _, opt := range opts {
+				opt(options)
+			}
+			require.Equal(t, test.want, options.PriceRules)
+		})
+	}
+}
+
+func TestParsePriceRules_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"DOGE=1:2", "KRW=abc:", "KRW=1:x"} {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parsePriceRules(input)
+
+			require.Error(t, err)
+		})
+	}
+}
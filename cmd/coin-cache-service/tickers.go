@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/watcher"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/pkg/errors"
+)
+
+type TickerBody struct {
+	CoinID       string
+	Price        string
+	TradedAt     time.Time
+	ChangePrice  string
+	ChangeRate   string
+	Volume24h    string
+	TradedValue  string
+	OpeningPrice string
+	HighPrice    string
+	LowPrice     string
+}
+
+func NewTickerBody(ticker *domain.Ticker) *TickerBody {
+	return &TickerBody{
+		CoinID:       string(ticker.CoinID()),
+		Price:        string(ticker.Price()),
+		TradedAt:     ticker.TradedAt(),
+		ChangePrice:  string(ticker.ChangePrice()),
+		ChangeRate:   string(ticker.ChangeRate()),
+		Volume24h:    string(ticker.Volume24h()),
+		TradedValue:  string(ticker.TradedValue()),
+		OpeningPrice: string(ticker.OpeningPrice()),
+		HighPrice:    string(ticker.HighPrice()),
+		LowPrice:     string(ticker.LowPrice()),
+	}
+}
+
+type ListTickersBody struct {
+	Tickers []*TickerBody
+}
+
+type ListTickersRequest struct {
+	ExchangeParam
+}
+
+type ListTickersResponse struct {
+	Body *ListTickersBody `doc:"Body" json:"body"`
+}
+
+type GetTickerRequest struct {
+	ExchangeParam
+
+	CoinID string `path:"coinID"`
+}
+
+type GetTickerResponse struct {
+	Body *TickerBody `doc:"Body" json:"body"`
+}
+
+// AddTickerRoutes 웹소켓으로 받은 실시간 현재가를 메모리에서 바로 돌려준다.
+func AddTickerRoutes(api huma.API, tickerWatcher *watcher.Watcher) {
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "list.tickers",
+		Summary:     "List real-time tickers",
+		Method:      http.MethodGet,
+		Path:        "/tickers",
+	}, func(_ context.Context, input *ListTickersRequest) (*ListTickersResponse, error) {
+		var tickers []*TickerBody
+		for _, ticker := range tickerWatcher.ListTickers() {
+			if !input.Match(ticker.CoinID()) {
+				continue
+			}
+			tickers = append(tickers, NewTickerBody(ticker))
+		}
+		resp := &ListTickersResponse{
+			Body: &ListTickersBody{
+				Tickers: tickers,
+			},
+		}
+		return resp, nil
+	})
+	huma.Register(api, huma.Operation{ //nolint:exhaustruct
+		OperationID: "get.ticker",
+		Summary:     "Get real-time ticker",
+		Method:      http.MethodGet,
+		Path:        "/tickers/{coinID}",
+	}, func(_ context.Context, input *GetTickerRequest) (*GetTickerResponse, error) {
+		ticker, err := tickerWatcher.GetTicker(input.QualifyCoinID(input.CoinID))
+		if err != nil {
+			if errors.Is(err, watcher.ErrTickerNotFound) {
+				return nil, huma.Error404NotFound(err.Error())
+			}
+			return nil, problem(err)
+		}
+		resp := &GetTickerResponse{
+			Body: NewTickerBody(ticker),
+		}
+		return resp, nil
+	})
+}
@@ -0,0 +1,157 @@
+package broadcaster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	coinrepository.AppendEventCommand
+	coinrepository.ListEventsAfterQuery
+}
+
+// subscriberBuffer 구독자가 이만큼 밀리면 연결을 끊는다. 구독자는 마지막 번호부터 다시 받으면 된다.
+const subscriberBuffer = 64
+
+// Broadcaster 버스의 모든 이벤트를 이벤트 로그에 기록하고 구독자에게 나눠준다.
+type Broadcaster struct {
+	logger *zap.Logger
+	bus    bus.Bus
+	repo   Repository
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription 구독자가 받을 이벤트. 구독이 끊기면 Events가 닫힌다.
+type Subscription struct {
+	Events <-chan *domain.LoggedEvent
+	// ResetID 0이 아니면 요청한 번호 다음의 이벤트 일부가 로그에서 이미 지워져 이어 받을 수 없다.
+	// 구독자는 상태를 새로 읽어야 하며, 백로그는 ResetID 다음부터이다.
+	ResetID uint64
+
+	events chan *domain.LoggedEvent
+	topics map[string]struct{}
+}
+
+func NewBroadcaster(logger *zap.Logger, bus bus.Bus, repo Repository) *Broadcaster {
+	return &Broadcaster{
+		logger:      logger,
+		bus:         bus,
+		repo:        repo,
+		mu:          sync.Mutex{},
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Start 이벤트 로그와 구독자는 프로세스마다 있으므로 프로세스마다 모든 이벤트를 받는다.
+func (b *Broadcaster) Start(ctx context.Context) error {
+	for _, topic := range domain.Topics() {
+		err := b.bus.Subscribe(ctx, topic, b.handleEvent, bus.WithName("broadcaster"), bus.WithFanOut())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// Stop 모든 구독을 끊는다.
+func (b *Broadcaster) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscribers {
+		b.remove(subscription)
+	}
+}
+
+func (b *Broadcaster) handleEvent(ctx context.Context, event domain.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	loggedEvent, err := b.repo.AppendEvent(ctx, event, time.Now())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for subscription := range b.subscribers {
+		if !subscription.match(loggedEvent.Topic()) {
+			continue
+		}
+		select {
+		case subscription.events <- loggedEvent:
+		default:
+			b.logger.Warn("drop slow subscriber", zap.Uint64("event_id", loggedEvent.ID()))
+			b.remove(subscription)
+		}
+	}
+	return nil
+}
+
+// Subscribe afterID 다음의 기록된 이벤트와 이후 새 이벤트를 topics로 걸러 받는다. topics가 비어 있으면 모두 받는다.
+// 기록과 배포를 같은 잠금 안에서 하므로 놓치거나 두 번 받는 이벤트가 없다.
+func (b *Broadcaster) Subscribe(ctx context.Context, topics []string, afterID uint64) (
+	[]*domain.LoggedEvent,
+	*Subscription,
+	error,
+) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	events, err := b.repo.ListEventsAfter(ctx, afterID)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	subscription := newSubscription(topics)
+	if afterID > 0 && len(events) > 0 && events[0].ID() > afterID+1 {
+		subscription.ResetID = events[0].ID() - 1
+	}
+	var backlog []*domain.LoggedEvent
+	for _, event := range events {
+		if subscription.match(event.Topic()) {
+			backlog = append(backlog, event)
+		}
+	}
+	b.subscribers[subscription] = struct{}{}
+	return backlog, subscription, nil
+}
+
+// Unsubscribe 이미 끊긴 구독이어도 괜찮다.
+func (b *Broadcaster) Unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(subscription)
+}
+
+func (b *Broadcaster) remove(subscription *Subscription) {
+	if _, ok := b.subscribers[subscription]; !ok {
+		return
+	}
+	delete(b.subscribers, subscription)
+	close(subscription.events)
+}
+
+func newSubscription(topics []string) *Subscription {
+	events := make(chan *domain.LoggedEvent, subscriberBuffer)
+	ret := &Subscription{
+		Events:  events,
+		ResetID: 0,
+		events:  events,
+		topics:  make(map[string]struct{}, len(topics)),
+	}
+	for _, topic := range topics {
+		ret.topics[topic] = struct{}{}
+	}
+	return ret
+}
+
+func (s *Subscription) match(topic string) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	_, ok := s.topics[topic]
+	return ok
+}
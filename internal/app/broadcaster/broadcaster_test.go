@@ -0,0 +1,46 @@
+package broadcaster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/broadcaster"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBroadcaster_SubscribeResumesAndFilters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(logger)
+	b := broadcaster.NewBroadcaster(logger, bus, repo)
+	require.NoError(t, b.Start(ctx))
+	t.Cleanup(b.Stop)
+	now := time.Now()
+	bus.Publish(ctx, domain.NewCoinCreatedEvent(now, "upbit:KRW-A"))
+	bus.Publish(ctx, domain.NewBannedCoinCreatedEvent("upbit:KRW-A"))
+	bus.Publish(ctx, domain.NewCoinCreatedEvent(now, "upbit:KRW-B"))
+
+	backlog, subscription, err := b.Subscribe(ctx, []string{domain.CoinCreatedEventTopic}, 1)
+	require.NoError(t, err)
+	bus.Publish(ctx, domain.NewBannedCoinCreatedEvent("upbit:KRW-B"))
+	bus.Publish(ctx, domain.NewCoinCreatedEvent(now, "upbit:KRW-C"))
+
+	require.Zero(t, subscription.ResetID)
+	require.Len(t, backlog, 1)
+	require.Equal(t, uint64(3), backlog[0].ID())
+	live := <-subscription.Events
+	require.Equal(t, uint64(5), live.ID())
+	coinCreated, err := domain.ParseCoinCreatedEvent(live.Payload())
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-C"), coinCreated.CoinID)
+	b.Unsubscribe(subscription)
+	_, ok := <-subscription.Events
+	require.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package deadletter
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+type Repository interface {
+	coinrepository.ListDeadLettersQuery
+	coinrepository.GetDeadLetterQuery
+	coinrepository.DeleteDeadLetterCommand
+}
+
+// Redeliverer 이벤트를 한 구독에만 다시 전달한다.
+type Redeliverer interface {
+	Redeliver(ctx context.Context, subscription string, event domain.Event) error
+}
+
+// Service 재시도를 모두 실패한 이벤트를 살펴보고 다시 전달하거나 버린다.
+type Service struct {
+	repo Repository
+	bus  Redeliverer
+}
+
+func NewService(repo Repository, bus Redeliverer) *Service {
+	return &Service{repo: repo, bus: bus}
+}
+
+func (s *Service) ListDeadLetters(ctx context.Context) ([]*domain.DeadLetter, error) {
+	ret, err := s.repo.ListDeadLetters(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ret, nil
+}
+
+func (s *Service) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
+	ret, err := s.repo.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ret, nil
+}
+
+// Replay 실패했던 구독에 다시 전달하고 dead letter를 지운다. 또 실패하면 새 dead letter가 남는다.
+func (s *Service) Replay(ctx context.Context, id string) error {
+	deadLetter, err := s.repo.GetDeadLetter(ctx, id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = s.bus.Redeliver(ctx, deadLetter.Subscription(), deadLetter)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = s.repo.DeleteDeadLetter(ctx, id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Discard 다시 전달하지 않고 지운다.
+func (s *Service) Discard(ctx context.Context, id string) error {
+	err := s.repo.DeleteDeadLetter(ctx, id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package deadletter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/deadletter"
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newDeadLetter(id string, subscription string) *domain.DeadLetter {
+	return domain.NewDeadLetter(
+		id,
+		subscription,
+		"event-"+id,
+		domain.CoinCreatedEventTopic,
+		[]byte(`{"coin_id":"upbit:KRW-A"}`),
+		[]*domain.DeliveryAttempt{domain.NewDeliveryAttempt(time.Now(), "timeout")},
+		time.Now(),
+	)
+}
+
+func TestService_ReplayDeletesDeadLetter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	require.NoError(t, repo.CreateDeadLetter(ctx, newDeadLetter("1", "trader")))
+	b := local.NewBus(zap.NewNop())
+	var received []*domain.Envelope
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, event domain.Event) error {
+		received = append(received, event.(*domain.Envelope))
+		return nil
+	}, bus.WithName("trader")))
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, _ domain.Event) error {
+		t.Error("other subscription must not receive replayed event")
+		return nil
+	}, bus.WithName("watcher")))
+	service := deadletter.NewService(repo, b)
+
+	err := service.Replay(ctx, "1")
+
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, "event-1", received[0].EventID())
+	require.JSONEq(t, `{"coin_id":"upbit:KRW-A"}`, string(received[0].Payload()))
+	_, err = repo.GetDeadLetter(ctx, "1")
+	require.ErrorIs(t, err, coinrepository.ErrDeadLetterNotFound)
+}
+
+func TestService_ReplayKeepsDeadLetterOfUnknownSubscription(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	require.NoError(t, repo.CreateDeadLetter(ctx, newDeadLetter("1", "gone")))
+	service := deadletter.NewService(repo, local.NewBus(zap.NewNop()))
+
+	err := service.Replay(ctx, "1")
+
+	require.ErrorIs(t, err, bus.ErrSubscriptionNotFound)
+	_, err = repo.GetDeadLetter(ctx, "1")
+	require.NoError(t, err)
+}
+
+func TestService_ReplayUnknownDeadLetter(t *testing.T) {
+	t.Parallel()
+	service := deadletter.NewService(realrepositorytest.New(t), local.NewBus(zap.NewNop()))
+
+	err := service.Replay(context.Background(), "none")
+
+	require.ErrorIs(t, err, coinrepository.ErrDeadLetterNotFound)
+}
+
+func TestService_Discard(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	require.NoError(t, repo.CreateDeadLetter(ctx, newDeadLetter("1", "trader")))
+	service := deadletter.NewService(repo, local.NewBus(zap.NewNop()))
+
+	err := service.Discard(ctx, "1")
+	errAgain := service.Discard(ctx, "1")
+
+	require.NoError(t, err)
+	require.ErrorIs(t, errAgain, coinrepository.ErrDeadLetterNotFound)
+	deadLetters, err := service.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+}
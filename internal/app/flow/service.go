@@ -12,7 +12,10 @@ import (
 type Repository interface {
 	coinrepository.ListCoinsQuery
 	coinrepository.ListBannedCoinsQuery
+	coinrepository.GetBannedCoinQuery
+	coinrepository.ListAllowedCoinsQuery
 	coinrepository.ListTradesQuery
+	coinrepository.ListTradesInRangeQuery
 }
 
 type Service struct {
@@ -24,6 +27,19 @@ func NewService(repo Repository) *Service {
 }
 
 func (s *Service) ListCoins(ctx context.Context) ([]string, error) {
+	coins, err := s.ListCoinDetails(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, coin := range coins {
+		ret = append(ret, string(coin.ID()))
+	}
+	return ret, nil
+}
+
+// ListCoinDetails 금지되지 않은 코인을 이름, 경고 정보와 함께 돌려준다.
+func (s *Service) ListCoinDetails(ctx context.Context) ([]*domain.Coin, error) {
 	bannedCoins, err := s.repo.ListBannedCoins(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -36,20 +52,61 @@ func (s *Service) ListCoins(ctx context.Context) ([]string, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	var ret []string
+	var ret []*domain.Coin
 	for _, coin := range coins {
 		if bannedCoinSet.ContainKey(coin.ID()) {
 			continue
 		}
-		ret = append(ret, string(coin.ID()))
+		ret = append(ret, coin)
 	}
 	return ret, nil
 }
 
-func (s *Service) ListTrades(ctx context.Context, coinID domain.CoinID) (*domain.Trades, error) {
-	trades, err := s.repo.ListTrades(ctx, coinID)
+func (s *Service) ListTrades(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+) (*domain.Trades, error) {
+	trades, err := s.repo.ListTrades(ctx, coinID, interval)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return trades, nil
+}
+
+func (s *Service) ListTradesInRange(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+	tradeRange coinrepository.TradeRange,
+) (*domain.Trades, error) {
+	trades, err := s.repo.ListTradesInRange(ctx, coinID, interval, tradeRange)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return trades, nil
 }
+
+func (s *Service) ListBannedCoins(ctx context.Context) ([]*domain.BannedCoin, error) {
+	bannedCoins, err := s.repo.ListBannedCoins(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return bannedCoins, nil
+}
+
+func (s *Service) GetBannedCoin(ctx context.Context, coinID domain.CoinID) (*domain.BannedCoin, error) {
+	bannedCoin, err := s.repo.GetBannedCoin(ctx, coinID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return bannedCoin, nil
+}
+
+func (s *Service) ListAllowedCoins(ctx context.Context) ([]*domain.AllowedCoin, error) {
+	allowedCoins, err := s.repo.ListAllowedCoins(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return allowedCoins, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
 	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinservice"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
 	setpkg "github.com/biosvos/coin-cache-service/internal/pkg/set"
 	"github.com/biosvos/coin-cache-service/pkg/tracer"
@@ -17,20 +18,19 @@ import (
 type Repository interface {
 	coinrepository.ListCoinsQuery
 	coinrepository.ListBannedCoinsQuery
-	coinrepository.CreateCoinCommand
-	coinrepository.UpdateCoinCommand
-	coinrepository.DeleteCoinCommand
+	coinrepository.SaveCoinsCommand
 }
 
 type Service interface {
+	coinservice.ExchangeQuery
 	coinrepository.ListCoinsQuery
 }
 
-// Miner coin 정보를 최신화한다.
+// Miner 한 거래소의 coin 정보를 최신화한다. 거래소마다 Miner를 하나씩 둔다.
+// 이벤트는 코인과 함께 outbox에 기록하고 relay가 발행한다.
 type Miner struct {
 	service    Service
 	repository Repository
-	bus        bus.Bus
 	logger     *zap.Logger
 	tracer     tracer.Tracer
 	scheduler  gocron.Scheduler
@@ -41,7 +41,6 @@ func NewMiner(
 	logger *zap.Logger,
 	service Service,
 	repository Repository,
-	bus bus.Bus,
 ) *Miner {
 	scheduler, _ := gocron.NewScheduler() // option이 없으면 error도 발생하지 않는다.
 	ret := Miner{
@@ -49,7 +48,6 @@ func NewMiner(
 		logger:     logger,
 		service:    service,
 		repository: repository,
-		bus:        bus,
 		scheduler:  scheduler,
 	}
 	_, _ = ret.scheduler.NewJob(
@@ -95,6 +93,7 @@ func (m *Miner) Stop() {
 func (m *Miner) Mine(ctx context.Context) error { //nolint:cyclop  //FIXME 나중에 nolint 제거
 	ctx, span := m.tracer.Start(ctx, "miner.Mine")
 	defer span.End()
+	span.String("exchange", string(m.service.Exchange()))
 
 	bannedCoinSet, err := m.listBannedCoinSet(ctx)
 	if err != nil {
@@ -128,30 +127,41 @@ func (m *Miner) Mine(ctx context.Context) error { //nolint:cyclop  //FIXME 나
 	onlyRepositoryCoinSet := repositoryCoinSet.Difference(serviceCoinSet)
 	bothCoinSet := serviceCoinSet.Intersection(repositoryCoinSet)
 
+	// 한 번에 저장해서 일부 코인만 반영된 채로 멈추지 않게 한다.
+	coinChanges := &coinrepository.CoinChanges{Created: nil, Updated: nil, Deleted: nil}
+	var events []domain.Event
 	for _, coin := range onlyServiceCoinSet.Values() {
-		err := m.createCoin(ctx, now, coin)
-		if err != nil {
-			return errors.WithStack(err)
-		}
+		coinChanges.Created = append(coinChanges.Created, coin)
+		events = append(events, m.seal(ctx, domain.NewCoinCreatedEvent(now, coin.ID())))
 	}
-
 	for _, coin := range onlyRepositoryCoinSet.Values() {
-		err := m.deleteCoin(ctx, now, coin)
-		if err != nil {
-			return errors.WithStack(err)
-		}
+		coinChanges.Deleted = append(coinChanges.Deleted, coin)
+		events = append(events, m.seal(ctx, domain.NewCoinDeletedEvent(now, coin.ID())))
 	}
-
 	for _, coin := range bothCoinSet.Values() {
-		if !coin.IsOld(now) {
+		repositoryCoin, _ := repositoryCoinSet.Get(coin.ID())
+		changes := repositoryCoin.Changes(coin)
+		if len(changes) == 0 && !repositoryCoin.IsOld(now) {
 			continue
 		}
-		coin = coin.SetModifiedAt(now)
-		err := m.updateCoin(ctx, now, coin)
-		if err != nil {
-			return errors.WithStack(err)
-		}
+		// changes가 비어 있으면 수정 시각만 갱신한다.
+		coinChanges.Updated = append(coinChanges.Updated, coin.SetModifiedAt(now))
+		events = append(events, m.seal(ctx, domain.NewCoinUpdatedEvent(now, coin.ID(), changes...)))
+	}
+	if len(events) == 0 {
+		return nil
 	}
+	err = m.repository.SaveCoins(ctx, coinChanges, events...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	m.logger.Info(
+		"save coins",
+		zap.String("exchange", string(m.service.Exchange())),
+		zap.Int("created", len(coinChanges.Created)),
+		zap.Int("updated", len(coinChanges.Updated)),
+		zap.Int("deleted", len(coinChanges.Deleted)),
+	)
 	return nil
 }
 
@@ -209,6 +219,9 @@ func (m *Miner) listRepositoryCoinsWithoutBanned(
 	}
 	var filteredCoins []*domain.Coin
 	for _, coin := range repositoryCoins {
+		if coin.ID().Exchange() != m.service.Exchange() {
+			continue // 다른 거래소의 코인은 그 거래소의 Miner가 관리한다.
+		}
 		if bannedCoinSet.ContainKey(coin.ID()) {
 			continue
 		}
@@ -217,32 +230,7 @@ func (m *Miner) listRepositoryCoinsWithoutBanned(
 	return filteredCoins, nil
 }
 
-func (m *Miner) createCoin(ctx context.Context, now time.Time, coin *domain.Coin) error {
-	_, err := m.repository.CreateCoin(ctx, coin)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	m.logger.Info("create coin", zap.String("coin_id", string(coin.ID())))
-	m.bus.Publish(ctx, domain.NewCoinCreatedEvent(now, coin.ID()))
-	return nil
-}
-
-func (m *Miner) deleteCoin(ctx context.Context, now time.Time, coin *domain.Coin) error {
-	err := m.repository.DeleteCoin(ctx, coin)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	m.logger.Info("delete coin", zap.String("coin_id", string(coin.ID())))
-	m.bus.Publish(ctx, domain.NewCoinDeletedEvent(now, coin.ID()))
-	return nil
-}
-
-func (m *Miner) updateCoin(ctx context.Context, now time.Time, coin *domain.Coin) error {
-	_, err := m.repository.UpdateCoin(ctx, coin)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	m.logger.Info("update coin", zap.String("coin_id", string(coin.ID())))
-	m.bus.Publish(ctx, domain.NewCoinUpdatedEvent(now, coin.ID()))
-	return nil
+// seal 이벤트를 기록하는 시점의 trace context를 봉투에 남긴다.
+func (m *Miner) seal(ctx context.Context, event domain.Event) domain.Event {
+	return bus.Seal(ctx, m.tracer, "miner:"+string(m.service.Exchange()), event)
 }
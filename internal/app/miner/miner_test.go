@@ -0,0 +1,63 @@
+package miner_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/miner"
+	"github.com/biosvos/coin-cache-service/internal/app/relay"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit/upbittest"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMiner_MinePublishesChangedFields(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(logger)
+	_, _ = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-BTC", time.Now().Add(-time.Hour)).SetNames("비트코인", "Bitcoin"))
+	server := upbittest.NewServer()
+	t.Cleanup(server.Close)
+	market := upbittest.NewMarket("KRW-BTC")
+	market.KoreanName = "비트코인"
+	market.EnglishName = "Bitcoin"
+	market.MarketEvent = &upbit.MarketEvent{
+		Warning: true,
+		Caution: &upbit.MarketCaution{PRICEFLUCTUATIONS: true}, //nolint:exhaustruct
+	}
+	server.SetMarkets(market)
+	var mu sync.Mutex
+	var events []*domain.CoinUpdatedEvent
+	require.NoError(t, bus.Subscribe(ctx, domain.CoinUpdatedEventTopic, func(_ context.Context, event domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		updated, err := domain.ParseCoinUpdatedEvent(event.Payload())
+		if err != nil {
+			return err
+		}
+		events = append(events, updated)
+		return nil
+	}))
+	m := miner.NewMiner(noop.NewTracer(), logger, upbit.NewService(upbit.WithBaseURL(server.URL())), repo)
+
+	err := m.Mine(ctx)
+
+	require.NoError(t, err)
+	require.NoError(t, relay.NewRelay(logger, bus, repo).Deliver(ctx))
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 1)
+	require.Equal(t, []domain.CoinField{domain.CoinFieldWarning, domain.CoinFieldCautions}, events[0].Changes)
+	coins, err := repo.ListCoins(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []domain.CautionCategory{domain.CautionPriceFluctuations}, coins[0].Cautions())
+}
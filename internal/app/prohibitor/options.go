@@ -0,0 +1,33 @@
+package prohibitor
+
+import (
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+// PriceRule 기준 화폐별로 거래를 허용하는 가격 범위. 0이면 해당 쪽은 제한하지 않는다.
+type PriceRule struct {
+	MinPrice float64
+	MaxPrice float64
+}
+
+type Options struct {
+	PriceRules map[domain.Quote]PriceRule
+}
+
+// NewOptions 기본으로 원화 마켓은 100원 이상 100,000원 이하만 허용한다.
+func NewOptions() *Options {
+	return &Options{
+		PriceRules: map[domain.Quote]PriceRule{
+			domain.QuoteKRW: {MinPrice: 100, MaxPrice: 100_000}, //nolint:mnd
+		},
+	}
+}
+
+type Option func(*Options)
+
+// WithPriceRule quote 마켓의 가격 범위를 정한다. 규칙이 없는 기준 화폐는 가격으로 금지하지 않는다.
+func WithPriceRule(quote domain.Quote, rule PriceRule) Option {
+	return func(o *Options) {
+		o.PriceRules[quote] = rule
+	}
+}
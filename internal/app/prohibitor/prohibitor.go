@@ -3,12 +3,12 @@ package prohibitor
 import (
 	"context"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
 	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -24,29 +24,88 @@ type Repository interface {
 	coinrepository.ListBannedCoinsQuery
 	coinrepository.GetBannedCoinQuery
 	coinrepository.DeleteBannedCoinCommand
+
+	coinrepository.CreateAllowedCoinCommand
+	coinrepository.GetAllowedCoinQuery
+	coinrepository.DeleteAllowedCoinCommand
 }
 
 type Prohibitor struct {
-	logger    *zap.Logger
-	bus       bus.Bus
-	repo      Repository
-	scheduler gocron.Scheduler
+	tracer     tracer.Tracer
+	logger     *zap.Logger
+	bus        bus.Bus
+	repo       Repository
+	scheduler  gocron.Scheduler
+	priceRules map[domain.Quote]PriceRule
 }
 
 const day = 24 * time.Hour
 
-func NewProhibitor(logger *zap.Logger, bus bus.Bus, repo Repository) *Prohibitor {
+// producer 봉투에 남기는 발행자 이름.
+const producer = "prohibitor"
+
+func NewProhibitor(
+	tracer tracer.Tracer,
+	logger *zap.Logger,
+	bus bus.Bus,
+	repo Repository,
+	opts ...Option,
+) *Prohibitor {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
 	scheduler, _ := gocron.NewScheduler()
-	return &Prohibitor{logger: logger, bus: bus, repo: repo, scheduler: scheduler}
+	return &Prohibitor{
+		tracer:     tracer,
+		logger:     logger,
+		bus:        bus,
+		repo:       repo,
+		scheduler:  scheduler,
+		priceRules: options.PriceRules,
+	}
 }
 
 func (p *Prohibitor) Start(ctx context.Context) error {
 	p.scheduler.Start()
-	p.bus.Subscribe(ctx, domain.CoinCreatedEventTopic, p.handleCoinCreated)
-	p.bus.Subscribe(ctx, domain.CoinUpdatedEventTopic, p.handleCoinUpdated)
-	p.bus.Subscribe(ctx, domain.CoinDeletedEventTopic, p.handleCoinDeleted)
-	p.bus.Subscribe(ctx, domain.TradesUpdatedEventTopic, p.handleTradesUpdated)
-	p.bus.Subscribe(ctx, domain.TradesDeletedEventTopic, p.handleTradesDeleted)
+	handlers := map[string]func(ctx context.Context, event domain.Event) error{
+		domain.CoinCreatedEventTopic:   p.handleCoinCreated,
+		domain.CoinUpdatedEventTopic:   p.handleCoinUpdated,
+		domain.CoinDeletedEventTopic:   p.handleCoinDeleted,
+		domain.TradesUpdatedEventTopic: p.handleTradesUpdated,
+		domain.TradesDeletedEventTopic: p.handleTradesDeleted,
+	}
+	for topic, handler := range handlers {
+		err := p.bus.Subscribe(ctx, topic, handler, bus.WithName("prohibitor"))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	err := p.restoreExpireBannedCoinJobs(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// restoreExpireBannedCoinJobs 재시작 시 저장된 금지 코인의 만료 작업을 다시 등록한다.
+// 중단된 동안 만료된 금지는 즉시 해제한다.
+func (p *Prohibitor) restoreExpireBannedCoinJobs(ctx context.Context) error {
+	bannedCoins, err := p.repo.ListBannedCoins(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	now := time.Now()
+	for _, bannedCoin := range bannedCoins {
+		if bannedCoin.IsBanOver(now) {
+			err := p.deleteBannedCoin(ctx, bannedCoin)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		p.addExpireBannedCoinJob(ctx, bannedCoin)
+	}
 	return nil
 }
 
@@ -57,7 +116,9 @@ func (p *Prohibitor) Stop() {
 	}
 }
 
+// addExpireBannedCoinJob 만료 작업은 요청이 끝난 뒤에 돌기 때문에 ctx의 취소를 따르지 않는다.
 func (p *Prohibitor) addExpireBannedCoinJob(ctx context.Context, bannedCoin *domain.BannedCoin) {
+	ctx = context.WithoutCancel(ctx)
 	_, err := p.scheduler.NewJob(
 		gocron.OneTimeJob(
 			gocron.OneTimeJobStartDateTime(
@@ -78,39 +139,163 @@ func (p *Prohibitor) addExpireBannedCoinJob(ctx context.Context, bannedCoin *dom
 			},
 			bannedCoin.CoinID(),
 		),
+		gocron.WithTags(string(bannedCoin.CoinID())),
 	)
 	if err != nil {
 		p.logger.Error("failed to add expire banned coin job", zap.Error(err))
 	}
 }
 
+func (p *Prohibitor) removeExpireBannedCoinJob(coinID domain.CoinID) {
+	p.scheduler.RemoveByTags(string(coinID))
+}
+
+// BanCoin 운영자가 코인을 직접 금지한다. 허용 목록과 상관없이 금지된다.
+// 거래소가 붙지 않은 coinID는 기본 거래소의 코인으로 보고, 모르는 코인이면 ErrCoinNotFound를 반환한다.
+func (p *Prohibitor) BanCoin(
+	ctx context.Context,
+	coinID domain.CoinID,
+	period time.Duration,
+	note string,
+) (*domain.BannedCoin, error) {
+	coinID = domain.ParseCoinID(string(coinID), "")
+	_, err := p.repo.GetCoin(ctx, coinID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	bannedCoin := domain.NewManualBannedCoin(coinID, time.Now(), period, note)
+	err = p.saveBannedCoin(ctx, bannedCoin)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return bannedCoin, nil
+}
+
+// UnbanCoin 운영자가 코인의 금지를 직접 해제한다.
+func (p *Prohibitor) UnbanCoin(ctx context.Context, coinID domain.CoinID) error {
+	coinID = domain.ParseCoinID(string(coinID), "")
+	bannedCoin, err := p.repo.GetBannedCoin(ctx, coinID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return p.deleteBannedCoin(ctx, bannedCoin)
+}
+
+// AllowCoin 코인을 허용 목록에 추가한다. 자동 규칙으로 금지된 상태였다면 금지를 해제한다.
+// 이미 허용된 코인이면 기존 항목을 그대로 반환한다.
+func (p *Prohibitor) AllowCoin(ctx context.Context, coinID domain.CoinID, note string) (*domain.AllowedCoin, error) {
+	coinID = domain.ParseCoinID(string(coinID), "")
+	allowedCoin, err := p.repo.CreateAllowedCoin(ctx, domain.NewAllowedCoin(coinID, time.Now(), note))
+	switch {
+	case errors.Is(err, coinrepository.ErrAllowedCoinAlreadyExists):
+		allowedCoin, err = p.repo.GetAllowedCoin(ctx, coinID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	case err != nil:
+		return nil, errors.WithStack(err)
+	default:
+		p.logger.Info("added allowed coin", zap.String("coin_id", string(coinID)))
+	}
+
+	bannedCoin, err := p.repo.GetBannedCoin(ctx, coinID)
+	if errors.Is(err, coinrepository.ErrBannedCoinNotFound) {
+		return allowedCoin, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if bannedCoin.IsManual() {
+		return allowedCoin, nil
+	}
+	err = p.deleteBannedCoin(ctx, bannedCoin)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return allowedCoin, nil
+}
+
+// DisallowCoin 코인을 허용 목록에서 제거한다. 이후부터 자동 규칙이 다시 적용된다.
+func (p *Prohibitor) DisallowCoin(ctx context.Context, coinID domain.CoinID) error {
+	coinID = domain.ParseCoinID(string(coinID), "")
+	allowedCoin, err := p.repo.GetAllowedCoin(ctx, coinID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = p.repo.DeleteAllowedCoin(ctx, allowedCoin)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	p.logger.Info("removed allowed coin", zap.String("coin_id", string(coinID)))
+	return nil
+}
+
+func (p *Prohibitor) isAllowed(ctx context.Context, coinID domain.CoinID) (bool, error) {
+	_, err := p.repo.GetAllowedCoin(ctx, coinID)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, coinrepository.ErrAllowedCoinNotFound) {
+		return false, nil
+	}
+	return false, errors.WithStack(err)
+}
+
 func (p *Prohibitor) handleCoinCreated(ctx context.Context, event domain.Event) error {
-	coinCreatedEvent := domain.ParseCoinCreatedEvent(event.Payload())
+	coinCreatedEvent, err := domain.ParseCoinCreatedEvent(event.Payload())
+	if err != nil {
+		return err
+	}
 	return p.prohibitByStatus(ctx, coinCreatedEvent.CoinID)
 }
 
 func (p *Prohibitor) handleCoinUpdated(ctx context.Context, event domain.Event) error {
-	coinUpdatedEvent := domain.ParseCoinUpdatedEvent(event.Payload())
+	coinUpdatedEvent, err := domain.ParseCoinUpdatedEvent(event.Payload())
+	if err != nil {
+		return err
+	}
 	return p.prohibitByStatus(ctx, coinUpdatedEvent.CoinID)
 }
 
 func (p *Prohibitor) handleCoinDeleted(ctx context.Context, event domain.Event) error {
-	coinDeletedEvent := domain.ParseCoinDeletedEvent(event.Payload())
+	coinDeletedEvent, err := domain.ParseCoinDeletedEvent(event.Payload())
+	if err != nil {
+		return err
+	}
 	return p.allowCoin(ctx, coinDeletedEvent.CoinID)
 }
 
 func (p *Prohibitor) handleTradesUpdated(ctx context.Context, event domain.Event) error {
-	tradesUpdatedEvent := domain.ParseTradesUpdatedEvent(event.Payload())
+	tradesUpdatedEvent, err := domain.ParseTradesUpdatedEvent(event.Payload())
+	if err != nil {
+		return err
+	}
+	if tradesUpdatedEvent.Interval != domain.IntervalDay {
+		return nil // 금지 규칙은 일 캔들 기준이다.
+	}
 	return p.prohibitByTrades(ctx, tradesUpdatedEvent.CoinID)
 }
 
 func (p *Prohibitor) handleTradesDeleted(ctx context.Context, event domain.Event) error {
-	tradesDeletedEvent := domain.ParseTradesDeletedEvent(event.Payload())
+	tradesDeletedEvent, err := domain.ParseTradesDeletedEvent(event.Payload())
+	if err != nil {
+		return err
+	}
+	if tradesDeletedEvent.Interval != domain.IntervalDay {
+		return nil
+	}
 	return p.allowCoin(ctx, tradesDeletedEvent.CoinID)
 }
 
 func (p *Prohibitor) prohibitByStatus(ctx context.Context, coinID domain.CoinID) error {
-	_, err := p.repo.GetBannedCoin(ctx, coinID)
+	allowed, err := p.isAllowed(ctx, coinID)
+	if err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+	_, err = p.repo.GetBannedCoin(ctx, coinID)
 	if err == nil { // already banned
 		return nil
 	}
@@ -124,56 +309,78 @@ func (p *Prohibitor) prohibitByStatus(ctx context.Context, coinID domain.CoinID)
 	if !coin.IsDanger() {
 		return nil
 	}
-	err = p.createBannedCoin(ctx, coinID, day)
+	err = p.createBannedCoin(ctx, coinID, day, []domain.BanReason{domain.BanReasonCaution})
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-func (p *Prohibitor) prohibitByTrades(ctx context.Context, coinID domain.CoinID) error {
-	_, err := p.repo.GetBannedCoin(ctx, coinID)
+func (p *Prohibitor) prohibitByTrades(ctx context.Context, coinID domain.CoinID) error { //nolint:cyclop
+	allowed, err := p.isAllowed(ctx, coinID)
+	if err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+	_, err = p.repo.GetBannedCoin(ctx, coinID)
 	if err == nil { // already banned
 		return nil
 	}
 	if !errors.Is(err, coinrepository.ErrBannedCoinNotFound) {
 		return errors.WithStack(err)
 	}
-	trades, err := p.repo.ListTrades(ctx, coinID)
+	trades, err := p.repo.ListTrades(ctx, coinID, domain.IntervalDay)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	var banDuration time.Duration
+	var reasons []domain.BanReason
 	if !trades.IsEnoughTrade() { // 20개 이하면, 20개 이상이 될때까지 금지한다.
 		banDuration = max(banDuration, day)
+		reasons = append(reasons, domain.BanReasonNotEnoughTrades)
 	}
-	lastPrice := trades.LastPrice()
-	sp := strings.Split(string(lastPrice), ".")
-	price, err := strconv.ParseUint(sp[0], 10, 64)
+	priceReasons, err := p.checkPrice(coinID, trades.LastPrice())
 	if err != nil {
-		return errors.WithStack(err)
-	}
-	const maxPrice = 100_000
-	const tenDays = 10 * day
-	if maxPrice < price {
-		banDuration = max(banDuration, tenDays)
+		return err
 	}
-
-	const minPrice = 100
-	if price < minPrice {
+	if len(priceReasons) > 0 {
+		const tenDays = 10 * day
 		banDuration = max(banDuration, tenDays)
+		reasons = append(reasons, priceReasons...)
 	}
 
 	if banDuration == 0 {
 		return nil
 	}
-	err = p.createBannedCoin(ctx, coinID, banDuration)
+	err = p.createBannedCoin(ctx, coinID, banDuration, reasons)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
+// checkPrice coinID의 기준 화폐 규칙으로 가격을 검사한다.
+func (p *Prohibitor) checkPrice(coinID domain.CoinID, lastPrice domain.Price) ([]domain.BanReason, error) {
+	rule, ok := p.priceRules[coinID.Quote()]
+	if !ok {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(string(lastPrice), 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var reasons []domain.BanReason
+	if rule.MaxPrice > 0 && rule.MaxPrice < price {
+		reasons = append(reasons, domain.BanReasonPriceTooHigh)
+	}
+	if rule.MinPrice > 0 && price < rule.MinPrice {
+		reasons = append(reasons, domain.BanReasonPriceTooLow)
+	}
+	return reasons, nil
+}
+
 func (p *Prohibitor) allowCoin(ctx context.Context, coinID domain.CoinID) error {
 	bannedCoin, err := p.repo.GetBannedCoin(ctx, coinID)
 	if err != nil {
@@ -190,24 +397,44 @@ func (p *Prohibitor) allowCoin(ctx context.Context, coinID domain.CoinID) error
 	return p.deleteBannedCoin(ctx, bannedCoin)
 }
 
-func (p *Prohibitor) createBannedCoin(ctx context.Context, coinID domain.CoinID, period time.Duration) error {
-	bannedCoin := domain.NewBannedCoin(coinID, time.Now(), period)
-	_, err := p.repo.CreateBannedCoin(ctx, bannedCoin)
+func (p *Prohibitor) createBannedCoin(
+	ctx context.Context,
+	coinID domain.CoinID,
+	period time.Duration,
+	reasons []domain.BanReason,
+) error {
+	bannedCoin := domain.NewBannedCoin(coinID, time.Now(), period, reasons)
+	return p.saveBannedCoin(ctx, bannedCoin)
+}
+
+func (p *Prohibitor) saveBannedCoin(ctx context.Context, bannedCoin *domain.BannedCoin) error {
+	_, err := p.repo.CreateBannedCoin(
+		ctx,
+		bannedCoin,
+		bus.Seal(ctx, p.tracer, producer, domain.NewBannedCoinCreatedEvent(bannedCoin.CoinID())),
+	)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	p.logger.Info("prohibited coin", zap.String("coin_id", string(coinID)))
-	p.bus.Publish(ctx, domain.NewBannedCoinCreatedEvent(coinID))
+	p.logger.Info(
+		"prohibited coin",
+		zap.String("coin_id", string(bannedCoin.CoinID())),
+		zap.Any("reasons", bannedCoin.Reasons()),
+	)
 	p.addExpireBannedCoinJob(ctx, bannedCoin)
 	return nil
 }
 
 func (p *Prohibitor) deleteBannedCoin(ctx context.Context, bannedCoin *domain.BannedCoin) error {
-	err := p.repo.DeleteBannedCoin(ctx, bannedCoin)
+	err := p.repo.DeleteBannedCoin(
+		ctx,
+		bannedCoin,
+		bus.Seal(ctx, p.tracer, producer, domain.NewBannedCoinDeletedEvent(bannedCoin.CoinID())),
+	)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	p.removeExpireBannedCoinJob(bannedCoin.CoinID())
 	p.logger.Info("allowed coin", zap.String("coin_id", string(bannedCoin.CoinID())))
-	p.bus.Publish(ctx, domain.NewBannedCoinDeletedEvent(bannedCoin.CoinID()))
 	return nil
 }
@@ -0,0 +1,413 @@
+package prohibitor_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/miner"
+	"github.com/biosvos/coin-cache-service/internal/app/prohibitor"
+	"github.com/biosvos/coin-cache-service/internal/app/relay"
+	"github.com/biosvos/coin-cache-service/internal/app/trader"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/internal/pkg/sqliterepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit/upbittest"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProhibitor_BansCheapCoinFromTrades(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	tracer := noop.NewTracer()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(logger)
+	server := upbittest.NewServer()
+	t.Cleanup(server.Close)
+	server.SetMarkets(upbittest.NewMarket("KRW-CHEAP"), upbittest.NewMarket("KRW-FINE"))
+	now := time.Now()
+	server.SetCandles("days", "KRW-CHEAP", upbittest.NewDailyCandles("KRW-CHEAP", now, 30, 99.5)...)
+	server.SetCandles("days", "KRW-FINE", upbittest.NewDailyCandles("KRW-FINE", now, 30, 5000)...)
+	exchange := upbit.NewService(upbit.WithBaseURL(server.URL()))
+	tr := trader.NewTrader(tracer, logger, bus, exchange, repo)
+	require.NoError(t, tr.Start(ctx))
+	t.Cleanup(tr.Stop)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), logger, bus, repo)
+	require.NoError(t, p.Start(ctx))
+	t.Cleanup(p.Stop)
+	r := relay.NewRelay(logger, bus, repo, relay.WithInterval(10*time.Millisecond))
+	require.NoError(t, r.Start(ctx))
+	t.Cleanup(r.Stop)
+	m := miner.NewMiner(tracer, logger, exchange, repo)
+
+	err := m.Mine(ctx)
+
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := repo.GetBannedCoin(ctx, "upbit:KRW-CHEAP")
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	bannedCoin, err := repo.GetBannedCoin(ctx, "upbit:KRW-CHEAP")
+	require.NoError(t, err)
+	require.Equal(t, []domain.BanReason{domain.BanReasonPriceTooLow}, bannedCoin.Reasons())
+	require.Eventually(t, func() bool {
+		_, err := repo.ListTrades(ctx, "upbit:KRW-FINE", domain.IntervalDay)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-FINE")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+}
+
+func TestProhibitor_StartRestoresBannedCoinExpiry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(zap.NewNop())
+	now := time.Now()
+	_, _ = repo.CreateBannedCoin(ctx, domain.NewBannedCoin("KRW-EXPIRED", now.Add(-2*time.Hour), time.Hour, nil))
+	_, _ = repo.CreateBannedCoin(ctx, domain.NewBannedCoin("KRW-SOON", now, 500*time.Millisecond, nil))
+	_, _ = repo.CreateBannedCoin(ctx, domain.NewBannedCoin("KRW-LATER", now, time.Hour, nil))
+	var mu sync.Mutex
+	var deleted []domain.CoinID
+	require.NoError(t, bus.Subscribe(ctx, domain.BannedCoinDeletedEventTopic, func(_ context.Context, event domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		bannedCoinDeleted, err := domain.ParseBannedCoinDeletedEvent(event.Payload())
+		if err != nil {
+			return err
+		}
+		deleted = append(deleted, bannedCoinDeleted.CoinID)
+		return nil
+	}))
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), bus, repo)
+	r := relay.NewRelay(zap.NewNop(), bus, repo, relay.WithInterval(10*time.Millisecond))
+	t.Cleanup(r.Stop)
+
+	err := p.Start(ctx)
+	t.Cleanup(p.Stop)
+
+	require.NoError(t, err)
+	require.NoError(t, r.Start(ctx))
+	_, err = repo.GetBannedCoin(ctx, "KRW-EXPIRED")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+	require.Eventually(t, func() bool {
+		_, err := repo.GetBannedCoin(ctx, "KRW-SOON")
+		return err != nil
+	}, 5*time.Second, 50*time.Millisecond)
+	_, err = repo.GetBannedCoin(ctx, "KRW-LATER")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(deleted) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []domain.CoinID{"KRW-EXPIRED", "KRW-SOON"}, deleted)
+}
+
+func TestProhibitor_BanAndUnbanReachTrader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	tracer := noop.NewTracer()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(logger)
+	server := upbittest.NewServer()
+	t.Cleanup(server.Close)
+	now := time.Now()
+	server.SetCandles("days", "KRW-BTC", upbittest.NewDailyCandles("KRW-BTC", now, 30, 5000)...)
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-BTC", now))
+	require.NoError(t, err)
+	exchange := upbit.NewService(upbit.WithBaseURL(server.URL()))
+	schedule := trader.WithSchedule(domain.IntervalDay, 20*time.Millisecond)
+	tr := trader.NewTrader(tracer, logger, bus, exchange, repo, schedule)
+	require.NoError(t, tr.Start(ctx))
+	t.Cleanup(tr.Stop)
+	p := prohibitor.NewProhibitor(tracer, logger, bus, repo)
+	require.NoError(t, p.Start(ctx))
+	t.Cleanup(p.Stop)
+	r := relay.NewRelay(logger, bus, repo, relay.WithInterval(10*time.Millisecond))
+	require.NoError(t, r.Start(ctx))
+	t.Cleanup(r.Stop)
+	const path = "/v1/candles/days"
+	require.Eventually(t, func() bool {
+		return server.Requests(path) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = p.BanCoin(ctx, "upbit:KRW-BTC", time.Hour, "maintenance")
+
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		before := server.Requests(path)
+		time.Sleep(100 * time.Millisecond)
+		return server.Requests(path) == before
+	}, 5*time.Second, 10*time.Millisecond)
+	banned := server.Requests(path)
+
+	err = p.UnbanCoin(ctx, "upbit:KRW-BTC")
+
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return server.Requests(path) > banned
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProhibitor_BanCoin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-BTC", time.Now()))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+
+	bannedCoin, err := p.BanCoin(ctx, "KRW-BTC", time.Hour, "maintenance")
+
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-BTC"), bannedCoin.CoinID())
+	require.True(t, bannedCoin.IsManual())
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-BTC")
+	require.NoError(t, err)
+}
+
+func TestProhibitor_BanExpiresAfterRequestIsCancelled(t *testing.T) {
+	t.Parallel()
+	repo, err := sqliterepository.NewRepository(context.Background(), filepath.Join(t.TempDir(), "coins.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = repo.Close()
+	})
+	_, err = repo.CreateCoin(context.Background(), domain.NewCoin("upbit:KRW-BTC", time.Now()))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	require.NoError(t, p.Start(context.Background()))
+	t.Cleanup(p.Stop)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err = p.BanCoin(ctx, "upbit:KRW-BTC", 100*time.Millisecond, "maintenance")
+	cancel()
+
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := repo.GetBannedCoin(context.Background(), "upbit:KRW-BTC")
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProhibitor_BanCoinUnknownCoin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+
+	_, err := p.BanCoin(ctx, "upbit:KRW-NONE", time.Hour, "")
+
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-NONE")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+}
+
+func TestProhibitor_AllowCoinLiftsAutomaticBan(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	now := time.Now()
+	reasons := []domain.BanReason{domain.BanReasonCaution}
+	_, err := repo.CreateBannedCoin(ctx, domain.NewBannedCoin("upbit:KRW-AUTO", now, time.Hour, reasons))
+	require.NoError(t, err)
+	_, err = repo.CreateBannedCoin(ctx, domain.NewManualBannedCoin("upbit:KRW-MANUAL", now, time.Hour, "maintenance"))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+
+	_, autoErr := p.AllowCoin(ctx, "upbit:KRW-AUTO", "")
+	_, manualErr := p.AllowCoin(ctx, "upbit:KRW-MANUAL", "")
+
+	require.NoError(t, autoErr)
+	require.NoError(t, manualErr)
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-AUTO")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-MANUAL")
+	require.NoError(t, err)
+	_, err = repo.GetAllowedCoin(ctx, "upbit:KRW-AUTO")
+	require.NoError(t, err)
+}
+
+func TestProhibitor_QualifiesCoinIDWithDefaultExchange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	_, err := repo.CreateBannedCoin(ctx, domain.NewManualBannedCoin("upbit:KRW-BTC", time.Now(), time.Hour, ""))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), repo)
+	t.Cleanup(p.Stop)
+
+	unbanErr := p.UnbanCoin(ctx, "KRW-BTC")
+	allowedCoin, allowErr := p.AllowCoin(ctx, "KRW-BTC", "")
+	disallowErr := p.DisallowCoin(ctx, "KRW-BTC")
+
+	require.NoError(t, unbanErr)
+	require.NoError(t, allowErr)
+	require.Equal(t, domain.CoinID("upbit:KRW-BTC"), allowedCoin.CoinID())
+	require.NoError(t, disallowErr)
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-BTC")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+	_, err = repo.GetAllowedCoin(ctx, "upbit:KRW-BTC")
+	require.ErrorIs(t, err, coinrepository.ErrAllowedCoinNotFound)
+}
+
+func TestProhibitor_SkipsAllowedCoinOnStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(zap.NewNop())
+	now := time.Now()
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-ALLOWED", now).SetWarning(true))
+	require.NoError(t, err)
+	_, err = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-WARNED", now).SetWarning(true))
+	require.NoError(t, err)
+	_, err = repo.CreateAllowedCoin(ctx, domain.NewAllowedCoin("upbit:KRW-ALLOWED", now, ""))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), bus, repo)
+	require.NoError(t, p.Start(ctx))
+	t.Cleanup(p.Stop)
+
+	bus.Publish(ctx, domain.NewCoinUpdatedEvent(now, "upbit:KRW-ALLOWED", domain.CoinFieldWarning))
+	bus.Publish(ctx, domain.NewCoinUpdatedEvent(now, "upbit:KRW-WARNED", domain.CoinFieldWarning))
+
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-ALLOWED")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+	bannedCoin, err := repo.GetBannedCoin(ctx, "upbit:KRW-WARNED")
+	require.NoError(t, err)
+	require.Equal(t, []domain.BanReason{domain.BanReasonCaution}, bannedCoin.Reasons())
+}
+
+func TestProhibitor_SkipsAllowedCoinOnTrades(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(zap.NewNop())
+	now := time.Now()
+	for _, coinID := range []domain.CoinID{"upbit:KRW-ALLOWED", "upbit:KRW-CHEAP"} {
+		var trades []*domain.Trade
+		for i := range 30 {
+			trades = append(trades, domain.NewTrade(now.AddDate(0, 0, -i), "99", "99", "99", "99"))
+		}
+		_, err := repo.SaveTrades(ctx, domain.NewTrades(coinID, domain.IntervalDay, now, trades))
+		require.NoError(t, err)
+	}
+	_, err := repo.CreateAllowedCoin(ctx, domain.NewAllowedCoin("upbit:KRW-ALLOWED", now, ""))
+	require.NoError(t, err)
+	p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), bus, repo)
+	require.NoError(t, p.Start(ctx))
+	t.Cleanup(p.Stop)
+
+	bus.Publish(ctx, domain.NewTradesUpdatedEvent("upbit:KRW-ALLOWED", domain.IntervalDay, nil))
+	bus.Publish(ctx, domain.NewTradesUpdatedEvent("upbit:KRW-CHEAP", domain.IntervalDay, nil))
+
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-ALLOWED")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+	bannedCoin, err := repo.GetBannedCoin(ctx, "upbit:KRW-CHEAP")
+	require.NoError(t, err)
+	require.Equal(t, []domain.BanReason{domain.BanReasonPriceTooLow}, bannedCoin.Reasons())
+}
+
+func TestProhibitor_PriceRules(t *testing.T) {
+	t.Parallel()
+	usdtRule := prohibitor.WithPriceRule(domain.QuoteUSDT, prohibitor.PriceRule{MinPrice: 0.1, MaxPrice: 10})
+	noMaxRule := prohibitor.WithPriceRule(domain.QuoteKRW, prohibitor.PriceRule{MinPrice: 100, MaxPrice: 0})
+	noMinRule := prohibitor.WithPriceRule(domain.QuoteKRW, prohibitor.PriceRule{MinPrice: 0, MaxPrice: 100})
+	tests := map[string]struct {
+		opts   []prohibitor.Option
+		coinID domain.CoinID
+		price  domain.Price
+		want   []domain.BanReason
+	}{
+		"usdt too low": {
+			opts:   []prohibitor.Option{usdtRule},
+			coinID: "upbit:USDT-CHEAP",
+			price:  "0.05",
+			want:   []domain.BanReason{domain.BanReasonPriceTooLow},
+		},
+		"usdt too high": {
+			opts:   []prohibitor.Option{usdtRule},
+			coinID: "upbit:USDT-DEAR",
+			price:  "20",
+			want:   []domain.BanReason{domain.BanReasonPriceTooHigh},
+		},
+		"usdt in range ignores krw rule": {
+			opts:   []prohibitor.Option{usdtRule},
+			coinID: "upbit:USDT-FINE",
+			price:  "1",
+			want:   nil,
+		},
+		"quote without rule": {
+			opts:   nil,
+			coinID: "upbit:BTC-CHEAP",
+			price:  "0.00000001",
+			want:   nil,
+		},
+		"open-ended max": {
+			opts:   []prohibitor.Option{noMaxRule},
+			coinID: "upbit:KRW-DEAR",
+			price:  "100000000",
+			want:   nil,
+		},
+		"open-ended max still has min": {
+			opts:   []prohibitor.Option{noMaxRule},
+			coinID: "upbit:KRW-CHEAP",
+			price:  "99",
+			want:   []domain.BanReason{domain.BanReasonPriceTooLow},
+		},
+		"open-ended min": {
+			opts:   []prohibitor.Option{noMinRule},
+			coinID: "upbit:KRW-CHEAP",
+			price:  "0.001",
+			want:   nil,
+		},
+		"open-ended min still has max": {
+			opts:   []prohibitor.Option{noMinRule},
+			coinID: "upbit:KRW-DEAR",
+			price:  "101",
+			want:   []domain.BanReason{domain.BanReasonPriceTooHigh},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			repo := realrepositorytest.New(t)
+			bus := local.NewBus(zap.NewNop())
+			now := time.Now()
+			var trades []*domain.Trade
+			for i := range 30 {
+				trades = append(trades, domain.NewTrade(now.AddDate(0, 0, -i), test.price, test.price, test.price, test.price))
+			}
+			_, err := repo.SaveTrades(ctx, domain.NewTrades(test.coinID, domain.IntervalDay, now, trades))
+			require.NoError(t, err)
+			p := prohibitor.NewProhibitor(noop.NewTracer(), zap.NewNop(), bus, repo, test.opts...)
+			require.NoError(t, p.Start(ctx))
+			t.Cleanup(p.Stop)
+
+			bus.Publish(ctx, domain.NewTradesUpdatedEvent(test.coinID, domain.IntervalDay, nil))
+
+			bannedCoin, err := repo.GetBannedCoin(ctx, test.coinID)
+			if test.want == nil {
+				require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, bannedCoin.Reasons())
+		})
+	}
+}
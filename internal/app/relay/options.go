@@ -0,0 +1,31 @@
+package relay
+
+import "time"
+
+type Options struct {
+	// Interval outbox를 확인하는 주기.
+	Interval time.Duration
+	// BatchSize 한 번에 읽어 발행하는 이벤트 수.
+	BatchSize int
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Interval:  200 * time.Millisecond, //nolint:mnd
+		BatchSize: 100,                    //nolint:mnd
+	}
+}
+
+type Option func(*Options)
+
+func WithInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = interval
+	}
+}
+
+func WithBatchSize(batchSize int) Option {
+	return func(o *Options) {
+		o.BatchSize = max(batchSize, 1)
+	}
+}
@@ -0,0 +1,118 @@
+package relay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	coinrepository.ListOutboxEventsQuery
+	coinrepository.DeleteOutboxEventCommand
+}
+
+// Publisher 버스가 이벤트를 넘겨받았는지 확인할 수 있게 발행한다.
+// 로컬 버스는 구독자가 처리를 마칠 때까지, NATS는 JetStream이 저장했다고 ack할 때까지 기다린다.
+type Publisher interface {
+	PublishConfirmed(ctx context.Context, event domain.Event) error
+}
+
+// Relay outbox에 쌓인 이벤트를 버스로 발행한다.
+// 버스가 넘겨받은 것을 확인한 뒤에 지우므로 지우기 전에 멈추거나 발행에 실패하면 다시 발행한다. 즉 한 번 이상 전달한다.
+type Relay struct {
+	logger    *zap.Logger
+	bus       Publisher
+	repo      Repository
+	interval  time.Duration
+	batchSize int
+
+	mu     sync.Mutex // Deliver를 동시에 실행하면 같은 이벤트를 두 번 발행한다.
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRelay(logger *zap.Logger, bus Publisher, repo Repository, opts ...Option) *Relay {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &Relay{
+		logger:    logger,
+		bus:       bus,
+		repo:      repo,
+		interval:  options.Interval,
+		batchSize: options.BatchSize,
+		mu:        sync.Mutex{},
+		cancel:    nil,
+		done:      nil,
+	}
+}
+
+// Start 멈춘 동안 쌓인 이벤트를 먼저 발행한 뒤 주기적으로 outbox를 비운다.
+// 구독자가 모두 구독한 뒤에 시작해야 한다.
+func (r *Relay) Start(ctx context.Context) error {
+	err := r.Deliver(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	r.done = make(chan struct{})
+	go r.run(ctx)
+	return nil
+}
+
+func (r *Relay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		err := r.Deliver(ctx)
+		if err != nil {
+			r.logger.Error("failed to relay events", zap.Error(err))
+		}
+	}
+}
+
+// Deliver outbox가 빌 때까지 이벤트를 기록한 순서대로 발행한다.
+// 발행에 실패하면 순서를 지키기 위해 멈추고, 남은 이벤트는 다음 주기에 다시 발행한다.
+func (r *Relay) Deliver(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for {
+		events, err := r.repo.ListOutboxEvents(ctx, r.batchSize)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, event := range events {
+			err := r.bus.PublishConfirmed(ctx, event)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			err = r.repo.DeleteOutboxEvent(ctx, event.EventID())
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if len(events) < r.batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package relay_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/relay"
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/memory"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRelay_DeliversEventsWrittenBeforeRestart(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	store := memory.NewStore()
+	repo := realrepositorytest.Open(t, store)
+	now := time.Now()
+	coin := domain.NewCoin("upbit:KRW-A", now)
+	_, err := repo.CreateCoin(ctx, coin, domain.NewCoinCreatedEvent(now, coin.ID()))
+	require.NoError(t, err)
+	// 이미 있는 코인이라 실패했으므로 이벤트도 기록되지 않는다.
+	_, err = repo.CreateCoin(ctx, coin, domain.NewCoinCreatedEvent(now, coin.ID()))
+	require.Error(t, err)
+	_, err = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-B", now), domain.NewCoinCreatedEvent(now, "upbit:KRW-B"))
+	require.NoError(t, err)
+	// 발행하기 전에 멈췄다.
+	repo = realrepositorytest.Open(t, store)
+	b := local.NewBus(logger)
+	var mu sync.Mutex
+	var received []domain.Event
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, event domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		return nil
+	}))
+	r := relay.NewRelay(logger, b, repo, relay.WithBatchSize(1))
+
+	err = r.Start(ctx)
+	t.Cleanup(r.Stop)
+
+	require.NoError(t, err)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+	for i, coinID := range []domain.CoinID{"upbit:KRW-A", "upbit:KRW-B"} {
+		coinCreated, err := domain.ParseCoinCreatedEvent(received[i].Payload())
+		require.NoError(t, err)
+		require.Equal(t, coinID, coinCreated.CoinID)
+	}
+	first, ok := received[0].(domain.IdentifiedEvent)
+	require.True(t, ok)
+	second, ok := received[1].(domain.IdentifiedEvent)
+	require.True(t, ok)
+	require.NotEqual(t, first.EventID(), second.EventID())
+	pending, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestRelay_DeletesAfterAsyncSubscribersHandled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	repo := realrepositorytest.New(t)
+	now := time.Now()
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-A", now), domain.NewCoinCreatedEvent(now, "upbit:KRW-A"))
+	require.NoError(t, err)
+	b := local.NewBus(logger, local.WithAsync(16, 1))
+	t.Cleanup(b.Close)
+	release := make(chan struct{})
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, _ domain.Event) error {
+		<-release
+		return nil
+	}))
+	r := relay.NewRelay(logger, b, repo)
+	delivered := make(chan error, 1)
+
+	go func() {
+		delivered <- r.Deliver(ctx)
+	}()
+
+	require.Never(t, func() bool {
+		return len(delivered) > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+	pending, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	close(release)
+	require.NoError(t, <-delivered)
+	pending, err = repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestRelay_KeepsEventsWhenPublishFails(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	repo := realrepositorytest.New(t)
+	now := time.Now()
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-A", now), domain.NewCoinCreatedEvent(now, "upbit:KRW-A"))
+	require.NoError(t, err)
+	b := local.NewBus(logger, local.WithAsync(16, 1))
+	b.Close()
+	r := relay.NewRelay(logger, b, repo)
+
+	err = r.Deliver(ctx)
+
+	require.ErrorIs(t, err, bus.ErrClosed)
+	pending, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+}
+
+// flakyPublisher 처음 failures번은 발행에 실패한다.
+type flakyPublisher struct {
+	failures  int
+	published []domain.CoinID
+}
+
+func (p *flakyPublisher) PublishConfirmed(_ context.Context, event domain.Event) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("publish failed")
+	}
+	coinCreated, err := domain.ParseCoinCreatedEvent(event.Payload())
+	if err != nil {
+		return err
+	}
+	p.published = append(p.published, coinCreated.CoinID)
+	return nil
+}
+
+func TestRelay_RetriesFailedPublishInOrder(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	now := time.Now()
+	for _, coinID := range []domain.CoinID{"upbit:KRW-A", "upbit:KRW-B"} {
+		_, err := repo.CreateCoin(ctx, domain.NewCoin(coinID, now), domain.NewCoinCreatedEvent(now, coinID))
+		require.NoError(t, err)
+	}
+	publisher := &flakyPublisher{failures: 1}
+	r := relay.NewRelay(zap.NewNop(), publisher, repo)
+
+	first := r.Deliver(ctx)
+	second := r.Deliver(ctx)
+
+	require.Error(t, first)
+	require.NoError(t, second)
+	require.Equal(t, []domain.CoinID{"upbit:KRW-A", "upbit:KRW-B"}, publisher.published)
+	pending, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
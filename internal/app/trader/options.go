@@ -0,0 +1,47 @@
+package trader
+
+import (
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+// Schedule 특정 단위의 캔들을 얼마나 자주 최신화할지 나타낸다.
+type Schedule struct {
+	Interval domain.Interval
+	Every    time.Duration
+}
+
+type Options struct {
+	Schedules       []Schedule
+	BackfillHorizon time.Duration
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+type Option func(*Options)
+
+// WithSchedule interval 단위의 캔들을 every 마다 최신화한다.
+// every가 0이면 캔들 단위에 맞는 기본 주기를 사용한다.
+func WithSchedule(interval domain.Interval, every time.Duration) Option {
+	return func(o *Options) {
+		if every <= 0 {
+			every = defaultRefreshInterval(interval)
+		}
+		o.Schedules = append(o.Schedules, Schedule{Interval: interval, Every: every})
+	}
+}
+
+// defaultRefreshInterval 캔들 단위가 짧으면 그만큼 자주, 아니면 10분마다 최신화한다.
+func defaultRefreshInterval(interval domain.Interval) time.Duration {
+	return min(interval.Duration(), refreshInterval)
+}
+
+// WithBackfill 최근 horizon 기간의 과거 캔들을 채운다. 상장일이 더 최근이라면 상장일까지만 채운다.
+func WithBackfill(horizon time.Duration) Option {
+	return func(o *Options) {
+		o.BackfillHorizon = horizon
+	}
+}
@@ -2,10 +2,12 @@ package trader
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
 	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinservice"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
 	"github.com/biosvos/coin-cache-service/pkg/tracer"
 	"github.com/go-co-op/gocron/v2"
@@ -14,7 +16,10 @@ import (
 )
 
 type Service interface {
+	coinservice.ExchangeQuery
+	coinservice.IntervalsQuery
 	coinrepository.ListTradesQuery
+	coinservice.ListTradesBeforeQuery
 }
 
 type Repository interface {
@@ -22,6 +27,7 @@ type Repository interface {
 	coinrepository.ListCoinsQuery
 	coinrepository.SaveTradesCommand
 	coinrepository.DeleteTradesCommand
+	coinrepository.ListTradesQuery
 	coinrepository.GetCoinQuery
 }
 
@@ -30,13 +36,34 @@ type Trader struct {
 	service   Service
 	repo      Repository
 	scheduler gocron.Scheduler
+	schedules []Schedule
+	horizon   time.Duration
 	logger    *zap.Logger
 	tracer    tracer.Tracer
 }
 
-const interval = time.Minute * 10
+const refreshInterval = time.Minute * 10
+
+// backfillTag 과거 캔들을 채우는 작업에 붙여 Start에서 다시 실행하지 않게 한다.
+const backfillTag = "backfill"
 
-func NewTrader(tracer tracer.Tracer, logger *zap.Logger, bus bus.Bus, service Service, repo Repository) *Trader {
+// NewTrader service 거래소의 코인만 다룬다. 거래소마다 Trader를 하나씩 둔다.
+// 별도의 schedule이 없다면 일 캔들만 10분마다 최신화한다.
+func NewTrader(
+	tracer tracer.Tracer,
+	logger *zap.Logger,
+	bus bus.Bus,
+	service Service,
+	repo Repository,
+	opts ...Option,
+) *Trader {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	if len(options.Schedules) == 0 {
+		WithSchedule(domain.IntervalDay, refreshInterval)(options)
+	}
 	scheduler, _ := gocron.NewScheduler() // option이 없으면 error도 발생하지 않는다.
 	ctx := context.Background()
 	coins, err := repo.ListCoins(ctx)
@@ -58,29 +85,68 @@ func NewTrader(tracer tracer.Tracer, logger *zap.Logger, bus bus.Bus, service Se
 		service:   service,
 		repo:      repo,
 		scheduler: scheduler,
+		schedules: supportedSchedules(logger, service, options.Schedules),
+		horizon:   options.BackfillHorizon,
 	}
 
 	for _, coin := range coins {
+		if !ret.owns(coin.ID()) {
+			continue
+		}
 		if _, ok := bannedCoinMap[coin.ID()]; ok {
 			continue
 		}
-		_ = ret.addRefreshTradesJob(coin.ID())
+		_ = ret.addRefreshTradesJobs(coin.ID())
+		ret.addBackfillTradesJobs(coin.ID())
 	}
 	return &ret
 }
 
-func (t *Trader) Start(ctx context.Context) {
+func (t *Trader) Start(ctx context.Context) error {
 	t.scheduler.Start()
-	t.bus.Subscribe(ctx, domain.CoinCreatedEventTopic, t.handleCoinCreatedEvent)
-	t.bus.Subscribe(ctx, domain.CoinDeletedEventTopic, t.handleCoinDeletedEvent)
-	t.bus.Subscribe(ctx, domain.BannedCoinCreatedEventTopic, t.handleBannedCoinCreatedEvent)
-	t.bus.Subscribe(ctx, domain.BannedCoinDeletedEventTopic, t.handleBannedCoinDeletedEvent)
+	// 거래소마다 Trader가 있으므로 구독 이름에 거래소를 붙인다.
+	// 최신화 작업은 프로세스의 스케줄러에 있으므로 프로세스마다 모든 이벤트를 받는다.
+	name := bus.WithName("trader:" + string(t.service.Exchange()))
+	handlers := map[string]func(ctx context.Context, event domain.Event) error{
+		domain.CoinCreatedEventTopic:       t.handleCoinCreatedEvent,
+		domain.CoinDeletedEventTopic:       t.handleCoinDeletedEvent,
+		domain.BannedCoinCreatedEventTopic: t.handleBannedCoinCreatedEvent,
+		domain.BannedCoinDeletedEventTopic: t.handleBannedCoinDeletedEvent,
+	}
+	for topic, handler := range handlers {
+		err := t.bus.Subscribe(ctx, topic, handler, name, bus.WithFanOut())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
 	for _, job := range t.scheduler.Jobs() {
+		if slices.Contains(job.Tags(), backfillTag) {
+			continue // 스케줄러가 시작하면서 바로 실행한다.
+		}
 		err := job.RunNow()
 		if err != nil {
 			t.logger.Error("failed to run job", zap.Error(err))
 		}
 	}
+	return nil
+}
+
+// supportedSchedules 거래소가 주지 않는 단위는 최신화하지 않는다.
+func supportedSchedules(logger *zap.Logger, service Service, schedules []Schedule) []Schedule {
+	supported := service.Intervals()
+	var ret []Schedule
+	for _, schedule := range schedules {
+		if !slices.Contains(supported, schedule.Interval) {
+			logger.Warn(
+				"skip unsupported interval",
+				zap.String("exchange", string(service.Exchange())),
+				zap.String("interval", string(schedule.Interval)),
+			)
+			continue
+		}
+		ret = append(ret, schedule)
+	}
+	return ret
 }
 
 func (t *Trader) Stop() {
@@ -90,17 +156,64 @@ func (t *Trader) Stop() {
 	}
 }
 
-func (t *Trader) addRefreshTradesJob(coinID domain.CoinID) gocron.Job {
-	job, _ := t.scheduler.NewJob(
-		gocron.DurationJob(interval),
-		gocron.NewTask(
-			t.RefreshTrades,
-			context.Background(),
-			coinID,
-		),
-		gocron.WithTags(string(coinID)),
-	)
-	return job
+// owns coinID가 이 Trader가 맡은 거래소의 코인인지 확인한다.
+func (t *Trader) owns(coinID domain.CoinID) bool {
+	return coinID.Exchange() == t.service.Exchange()
+}
+
+// addRefreshTradesJobs 코인의 캔들 단위마다 최신화 작업을 등록한다.
+func (t *Trader) addRefreshTradesJobs(coinID domain.CoinID) []gocron.Job {
+	var ret []gocron.Job
+	for _, schedule := range t.schedules {
+		job, err := t.scheduler.NewJob(
+			gocron.DurationJob(schedule.Every),
+			gocron.NewTask(
+				t.RefreshTrades,
+				context.Background(),
+				coinID,
+				schedule.Interval,
+			),
+			gocron.WithTags(string(coinID)),
+		)
+		if err != nil {
+			t.logger.Error("failed to add refresh trades job", zap.Error(err))
+			continue
+		}
+		ret = append(ret, job)
+	}
+	return ret
+}
+
+// addBackfillTradesJobs 코인의 캔들 단위마다 과거 캔들을 채우는 작업을 한 번만 실행한다.
+// 금지가 풀렸을 때는 부르지 않는다. 이미 채운 과거를 다시 내려받을 필요가 없다.
+func (t *Trader) addBackfillTradesJobs(coinID domain.CoinID) {
+	if t.horizon <= 0 {
+		return
+	}
+	for _, schedule := range t.schedules {
+		_, err := t.scheduler.NewJob(
+			gocron.OneTimeJob(gocron.OneTimeJobStartImmediately()),
+			gocron.NewTask(
+				t.BackfillTrades,
+				context.Background(),
+				coinID,
+				schedule.Interval,
+			),
+			gocron.WithTags(string(coinID), backfillTag),
+		)
+		if err != nil {
+			t.logger.Error("failed to add backfill trades job", zap.Error(err))
+		}
+	}
+}
+
+func runJobs(span tracer.Span, jobs []gocron.Job) {
+	for _, job := range jobs {
+		err := job.RunNow()
+		if err != nil {
+			span.Error(err)
+		}
+	}
 }
 
 func (t *Trader) removeRefreshTradesJob(coinID domain.CoinID) {
@@ -111,13 +224,18 @@ func (t *Trader) handleCoinCreatedEvent(ctx context.Context, event domain.Event)
 	_, span := t.tracer.Start(ctx, "trader.handleCoinCreatedEvent")
 	defer span.End()
 
-	coinCreatedEvent := domain.ParseCoinCreatedEvent(event.Payload())
-	span.String("coin_id", string(coinCreatedEvent.CoinID))
-	job := t.addRefreshTradesJob(coinCreatedEvent.CoinID)
-	err := job.RunNow()
+	coinCreatedEvent, err := domain.ParseCoinCreatedEvent(event.Payload())
 	if err != nil {
 		span.Error(err)
+		return err
 	}
+	span.String("coin_id", string(coinCreatedEvent.CoinID))
+	if !t.owns(coinCreatedEvent.CoinID) {
+		return nil
+	}
+	jobs := t.addRefreshTradesJobs(coinCreatedEvent.CoinID)
+	t.addBackfillTradesJobs(coinCreatedEvent.CoinID)
+	runJobs(span, jobs)
 	return nil
 }
 
@@ -125,8 +243,15 @@ func (t *Trader) handleCoinDeletedEvent(ctx context.Context, event domain.Event)
 	_, span := t.tracer.Start(ctx, "trader.handleCoinDeletedEvent")
 	defer span.End()
 
-	coinDeletedEvent := domain.ParseCoinDeletedEvent(event.Payload())
+	coinDeletedEvent, err := domain.ParseCoinDeletedEvent(event.Payload())
+	if err != nil {
+		span.Error(err)
+		return err
+	}
 	span.String("coin_id", string(coinDeletedEvent.CoinID))
+	if !t.owns(coinDeletedEvent.CoinID) {
+		return nil
+	}
 
 	t.removeRefreshTradesJob(coinDeletedEvent.CoinID)
 
@@ -138,7 +263,11 @@ func (t *Trader) handleBannedCoinCreatedEvent(ctx context.Context, event domain.
 	_, span := t.tracer.Start(ctx, "trader.handleBannedCoinCreatedEvent")
 	defer span.End()
 
-	bannedCoinCreatedEvent := domain.ParseBannedCoinCreatedEvent(event.Payload())
+	bannedCoinCreatedEvent, err := domain.ParseBannedCoinCreatedEvent(event.Payload())
+	if err != nil {
+		span.Error(err)
+		return err
+	}
 	span.String("coin_id", string(bannedCoinCreatedEvent.CoinID))
 
 	t.removeRefreshTradesJob(bannedCoinCreatedEvent.CoinID)
@@ -149,8 +278,15 @@ func (t *Trader) handleBannedCoinDeletedEvent(ctx context.Context, event domain.
 	_, span := t.tracer.Start(ctx, "trader.handleBannedCoinDeletedEvent")
 	defer span.End()
 
-	bannedCoinDeletedEvent := domain.ParseBannedCoinDeletedEvent(event.Payload())
+	bannedCoinDeletedEvent, err := domain.ParseBannedCoinDeletedEvent(event.Payload())
+	if err != nil {
+		span.Error(err)
+		return err
+	}
 	span.String("coin_id", string(bannedCoinDeletedEvent.CoinID))
+	if !t.owns(bannedCoinDeletedEvent.CoinID) {
+		return nil
+	}
 
 	coin, err := t.repo.GetCoin(ctx, bannedCoinDeletedEvent.CoinID)
 	switch {
@@ -158,11 +294,8 @@ func (t *Trader) handleBannedCoinDeletedEvent(ctx context.Context, event domain.
 		return nil
 
 	case err == nil:
-		job := t.addRefreshTradesJob(coin.ID())
-		err = job.RunNow()
-		if err != nil {
-			span.Error(err)
-		}
+		jobs := t.addRefreshTradesJobs(coin.ID())
+		runJobs(span, jobs)
 		return nil
 
 	default:
@@ -171,30 +304,99 @@ func (t *Trader) handleBannedCoinDeletedEvent(ctx context.Context, event domain.
 	}
 }
 
-func (t *Trader) RefreshTrades(ctx context.Context, coinID domain.CoinID) {
+func (t *Trader) RefreshTrades(ctx context.Context, coinID domain.CoinID, interval domain.Interval) {
 	ctx, span := t.tracer.Start(ctx, "trader.RefreshTrades")
 	defer span.End()
 	span.String("coin_id", string(coinID))
+	span.String("interval", string(interval))
 
-	trades, err := t.service.ListTrades(ctx, coinID)
+	trades, err := t.service.ListTrades(ctx, coinID, interval)
 	if err != nil {
 		span.Error(err)
 		return
 	}
-	err = t.repo.SaveTrades(ctx, trades)
+	changes, err := t.repo.SaveTrades(ctx, trades)
 	if err != nil {
 		span.Error(err)
 		return
 	}
+	span.Int64("changes", int64(len(changes)))
+	if len(changes) == 0 {
+		return
+	}
+	t.publish(ctx, domain.NewTradesUpdatedEvent(coinID, interval, changes))
 }
 
+// BackfillTrades 저장된 가장 오래된 캔들부터 과거로 거슬러 올라가며 horizon 기간까지 캔들을 채운다.
+// 거래소가 더 이상 캔들을 주지 않으면 상장일에 도달한 것으로 보고 멈춘다.
+// 이벤트가 너무 커지지 않도록 페이지마다 따로 발행한다.
+func (t *Trader) BackfillTrades(ctx context.Context, coinID domain.CoinID, interval domain.Interval) { //nolint:cyclop
+	ctx, span := t.tracer.Start(ctx, "trader.BackfillTrades")
+	defer span.End()
+	span.String("coin_id", string(coinID))
+	span.String("interval", string(interval))
+
+	now := time.Now()
+	to := now
+	stored, err := t.repo.ListTrades(ctx, coinID, interval)
+	switch {
+	case err == nil && stored.Size() > 0:
+		to = stored.OldestDate()
+	case err != nil && !errors.Is(err, coinrepository.ErrTradesNotFound):
+		span.Error(err)
+		return
+	}
+
+	const pageSize = 200
+	horizon := now.Add(-t.horizon)
+	var pages int64
+	var changes int64
+	for to.After(horizon) {
+		page, err := t.service.ListTradesBefore(ctx, coinID, interval, to, pageSize)
+		if err != nil {
+			span.Error(err)
+			break
+		}
+		if page.Size() == 0 {
+			break
+		}
+		pageChanges, err := t.repo.SaveTrades(ctx, page)
+		if err != nil {
+			span.Error(err)
+			break
+		}
+		if len(pageChanges) > 0 {
+			t.publish(ctx, domain.NewTradesUpdatedEvent(coinID, interval, pageChanges))
+		}
+		changes += int64(len(pageChanges))
+		pages++
+		oldest := page.OldestDate()
+		if page.Size() < pageSize || !oldest.Before(to) {
+			break
+		}
+		to = oldest
+	}
+	span.Int64("pages", pages)
+	span.Int64("changes", changes)
+}
+
+// DeleteTrades 모든 단위의 캔들을 삭제한다. 설정에서 뺀 단위의 캔들도 함께 지운다.
 func (t *Trader) DeleteTrades(ctx context.Context, coinID domain.CoinID) {
 	ctx, span := t.tracer.Start(ctx, "trader.DeleteTrades")
 	defer span.End()
 	span.String("coin_id", string(coinID))
 
-	err := t.repo.DeleteTrades(ctx, coinID)
+	intervals, err := t.repo.DeleteTrades(ctx, coinID)
 	if err != nil {
 		span.Error(err)
+		return
+	}
+	for _, interval := range intervals {
+		t.publish(ctx, domain.NewTradesDeletedEvent(coinID, interval))
 	}
 }
+
+// publish 발행하는 시점의 trace context를 봉투에 남긴다.
+func (t *Trader) publish(ctx context.Context, event domain.Event) {
+	t.bus.Publish(ctx, bus.Seal(ctx, t.tracer, "trader:"+string(t.service.Exchange()), event))
+}
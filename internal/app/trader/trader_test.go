@@ -0,0 +1,191 @@
+package trader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/trader"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeService listedAt부터 오늘까지 매일 하나씩 캔들을 가진다.
+// intervals가 비어 있으면 모든 단위의 캔들을 준다.
+type fakeService struct {
+	listedAt  time.Time
+	today     time.Time
+	intervals []domain.Interval
+	requests  atomic.Int64
+}
+
+func (f *fakeService) Exchange() domain.Exchange {
+	return domain.ExchangeUpbit
+}
+
+func (f *fakeService) Intervals() []domain.Interval {
+	if len(f.intervals) == 0 {
+		return domain.Intervals()
+	}
+	return f.intervals
+}
+
+func (f *fakeService) ListTrades(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+) (*domain.Trades, error) {
+	return f.ListTradesBefore(ctx, coinID, interval, time.Time{}, 20)
+}
+
+func (f *fakeService) ListTradesBefore(
+	_ context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+	to time.Time,
+	count int,
+) (*domain.Trades, error) {
+	f.requests.Add(1)
+	date := f.today
+	if !to.IsZero() {
+		date = to.AddDate(0, 0, -1)
+	}
+	var trades []*domain.Trade
+	for ; len(trades) < count && !date.Before(f.listedAt); date = date.AddDate(0, 0, -1) {
+		trades = append(trades, domain.NewTrade(date, "100", "100", "100", "100"))
+	}
+	return domain.NewTrades(coinID, interval, time.Now(), trades), nil
+}
+
+func TestTrader_BackfillTradesStopsAtListingDate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	service := &fakeService{listedAt: today.AddDate(0, 0, -449), today: today}
+	tr := trader.NewTrader(
+		noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), service, repo,
+		trader.WithBackfill(2*365*24*time.Hour),
+	)
+	tr.RefreshTrades(ctx, "KRW-A", domain.IntervalDay)
+
+	tr.BackfillTrades(ctx, "KRW-A", domain.IntervalDay)
+
+	trades, err := repo.ListTrades(ctx, "KRW-A", domain.IntervalDay)
+	require.NoError(t, err)
+	require.Equal(t, 450, trades.Size())
+	require.Equal(t, service.listedAt, trades.OldestDate())
+	require.Equal(t, int64(4), service.requests.Load())
+}
+
+func TestTrader_BackfillTradesStopsAtHorizon(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	service := &fakeService{listedAt: today.AddDate(-5, 0, 0), today: today}
+	tr := trader.NewTrader(
+		noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), service, repo,
+		trader.WithBackfill(30*24*time.Hour),
+	)
+
+	tr.BackfillTrades(ctx, "KRW-A", domain.IntervalDay)
+
+	trades, err := repo.ListTrades(ctx, "KRW-A", domain.IntervalDay)
+	require.NoError(t, err)
+	require.Equal(t, 200, trades.Size())
+	require.Equal(t, int64(1), service.requests.Load())
+}
+
+func TestTrader_BackfillTradesPublishesEachPage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(zap.NewNop())
+	var created []int
+	handler := func(_ context.Context, event domain.Event) error {
+		tradesUpdated, err := domain.ParseTradesUpdatedEvent(event.Payload())
+		if err != nil {
+			return err
+		}
+		created = append(created, len(tradesUpdated.Created))
+		return nil
+	}
+	require.NoError(t, bus.Subscribe(ctx, domain.TradesUpdatedEventTopic, handler))
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	service := &fakeService{listedAt: today.AddDate(0, 0, -449), today: today}
+	tr := trader.NewTrader(
+		noop.NewTracer(), zap.NewNop(), bus, service, repo,
+		trader.WithBackfill(2*365*24*time.Hour),
+	)
+
+	tr.BackfillTrades(ctx, "KRW-A", domain.IntervalDay)
+
+	require.Equal(t, []int{200, 200, 49}, created)
+}
+
+func TestTrader_StartBackfillsOnce(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-A", time.Now()))
+	require.NoError(t, err)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	service := &fakeService{
+		listedAt:  today.AddDate(0, 0, -10),
+		today:     today,
+		intervals: []domain.Interval{domain.IntervalDay},
+	}
+	tr := trader.NewTrader(
+		noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), service, repo,
+		trader.WithSchedule(domain.IntervalDay, time.Hour),
+		trader.WithBackfill(30*24*time.Hour),
+	)
+
+	require.NoError(t, tr.Start(ctx))
+	t.Cleanup(tr.Stop)
+
+	// 최신화 한 번, 과거 캔들 채우기 한 번.
+	require.Eventually(t, func() bool {
+		return service.requests.Load() == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool {
+		return service.requests.Load() > 2
+	}, 200*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestTrader_SkipsUnsupportedIntervals(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-A", time.Now()))
+	require.NoError(t, err)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	service := &fakeService{
+		listedAt:  today.AddDate(0, 0, -10),
+		today:     today,
+		intervals: []domain.Interval{domain.IntervalDay},
+	}
+	tr := trader.NewTrader(
+		noop.NewTracer(), zap.NewNop(), local.NewBus(zap.NewNop()), service, repo,
+		trader.WithSchedule(domain.IntervalDay, time.Hour),
+		trader.WithSchedule(domain.IntervalWeek, time.Hour),
+	)
+
+	require.NoError(t, tr.Start(ctx))
+	t.Cleanup(tr.Stop)
+
+	require.Eventually(t, func() bool {
+		_, err := repo.ListTrades(ctx, "upbit:KRW-A", domain.IntervalDay)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, int64(1), service.requests.Load())
+	_, err = repo.ListTrades(ctx, "upbit:KRW-A", domain.IntervalWeek)
+	require.ErrorIs(t, err, coinrepository.ErrTradesNotFound)
+}
@@ -0,0 +1,180 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinservice"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var ErrTickerNotFound = errors.New("ticker not found")
+
+type Repository interface {
+	coinrepository.ListCoinsQuery
+	coinrepository.ListBannedCoinsQuery
+}
+
+// Stream 거래소의 실시간 현재가 스트림.
+type Stream interface {
+	coinservice.ExchangeQuery
+	Subscribe(coinIDs []domain.CoinID)
+	Run(ctx context.Context, handle func(*domain.Ticker))
+}
+
+// Watcher 금지되지 않은 코인의 실시간 현재가를 메모리에 들고 있는다.
+type Watcher struct {
+	logger *zap.Logger
+	bus    bus.Bus
+	repo   Repository
+	stream Stream
+
+	resubscribeMu sync.Mutex // 먼저 읽은 코인 목록으로 나중에 구독하지 않도록 한 번에 하나씩 다시 구독한다.
+
+	mu      sync.RWMutex
+	tickers map[domain.CoinID]*domain.Ticker
+	watched map[domain.CoinID]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewWatcher(logger *zap.Logger, bus bus.Bus, repo Repository, stream Stream) *Watcher {
+	return &Watcher{
+		logger: logger,
+		bus:    bus,
+		repo:   repo,
+		stream: stream,
+
+		resubscribeMu: sync.Mutex{},
+
+		mu:      sync.RWMutex{},
+		tickers: map[domain.CoinID]*domain.Ticker{},
+		watched: map[domain.CoinID]struct{}{},
+		cancel:  nil,
+		done:    nil,
+	}
+}
+
+// Start 시세는 프로세스마다 따로 받으므로 코인 목록이 바뀌는 이벤트도 프로세스마다 모두 받는다.
+func (w *Watcher) Start(ctx context.Context) error {
+	topics := []string{
+		domain.CoinCreatedEventTopic,
+		domain.CoinDeletedEventTopic,
+		domain.BannedCoinCreatedEventTopic,
+		domain.BannedCoinDeletedEventTopic,
+	}
+	for _, topic := range topics {
+		err := w.bus.Subscribe(ctx, topic, w.handleCoinsChanged, bus.WithName("watcher"), bus.WithFanOut())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	err := w.resubscribe(ctx)
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		w.stream.Run(runCtx, w.store)
+	}()
+	return nil
+}
+
+func (w *Watcher) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	<-w.done
+}
+
+func (w *Watcher) handleCoinsChanged(ctx context.Context, _ domain.Event) error {
+	return w.resubscribe(ctx)
+}
+
+// resubscribe 저장된 코인 중 금지되지 않은 스트림 거래소의 코인을 다시 구독한다.
+// 더 이상 구독하지 않는 코인의 현재가는 지운다.
+func (w *Watcher) resubscribe(ctx context.Context) error {
+	w.resubscribeMu.Lock()
+	defer w.resubscribeMu.Unlock()
+
+	coins, err := w.repo.ListCoins(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bannedCoins, err := w.repo.ListBannedCoins(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	banned := make(map[domain.CoinID]struct{}, len(bannedCoins))
+	for _, bannedCoin := range bannedCoins {
+		banned[bannedCoin.CoinID()] = struct{}{}
+	}
+	watched := make(map[domain.CoinID]struct{}, len(coins))
+	var coinIDs []domain.CoinID
+	for _, coin := range coins {
+		if coin.ID().Exchange() != w.stream.Exchange() {
+			continue
+		}
+		if _, ok := banned[coin.ID()]; ok {
+			continue
+		}
+		watched[coin.ID()] = struct{}{}
+		coinIDs = append(coinIDs, coin.ID())
+	}
+
+	w.mu.Lock()
+	w.watched = watched
+	for coinID := range w.tickers {
+		if _, ok := watched[coinID]; !ok {
+			delete(w.tickers, coinID)
+		}
+	}
+	w.mu.Unlock()
+
+	w.stream.Subscribe(coinIDs)
+	w.logger.Info("subscribe tickers", zap.Int("coins", len(coinIDs)))
+	return nil
+}
+
+func (w *Watcher) store(ticker *domain.Ticker) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.watched[ticker.CoinID()]; !ok {
+		return // 구독을 바꾸기 전에 보낸 메시지
+	}
+	w.tickers[ticker.CoinID()] = ticker
+}
+
+// ListTickers 받은 현재가를 코인 ID 순으로 돌려준다.
+func (w *Watcher) ListTickers() []*domain.Ticker {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	ret := make([]*domain.Ticker, 0, len(w.tickers))
+	for _, ticker := range w.tickers {
+		ret = append(ret, ticker)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].CoinID() < ret[j].CoinID()
+	})
+	return ret
+}
+
+func (w *Watcher) GetTicker(coinID domain.CoinID) (*domain.Ticker, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	ticker, ok := w.tickers[coinID]
+	if !ok {
+		return nil, errors.Wrap(ErrTickerNotFound, string(coinID))
+	}
+	return ticker, nil
+}
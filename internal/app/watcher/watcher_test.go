@@ -0,0 +1,135 @@
+package watcher_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/app/watcher"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit/upbittest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWatcher_DropsBannedCoin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	repo := realrepositorytest.New(t)
+	bus := local.NewBus(logger)
+	now := time.Now()
+	_, _ = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-BTC", now))
+	_, _ = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-ETH", now))
+	_, _ = repo.CreateCoin(ctx, domain.NewCoin("bithumb:BTC_KRW", now))
+	server := upbittest.NewServer()
+	t.Cleanup(server.Close)
+	stream := upbit.NewTickerStream(upbit.WithWebSocketURL(server.WebSocketURL()))
+	w := watcher.NewWatcher(logger, bus, repo, stream)
+	require.NoError(t, w.Start(ctx))
+	t.Cleanup(w.Stop)
+	require.Eventually(t, func() bool {
+		server.PublishTicker(&upbit.Ticker{Code: "KRW-BTC", TradePrice: 100}) //nolint:exhaustruct
+		server.PublishTicker(&upbit.Ticker{Code: "KRW-ETH", TradePrice: 10})  //nolint:exhaustruct
+		return len(w.ListTickers()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	bannedCoin := domain.NewBannedCoin("upbit:KRW-ETH", now, time.Hour, nil)
+	_, _ = repo.CreateBannedCoin(ctx, bannedCoin)
+	bus.Publish(ctx, domain.NewBannedCoinCreatedEvent(bannedCoin.CoinID()))
+
+	_, err := w.GetTicker("upbit:KRW-ETH")
+	require.ErrorIs(t, err, watcher.ErrTickerNotFound)
+	require.Eventually(t, func() bool {
+		subscriptions := server.Subscriptions()
+		return len(subscriptions) > 0 && len(subscriptions[len(subscriptions)-1]) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	ticker, err := w.GetTicker("upbit:KRW-BTC")
+	require.NoError(t, err)
+	require.Equal(t, domain.Price("100"), ticker.Price())
+}
+
+// blockingRepository block이 켜져 있으면 다음 ListCoins가 코인을 읽은 뒤 release를 기다린다.
+type blockingRepository struct {
+	watcher.Repository
+
+	block   atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRepository) ListCoins(ctx context.Context) ([]*domain.Coin, error) {
+	coins, err := r.Repository.ListCoins(ctx)
+	if r.block.CompareAndSwap(true, false) {
+		r.entered <- struct{}{}
+		<-r.release
+	}
+	return coins, err //nolint:wrapcheck
+}
+
+type fakeStream struct {
+	mu            sync.Mutex
+	subscriptions [][]domain.CoinID
+}
+
+func (s *fakeStream) Exchange() domain.Exchange {
+	return domain.ExchangeUpbit
+}
+
+func (s *fakeStream) Subscribe(coinIDs []domain.CoinID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions = append(s.subscriptions, coinIDs)
+}
+
+func (s *fakeStream) Run(ctx context.Context, _ func(*domain.Ticker)) {
+	<-ctx.Done()
+}
+
+func (s *fakeStream) Subscriptions() [][]domain.CoinID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.subscriptions)
+}
+
+func TestWatcher_ResubscribesWithLatestCoins(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	logger := zap.NewNop()
+	realRepo := realrepositorytest.New(t)
+	repo := &blockingRepository{
+		Repository: realRepo,
+		block:      atomic.Bool{},
+		entered:    make(chan struct{}),
+		release:    make(chan struct{}),
+	}
+	bus := local.NewBus(logger)
+	now := time.Now()
+	_, err := realRepo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-BTC", now))
+	require.NoError(t, err)
+	stream := &fakeStream{mu: sync.Mutex{}, subscriptions: nil}
+	w := watcher.NewWatcher(logger, bus, repo, stream)
+	require.NoError(t, w.Start(ctx))
+	t.Cleanup(w.Stop)
+	repo.block.Store(true)
+	go bus.Publish(ctx, domain.NewCoinCreatedEvent(now, "upbit:KRW-BTC"))
+	<-repo.entered
+
+	_, err = realRepo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-ETH", now))
+	require.NoError(t, err)
+	go bus.Publish(ctx, domain.NewBannedCoinDeletedEvent("upbit:KRW-ETH"))
+	time.Sleep(50 * time.Millisecond) // 앞선 구독이 끝나기 전에 다시 구독하려 한다.
+	close(repo.release)
+
+	want := []domain.CoinID{"upbit:KRW-BTC", "upbit:KRW-ETH"}
+	require.Eventually(t, func() bool {
+		subscriptions := stream.Subscriptions()
+		return len(subscriptions) == 3 && slices.Equal(want, subscriptions[2])
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,146 @@
+// Package bithumb 빗썸 공개 API로 코인과 캔들을 조회한다.
+package bithumb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/http"
+	"github.com/pkg/errors"
+)
+
+// statusOK 빗썸은 HTTP 상태와 별도로 본문의 status로 성공 여부를 알려준다.
+const statusOK = "0000"
+
+var ErrBadStatus = errors.New("bithumb returned error status")
+
+type Bithumb struct {
+	baseURL string
+	client  *http.Client
+	limiter *limiter
+}
+
+func NewBithumb(opts ...Option) *Bithumb {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &Bithumb{
+		baseURL: options.BaseURL,
+		client:  http.NewClient(),
+		limiter: newLimiter(options.RequestsPerSecond),
+	}
+}
+
+type response struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (b *Bithumb) get(ctx context.Context, path string) (json.RawMessage, error) {
+	err := b.limiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.client.Get(ctx, b.baseURL+path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, http.ErrTooManyRequests
+	}
+	var ret response
+	err = json.Unmarshal(resp.Body, &ret)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ret.Status != statusOK {
+		return nil, errors.Wrapf(ErrBadStatus, "%v %v", ret.Status, ret.Message)
+	}
+	return ret.Data, nil
+}
+
+// ListTickers quote 마켓 전체의 시세를 조회한다. 키는 대상 코인 이름이다. e.g. BTC
+func (b *Bithumb) ListTickers(ctx context.Context, quote string) (map[string]*Ticker, error) {
+	data, err := b.get(ctx, "/public/ticker/ALL_"+quote)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ret := make(map[string]*Ticker, len(raw))
+	for name, value := range raw {
+		if name == "date" { // 조회 시각이 코인과 같은 수준에 섞여 온다.
+			continue
+		}
+		var ticker Ticker
+		err := json.Unmarshal(value, &ticker)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ret[name] = &ticker
+	}
+	return ret, nil
+}
+
+// ListCandles market의 캔들을 오래된 순으로 조회한다. 빗썸은 개수나 기준 시각을 지정할 수 없다.
+// chartInterval은 "1m", "1h", "24h" 같은 빗썸 캔들 단위이다.
+func (b *Bithumb) ListCandles(ctx context.Context, market string, chartInterval string) ([]*Candle, error) {
+	data, err := b.get(ctx, fmt.Sprintf("/public/candlestick/%v/%v", market, chartInterval))
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]json.RawMessage
+	err = json.Unmarshal(data, &rows)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ret := make([]*Candle, 0, len(rows))
+	for _, row := range rows {
+		candle, err := parseCandle(row)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, candle)
+	}
+	return ret, nil
+}
+
+// parseCandle [기준 시각(ms), 시가, 종가, 고가, 저가, 거래량] 배열을 읽는다.
+func parseCandle(row []json.RawMessage) (*Candle, error) {
+	const columns = 6
+	if len(row) != columns {
+		return nil, errors.Errorf("unexpected candle columns: %v", len(row))
+	}
+	var timestamp json.Number
+	err := json.Unmarshal(row[0], &timestamp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	millis, err := strconv.ParseInt(timestamp.String(), 10, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var values [columns - 1]string
+	for i := range values {
+		err := json.Unmarshal(row[i+1], &values[i])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return &Candle{
+		Date:         time.UnixMilli(millis).UTC(),
+		OpeningPrice: values[0],
+		ClosingPrice: values[1],
+		HighPrice:    values[2],
+		LowPrice:     values[3],
+		Volume:       values[4],
+	}, nil
+}
@@ -0,0 +1,12 @@
+package bithumb
+
+import "time"
+
+type Candle struct {
+	Date         time.Time
+	OpeningPrice string
+	ClosingPrice string
+	HighPrice    string
+	LowPrice     string
+	Volume       string
+}
@@ -0,0 +1,45 @@
+package bithumb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// limiter 빗썸은 남은 요청 수를 알려주지 않으므로 요청 사이를 일정한 간격으로 벌린다.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond int) *limiter {
+	return &limiter{
+		mu:       sync.Mutex{},
+		interval: time.Second / time.Duration(max(perSecond, 1)),
+		next:     time.Time{},
+	}
+}
+
+// wait 차례가 올 때까지 기다린다. 먼저 부른 호출자가 먼저 차례를 받는다.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
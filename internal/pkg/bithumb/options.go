@@ -0,0 +1,47 @@
+package bithumb
+
+import (
+	"strings"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+const defaultBaseURL = "https://api.bithumb.com"
+
+type Options struct {
+	BaseURL string
+	Quotes  []domain.Quote
+	// RequestsPerSecond 초당 보낼 요청 수. 빗썸 공개 API 한도보다 넉넉히 낮게 잡는다.
+	RequestsPerSecond int
+}
+
+func NewOptions() *Options {
+	return &Options{
+		BaseURL:           defaultBaseURL,
+		Quotes:            []domain.Quote{domain.QuoteKRW},
+		RequestsPerSecond: 10, //nolint:mnd
+	}
+}
+
+type Option func(*Options)
+
+// WithBaseURL 빗썸 API 주소를 바꾼다.
+func WithBaseURL(baseURL string) Option {
+	return func(o *Options) {
+		o.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithQuotes quotes 화폐로 거래하는 마켓만 가져온다. 기본은 KRW이다.
+func WithQuotes(quotes ...domain.Quote) Option {
+	return func(o *Options) {
+		o.Quotes = quotes
+	}
+}
+
+// WithRequestsPerSecond 초당 perSecond개까지만 요청한다.
+func WithRequestsPerSecond(perSecond int) Option {
+	return func(o *Options) {
+		o.RequestsPerSecond = perSecond
+	}
+}
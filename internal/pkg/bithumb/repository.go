@@ -0,0 +1,213 @@
+package bithumb
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinservice"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+var _ coinservice.CoinService = (*Service)(nil)
+
+// ErrUnsupportedInterval 빗썸이 제공하지 않는 캔들 단위. e.g. 15m, 1w
+var ErrUnsupportedInterval = errors.New("unsupported interval")
+
+// seriesTTL 과거 캔들을 페이지마다 나눠 요청해도 한 번 받은 캔들을 이 시간 동안 다시 쓴다.
+const seriesTTL = time.Minute
+
+type Service struct {
+	bithumb *Bithumb
+	quotes  []domain.Quote
+
+	seriesMu sync.Mutex
+	series   map[string]*series
+}
+
+// series 한 마켓, 한 단위의 캔들 전체를 오래된 순으로 담는다.
+type series struct {
+	candles   []*Candle
+	fetchedAt time.Time
+}
+
+func NewService(opts ...Option) *Service {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &Service{
+		bithumb:  NewBithumb(opts...),
+		quotes:   options.Quotes,
+		seriesMu: sync.Mutex{},
+		series:   make(map[string]*series),
+	}
+}
+
+// Exchange implements coinservice.CoinService.
+func (s *Service) Exchange() domain.Exchange {
+	return domain.ExchangeBithumb
+}
+
+// Intervals implements coinservice.CoinService. 빗썸은 15분, 4시간, 주, 월 캔들을 주지 않는다.
+func (s *Service) Intervals() []domain.Interval {
+	return []domain.Interval{
+		domain.IntervalMinute1,
+		domain.IntervalMinute3,
+		domain.IntervalMinute5,
+		domain.IntervalMinute30,
+		domain.IntervalMinute60,
+		domain.IntervalDay,
+	}
+}
+
+// ListCoins implements coinservice.CoinService.
+// 빗썸 시세 API는 이름과 경고 정보를 주지 않으므로 코인 ID만 채운다.
+func (s *Service) ListCoins(ctx context.Context) ([]*domain.Coin, error) {
+	now := time.Now()
+	var ret []*domain.Coin
+	for _, quote := range s.quotes {
+		tickers, err := s.bithumb.ListTickers(ctx, string(quote))
+		if err != nil {
+			return nil, err
+		}
+		for name := range tickers {
+			coinID := domain.NewCoinID(domain.ExchangeBithumb, name+"_"+string(quote))
+			ret = append(ret, domain.NewCoin(coinID, now))
+		}
+	}
+	return ret, nil
+}
+
+// ListTrades implements coinservice.CoinService.
+func (s *Service) ListTrades(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+) (*domain.Trades, error) {
+	const enoughSize = 20
+	return s.ListTradesBefore(ctx, coinID, interval, time.Time{}, enoughSize)
+}
+
+// ListTradesBefore implements coinservice.CoinService.
+// 빗썸은 기준 시각을 받지 않으므로 받을 수 있는 캔들을 모두 받아 걸러낸다.
+// 과거 캔들을 채울 때는 to를 옮겨 가며 여러 번 부르므로, 최근에 받은 캔들이 있으면 다시 받지 않는다.
+func (s *Service) ListTradesBefore(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+	to time.Time,
+	count int,
+) (*domain.Trades, error) {
+	chartInterval, err := chartInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	candles, err := s.listCandles(ctx, coinID.Market(), chartInterval, !to.IsZero())
+	if err != nil {
+		return nil, err
+	}
+	var trades []*domain.Trade
+	var prevClosingPrice string
+	for _, candle := range candles {
+		if !to.IsZero() && !candle.Date.Before(to) {
+			break
+		}
+		trade, err := toTrade(candle, prevClosingPrice)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+		prevClosingPrice = candle.ClosingPrice
+	}
+	if len(trades) > count {
+		trades = trades[len(trades)-count:]
+	}
+	return domain.NewTrades(coinID, interval, now, trades), nil
+}
+
+// listCandles cached가 참이면 seriesTTL 안에 받은 캔들을 다시 쓴다. 새로 받은 캔들은 항상 저장한다.
+func (s *Service) listCandles(
+	ctx context.Context,
+	market string,
+	chartInterval string,
+	cached bool,
+) ([]*Candle, error) {
+	key := market + "/" + chartInterval
+	now := time.Now()
+	s.seriesMu.Lock()
+	found, ok := s.series[key]
+	s.seriesMu.Unlock()
+	if cached && ok && now.Sub(found.fetchedAt) < seriesTTL {
+		return found.candles, nil
+	}
+	candles, err := s.bithumb.ListCandles(ctx, market, chartInterval)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Date.Before(candles[j].Date)
+	})
+	s.seriesMu.Lock()
+	defer s.seriesMu.Unlock()
+	for other, series := range s.series {
+		if now.Sub(series.fetchedAt) >= seriesTTL {
+			delete(s.series, other)
+		}
+	}
+	s.series[key] = &series{candles: candles, fetchedAt: now}
+	return candles, nil
+}
+
+// toTrade 빗썸 캔들에는 전일 종가가 없으므로 직전 캔들의 종가로 변화량을 계산한다.
+func toTrade(candle *Candle, prevClosingPrice string) (*domain.Trade, error) {
+	ret := domain.NewTrade(
+		candle.Date,
+		domain.Price(candle.ClosingPrice),
+		domain.Price(candle.OpeningPrice),
+		domain.Price(candle.HighPrice),
+		domain.Price(candle.LowPrice),
+	).SetVolume(domain.Volume(candle.Volume), "")
+	if prevClosingPrice == "" {
+		return ret, nil
+	}
+	prev, err := strconv.ParseFloat(prevClosingPrice, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	closing, err := strconv.ParseFloat(candle.ClosingPrice, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	change := closing - prev
+	var rate float64
+	if prev != 0 {
+		rate = change / prev
+	}
+	return ret.SetChange(
+		domain.Price(prevClosingPrice),
+		domain.Price(formatFloat(change)),
+		domain.Rate(formatFloat(rate)),
+	), nil
+}
+
+func chartInterval(interval domain.Interval) (string, error) {
+	switch interval { //nolint:exhaustive
+	case domain.IntervalMinute1, domain.IntervalMinute3, domain.IntervalMinute5, domain.IntervalMinute30:
+		return string(interval), nil
+	case domain.IntervalMinute60:
+		return "1h", nil
+	case domain.IntervalDay:
+		return "24h", nil
+	default:
+		return "", errors.Wrap(ErrUnsupportedInterval, string(interval))
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
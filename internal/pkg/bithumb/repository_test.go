@@ -0,0 +1,138 @@
+package bithumb_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bithumb"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer 빗썸 공개 API 응답을 흉내낸다. candles는 캔들 요청 수를 센다.
+func newServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var candles atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/public/ticker/ALL_KRW", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"0000","data":{
+			"BTC":{"opening_price":"50000000","closing_price":"51000000","min_price":"49000000","max_price":"52000000"},
+			"XRP":{"opening_price":"700","closing_price":"710","min_price":"690","max_price":"720"},
+			"date":"1715299200000"}}`))
+	})
+	mux.HandleFunc("/public/ticker/ALL_USDT", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"5500","message":"Invalid Parameter"}`))
+	})
+	mux.HandleFunc("/public/candlestick/BTC_KRW/24h", func(w http.ResponseWriter, _ *http.Request) {
+		candles.Add(1)
+		_, _ = w.Write([]byte(`{"status":"0000","data":[
+			[1715126400000,"100","110","120","90","1.5"],
+			[1715212800000,"110","121","130","100","2"],
+			[1715299200000,"121","100","125","95","3"]]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &candles
+}
+
+func TestService_ListCoins(t *testing.T) {
+	t.Parallel()
+	server, _ := newServer(t)
+	service := bithumb.NewService(bithumb.WithBaseURL(server.URL))
+
+	coins, err := service.ListCoins(context.Background())
+
+	require.NoError(t, err)
+	var coinIDs []domain.CoinID
+	for _, coin := range coins {
+		require.Equal(t, domain.QuoteKRW, coin.Quote())
+		coinIDs = append(coinIDs, coin.ID())
+	}
+	require.ElementsMatch(t, []domain.CoinID{"bithumb:BTC_KRW", "bithumb:XRP_KRW"}, coinIDs)
+}
+
+func TestService_ListCoinsBadStatus(t *testing.T) {
+	t.Parallel()
+	server, _ := newServer(t)
+	service := bithumb.NewService(bithumb.WithBaseURL(server.URL), bithumb.WithQuotes(domain.QuoteUSDT))
+
+	_, err := service.ListCoins(context.Background())
+
+	require.ErrorIs(t, err, bithumb.ErrBadStatus)
+}
+
+func TestService_ListTradesBefore(t *testing.T) {
+	t.Parallel()
+	server, _ := newServer(t)
+	service := bithumb.NewService(bithumb.WithBaseURL(server.URL))
+	to := time.UnixMilli(1715299200000)
+
+	trades, err := service.ListTradesBefore(context.Background(), "bithumb:BTC_KRW", domain.IntervalDay, to, 20)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, trades.Size())
+	require.Equal(t, domain.Price("121"), trades.LastPrice())
+	latest := trades.Trades()[len(trades.Trades())-1]
+	require.Equal(t, domain.Price("11"), latest.ChangePrice())
+	require.Equal(t, domain.Rate("0.1"), latest.ChangeRate())
+}
+
+func TestService_ListTradesUnsupportedInterval(t *testing.T) {
+	t.Parallel()
+	service := bithumb.NewService()
+
+	_, err := service.ListTrades(context.Background(), "bithumb:BTC_KRW", domain.IntervalWeek)
+
+	require.ErrorIs(t, err, bithumb.ErrUnsupportedInterval)
+}
+
+func TestService_Intervals(t *testing.T) {
+	t.Parallel()
+	service := bithumb.NewService()
+
+	for _, interval := range domain.Intervals() {
+		if slices.Contains(service.Intervals(), interval) {
+			continue
+		}
+		_, err := service.ListTrades(context.Background(), "bithumb:BTC_KRW", interval)
+		require.ErrorIs(t, err, bithumb.ErrUnsupportedInterval, interval)
+	}
+}
+
+func TestService_ListTradesBeforeReusesCandlesWhilePaging(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, requests := newServer(t)
+	service := bithumb.NewService(bithumb.WithBaseURL(server.URL))
+
+	first, err := service.ListTradesBefore(ctx, "bithumb:BTC_KRW", domain.IntervalDay, time.UnixMilli(1715299200001), 1)
+	require.NoError(t, err)
+	second, err := service.ListTradesBefore(ctx, "bithumb:BTC_KRW", domain.IntervalDay, first.OldestDate(), 1)
+	require.NoError(t, err)
+	_, err = service.ListTrades(ctx, "bithumb:BTC_KRW", domain.IntervalDay)
+	require.NoError(t, err)
+
+	require.Equal(t, domain.Price("100"), first.LastPrice())
+	require.Equal(t, domain.Price("121"), second.LastPrice())
+	require.Equal(t, int64(2), requests.Load())
+}
+
+func TestService_PacesRequests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server, _ := newServer(t)
+	service := bithumb.NewService(bithumb.WithBaseURL(server.URL), bithumb.WithRequestsPerSecond(10))
+	start := time.Now()
+
+	for range 3 {
+		_, err := service.ListCoins(ctx)
+		require.NoError(t, err)
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
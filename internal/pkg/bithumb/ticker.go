@@ -0,0 +1,12 @@
+package bithumb
+
+// Ticker 빗썸은 숫자를 모두 문자열로 준다.
+type Ticker struct {
+	OpeningPrice     string `json:"opening_price"`
+	ClosingPrice     string `json:"closing_price"`
+	MinPrice         string `json:"min_price"`
+	MaxPrice         string `json:"max_price"`
+	UnitsTraded      string `json:"units_traded"`
+	AccTradeValue    string `json:"acc_trade_value"`
+	PrevClosingPrice string `json:"prev_closing_price"`
+}
@@ -8,5 +8,11 @@ import (
 
 type Bus interface {
 	Publish(ctx context.Context, event domain.Event)
-	Subscribe(ctx context.Context, topic string, handler func(ctx context.Context, event domain.Event) error)
+	// Subscribe 구독하지 못하면 오류를 반환한다. 구독 없이 시작하면 이벤트를 놓치므로 시작을 멈춰야 한다.
+	Subscribe(
+		ctx context.Context,
+		topic string,
+		handler func(ctx context.Context, event domain.Event) error,
+		opts ...SubscribeOption,
+	) error
 }
@@ -0,0 +1,46 @@
+package bus
+
+import (
+	"context"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/google/uuid"
+)
+
+// Seal event를 봉투에 담는다. ctx의 span을 trace context로 남겨 핸들러의 span이 발행한 span에 이어지게 한다.
+// 이미 봉투에 담긴 이벤트는 그대로 둔다. ID가 있는 이벤트는 그 ID를 쓴다.
+func Seal(ctx context.Context, tracer tracer.Tracer, producer string, event domain.Event) *domain.Envelope {
+	if envelope, ok := event.(*domain.Envelope); ok {
+		return envelope
+	}
+	id := uuid.Must(uuid.NewV7()).String()
+	if identified, ok := event.(domain.IdentifiedEvent); ok && identified.EventID() != "" {
+		id = identified.EventID()
+	}
+	return domain.NewEnvelope(
+		id,
+		event.Topic(),
+		domain.EventVersion(event),
+		time.Now(),
+		producer,
+		tracer.Inject(ctx),
+		event.Payload(),
+	)
+}
+
+// StartHandling 핸들러의 span을 시작한다. 발행한 span과는 link로 이어진다.
+func StartHandling(
+	ctx context.Context,
+	tracer tracer.Tracer,
+	subscription string,
+	envelope *domain.Envelope,
+) (context.Context, tracer.Span) {
+	ctx, span := tracer.StartLinked(ctx, "bus.handle "+envelope.Topic(), envelope.TraceContext())
+	span.String("subscription", subscription)
+	span.String("event.id", envelope.EventID())
+	span.String("event.producer", envelope.Producer())
+	span.Int64("event.version", int64(envelope.Version()))
+	return ctx, span
+}
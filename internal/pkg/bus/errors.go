@@ -3,6 +3,8 @@ package bus
 import (
 	"fmt"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 type RetryAfterError struct {
@@ -20,3 +22,8 @@ func (e *RetryAfterError) Error() string {
 func (e *RetryAfterError) Duration() time.Duration {
 	return e.duration
 }
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrClosed 닫힌 버스에는 발행할 수 없다.
+var ErrClosed = errors.New("bus closed")
@@ -0,0 +1,37 @@
+package bus
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy 핸들러가 실패했을 때 다시 시도하는 방법.
+// RetryAfterError를 반환하면 backoff 대신 그 시간만큼 기다리지만 시도 횟수에는 포함된다.
+type RetryPolicy struct {
+	MaxAttempts    int           // 처음 시도를 포함한 최대 시도 횟수
+	InitialBackoff time.Duration // 첫 실패 뒤 기다리는 시간. 실패할 때마다 두 배가 된다.
+	MaxBackoff     time.Duration
+	Jitter         float64 // 0.2이면 기다리는 시간을 ±20% 흔든다.
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5, //nolint:mnd
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Jitter:         0.2, //nolint:mnd
+	}
+}
+
+// Backoff attempt번째 실패 뒤 기다릴 시간.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.MaxBackoff)
+	if p.Jitter > 0 {
+		backoff = time.Duration(float64(backoff) * (1 + p.Jitter*(rand.Float64()*2-1))) //nolint:gosec,mnd
+	}
+	return max(backoff, 0)
+}
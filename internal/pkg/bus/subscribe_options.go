@@ -0,0 +1,39 @@
+package bus
+
+type SubscribeOptions struct {
+	// Name 구독을 구분하는 이름. 실패한 이벤트를 이 구독에만 다시 전달할 때 쓴다.
+	Name        string
+	RetryPolicy RetryPolicy
+	// FanOut 같은 이름의 구독이 여러 프로세스에 있어도 나눠 받지 않고 프로세스마다 모든 이벤트를 받는다.
+	FanOut bool
+}
+
+func NewSubscribeOptions() *SubscribeOptions {
+	return &SubscribeOptions{
+		Name:        "",
+		RetryPolicy: DefaultRetryPolicy(),
+		FanOut:      false,
+	}
+}
+
+type SubscribeOption func(*SubscribeOptions)
+
+func WithName(name string) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Name = name
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithFanOut 프로세스의 메모리 상태를 갱신하는 구독처럼 프로세스마다 모든 이벤트를 받아야 할 때 쓴다.
+// 프로세스가 멈춘 동안 발행된 이벤트는 받지 않는다.
+func WithFanOut() SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.FanOut = true
+	}
+}
@@ -2,11 +2,15 @@ package local
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -14,43 +18,336 @@ var _ bus.Bus = (*Bus)(nil)
 
 type EventHandler func(ctx context.Context, event domain.Event) error
 
+// Bus 한 프로세스 안에서 이벤트를 전달한다.
+// 비동기 모드에서는 구독자마다 큐와 worker를 두어 느린 핸들러가 발행자를 막지 않는다.
 type Bus struct {
-	logger   *zap.Logger
-	handlers map[string][]EventHandler
+	logger  *zap.Logger
+	options *Options
+
+	mu          sync.RWMutex
+	subscribers map[string][]*subscriber
+
+	pendingMu   sync.Mutex
+	pendingCond *sync.Cond
+	pending     int // 큐에 넣었지만 아직 처리를 마치지 않은 이벤트 수
+	closed      bool
+
+	closing     chan struct{} // Close를 시작하면 닫혀 재시도 대기를 끝낸다.
+	closingOnce sync.Once
+}
+
+type subscriber struct {
+	name        string
+	handler     EventHandler
+	retryPolicy bus.RetryPolicy
+	lanes       []chan delivery // 같은 키의 이벤트는 항상 같은 lane으로 간다.
+	done        sync.WaitGroup
 }
 
-func NewBus(logger *zap.Logger) *Bus {
-	return &Bus{
-		logger:   logger,
-		handlers: make(map[string][]EventHandler),
+type delivery struct {
+	ctx   context.Context //nolint:containedctx
+	event *domain.Envelope
+	done  *sync.WaitGroup // PublishConfirmed가 처리를 기다린다. nil이면 기다리는 발행자가 없다.
+}
+
+func NewBus(logger *zap.Logger, opts ...Option) *Bus {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
 	}
+	ret := &Bus{
+		logger:      logger,
+		options:     options,
+		mu:          sync.RWMutex{},
+		subscribers: make(map[string][]*subscriber),
+		pendingMu:   sync.Mutex{},
+		pendingCond: nil,
+		pending:     0,
+		closed:      false,
+		closing:     make(chan struct{}),
+		closingOnce: sync.Once{},
+	}
+	ret.pendingCond = sync.NewCond(&ret.pendingMu)
+	return ret
+}
+
+func (b *Bus) async() bool {
+	return b.options.QueueSize > 0
 }
 
 // Publish implements bus.Bus.
+// 봉투에 담기지 않은 이벤트는 ctx의 span과 함께 봉투에 담아 전달한다.
 func (b *Bus) Publish(ctx context.Context, event domain.Event) {
 	b.logger.Info("publish event", zap.Any("event", event.Topic()))
-	for _, handler := range b.handlers[event.Topic()] {
-		b.handle(ctx, handler, event)
+	b.mu.RLock()
+	subscribers := b.subscribers[event.Topic()]
+	b.mu.RUnlock()
+	b.deliver(ctx, subscribers, bus.Seal(ctx, b.options.Tracer, b.options.Producer, event), nil)
+}
+
+// PublishConfirmed 모든 구독자가 처리를 마치면 반환한다. 재시도를 모두 실패해 dead letter로 남긴 이벤트도 처리한 것으로 본다.
+// 닫힌 버스에는 발행하지 못하고 bus.ErrClosed를 반환한다.
+func (b *Bus) PublishConfirmed(ctx context.Context, event domain.Event) error {
+	b.mu.RLock()
+	subscribers := b.subscribers[event.Topic()]
+	b.mu.RUnlock()
+	var done sync.WaitGroup
+	if !b.deliver(ctx, subscribers, bus.Seal(ctx, b.options.Tracer, b.options.Producer, event), &done) {
+		return errors.WithStack(bus.ErrClosed)
+	}
+	handled := make(chan struct{})
+	go func() {
+		done.Wait()
+		close(handled)
+	}()
+	select {
+	case <-handled:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
 	}
 }
 
-func (b *Bus) handle(ctx context.Context, handler EventHandler, event domain.Event) {
+// Redeliver subscription이라는 이름의 구독에만 event를 다시 전달한다.
+func (b *Bus) Redeliver(ctx context.Context, subscription string, event domain.Event) error {
+	b.mu.RLock()
+	var found *subscriber
+	for _, subscriber := range b.subscribers[event.Topic()] {
+		if subscriber.name == subscription {
+			found = subscriber
+		}
+	}
+	b.mu.RUnlock()
+	if found == nil {
+		return errors.Wrap(bus.ErrSubscriptionNotFound, subscription)
+	}
+	b.deliver(ctx, []*subscriber{found}, bus.Seal(ctx, b.options.Tracer, b.options.Producer, event), nil)
+	return nil
+}
+
+// deliver 버스가 닫혀 이벤트를 버렸다면 false를 반환한다. done이 있으면 구독자마다 처리를 마칠 때 Done한다.
+func (b *Bus) deliver(
+	ctx context.Context,
+	subscribers []*subscriber,
+	event *domain.Envelope,
+	done *sync.WaitGroup,
+) bool {
+	if !b.async() {
+		if b.isClosed() {
+			b.logger.Warn("bus is closed, drop event", zap.String("topic", event.Topic()))
+			return false
+		}
+		for _, subscriber := range subscribers {
+			b.handle(ctx, subscriber, event)
+		}
+		return true
+	}
+	if !b.acquire(len(subscribers)) {
+		b.logger.Warn("bus is closed, drop event", zap.String("topic", event.Topic()))
+		return false
+	}
+	lane := b.lane(event)
+	// 발행자의 취소가 이미 받은 이벤트의 처리를 멈추지 않게 한다.
+	ctx = context.WithoutCancel(ctx)
+	for _, subscriber := range subscribers {
+		if done != nil {
+			done.Add(1)
+		}
+		subscriber.lanes[lane] <- delivery{ctx: ctx, event: event, done: done}
+	}
+	return true
+}
+
+// acquire 닫히지 않았다면 count개의 이벤트를 처리 중으로 센다.
+func (b *Bus) acquire(count int) bool {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.pending += count
+	return true
+}
+
+func (b *Bus) isClosed() bool {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	return b.closed
+}
+
+func (b *Bus) release() {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	b.pending--
+	if b.pending == 0 {
+		b.pendingCond.Broadcast()
+	}
+}
+
+func (b *Bus) lane(event domain.Event) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(b.options.OrderingKey(event)))
+	return int(hash.Sum32() % uint32(b.options.Workers)) //nolint:gosec
+}
+
+// handle 구독의 재시도 정책대로 처리한다. 끝내 실패하거나 재시도를 기다리는 중에 취소되면 dead letter로 남긴다.
+func (b *Bus) handle(ctx context.Context, subscriber *subscriber, event *domain.Envelope) {
+	policy := subscriber.retryPolicy
+	var attempts []*domain.DeliveryAttempt
 	for {
-		err := handler(ctx, event)
+		handleCtx, span := bus.StartHandling(ctx, b.options.Tracer, subscriber.name, event)
+		err := subscriber.handler(handleCtx, event)
+		span.Error(err)
+		span.End()
 		if err == nil {
 			return
 		}
+		attempts = append(attempts, domain.NewDeliveryAttempt(time.Now(), err.Error()))
+		if len(attempts) >= policy.MaxAttempts {
+			b.deadLetter(ctx, subscriber, event, attempts)
+			return
+		}
+		backoff := policy.Backoff(len(attempts))
 		var retryAfterError *bus.RetryAfterError
 		if errors.As(err, &retryAfterError) {
-			b.logger.Info("retry after", zap.Duration("duration", retryAfterError.Duration()))
-			time.Sleep(retryAfterError.Duration())
-			continue
+			backoff = retryAfterError.Duration()
+		}
+		b.logger.Info(
+			"retry event",
+			zap.String("subscription", subscriber.name),
+			zap.Int("attempt", len(attempts)),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		if !b.wait(ctx, backoff) {
+			b.deadLetter(context.WithoutCancel(ctx), subscriber, event, attempts)
+			return
 		}
-		b.logger.Error("error handling event", zap.Error(err))
+	}
+}
+
+// wait backoff만큼 기다린다. 그 전에 ctx가 취소되거나 버스가 닫히기 시작하면 false를 반환한다.
+func (b *Bus) wait(ctx context.Context, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-b.closing:
+		return false
+	}
+}
+
+func (b *Bus) deadLetter(
+	ctx context.Context,
+	subscriber *subscriber,
+	event *domain.Envelope,
+	attempts []*domain.DeliveryAttempt,
+) {
+	b.logger.Error(
+		"error handling event",
+		zap.String("subscription", subscriber.name),
+		zap.String("topic", event.Topic()),
+		zap.String("error", attempts[len(attempts)-1].Error()),
+	)
+	if b.options.DeadLetters == nil {
 		return
 	}
+	deadLetter := domain.NewDeadLetter(
+		uuid.Must(uuid.NewV7()).String(),
+		subscriber.name,
+		event.EventID(),
+		event.Topic(),
+		event.Payload(),
+		attempts,
+		time.Now(),
+	)
+	err := b.options.DeadLetters.CreateDeadLetter(ctx, deadLetter)
+	if err != nil {
+		b.logger.Error("failed to save dead letter", zap.Error(err))
+	}
+}
+
+// Subscribe implements bus.Bus. 비동기 모드에서는 구독자의 worker를 띄운다.
+// 이름을 주지 않으면 토픽과 순번으로 이름을 짓는다. 한 프로세스 안의 버스이므로 모든 구독이 모든 이벤트를 받는다.
+func (b *Bus) Subscribe(
+	_ context.Context,
+	topic string,
+	handler func(ctx context.Context, event domain.Event) error,
+	opts ...bus.SubscribeOption,
+) error {
+	options := bus.NewSubscribeOptions()
+	options.RetryPolicy = b.options.RetryPolicy
+	for _, opt := range opts {
+		opt(options)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.isClosed() {
+		return errors.WithStack(bus.ErrClosed)
+	}
+	name := options.Name
+	if name == "" {
+		name = fmt.Sprintf("%v#%v", topic, len(b.subscribers[topic]))
+	}
+	subscriber := &subscriber{
+		name:        name,
+		handler:     handler,
+		retryPolicy: options.RetryPolicy,
+		lanes:       nil,
+		done:        sync.WaitGroup{},
+	}
+	if b.async() {
+		for range b.options.Workers {
+			lane := make(chan delivery, b.options.QueueSize)
+			subscriber.lanes = append(subscriber.lanes, lane)
+			subscriber.done.Add(1)
+			go b.work(subscriber, lane)
+		}
+	}
+	b.subscribers[topic] = append(b.subscribers[topic], subscriber)
+	return nil
+}
+
+func (b *Bus) work(subscriber *subscriber, lane <-chan delivery) {
+	defer subscriber.done.Done()
+	for delivery := range lane {
+		b.handle(delivery.ctx, subscriber, delivery.event)
+		if delivery.done != nil {
+			delivery.done.Done()
+		}
+		b.release()
+	}
 }
 
-func (b *Bus) Subscribe(_ context.Context, topic string, handler func(ctx context.Context, event domain.Event) error) {
-	b.handlers[topic] = append(b.handlers[topic], handler)
+// Close 쌓인 이벤트를 모두 처리할 때까지 기다린 뒤 worker를 멈춘다.
+// 처리 중인 핸들러가 발행한 이벤트도 처리한다. 닫힌 뒤 발행한 이벤트는 버린다.
+// 재시도를 기다리던 이벤트는 더 기다리지 않고 dead letter로 남긴다.
+func (b *Bus) Close() {
+	b.closingOnce.Do(func() {
+		close(b.closing)
+	})
+	b.pendingMu.Lock()
+	for b.pending > 0 && !b.closed {
+		b.pendingCond.Wait()
+	}
+	if b.closed {
+		b.pendingMu.Unlock()
+		return
+	}
+	b.closed = true
+	b.pendingMu.Unlock()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, subscribers := range b.subscribers {
+		for _, subscriber := range subscribers {
+			for _, lane := range subscriber.lanes {
+				close(lane)
+			}
+			subscriber.done.Wait()
+		}
+	}
 }
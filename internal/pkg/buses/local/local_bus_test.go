@@ -0,0 +1,213 @@
+package local_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/buses/local"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBus_AsyncPublishDoesNotWaitForRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	b := local.NewBus(zap.NewNop(), local.WithAsync(16, 1))
+	var attempts atomic.Int32
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(context.Context, domain.Event) error {
+		if attempts.Add(1) == 1 {
+			return bus.NewRetryAfterError(200 * time.Millisecond)
+		}
+		return nil
+	}))
+
+	start := time.Now()
+	b.Publish(ctx, domain.NewCoinCreatedEvent(start, "upbit:KRW-A"))
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	require.Eventually(t, func() bool { return attempts.Load() == 2 }, time.Second, 10*time.Millisecond)
+	b.Close()
+}
+
+func TestBus_KeepsTopicOrderAndDrainsOnClose(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	b := local.NewBus(zap.NewNop(), local.WithAsync(4, 4))
+	var mu sync.Mutex
+	var received []domain.CoinID
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, event domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		coinCreated, err := domain.ParseCoinCreatedEvent(event.Payload())
+		if err != nil {
+			return err
+		}
+		received = append(received, coinCreated.CoinID)
+		return nil
+	}))
+
+	var published []domain.CoinID
+	var wg sync.WaitGroup
+	for i := range 100 {
+		coinID := domain.NewCoinID(domain.ExchangeUpbit, "KRW-"+string(rune('A'+i%26))+string(rune('A'+i/26)))
+		published = append(published, coinID)
+		b.Publish(ctx, domain.NewCoinCreatedEvent(time.Now(), coinID))
+		// 발행하는 동안 구독해도 안전하다.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := b.Subscribe(ctx, domain.CoinDeletedEventTopic, func(context.Context, domain.Event) error { return nil })
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	b.Close()
+
+	require.Equal(t, published, received)
+	b.Publish(ctx, domain.NewCoinCreatedEvent(time.Now(), "upbit:KRW-LATE"))
+	require.Len(t, received, 100)
+}
+
+func TestBus_DeadLettersExhaustedEventAndRedelivers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	b := local.NewBus(zap.NewNop(), local.WithDeadLetters(repo))
+	var failing atomic.Bool
+	failing.Store(true)
+	var handled []domain.Event
+	policy := bus.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Jitter: 0}
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, event domain.Event) error {
+		if failing.Load() {
+			return errors.New("boom")
+		}
+		handled = append(handled, event)
+		return nil
+	}, bus.WithName("flaky"), bus.WithRetryPolicy(policy)))
+	event := domain.NewEnvelope("event-1", domain.CoinCreatedEventTopic, 1, time.Now(), "", nil, []byte(`{}`))
+
+	b.Publish(ctx, event)
+
+	deadLetters, err := repo.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, "flaky", deadLetters[0].Subscription())
+	require.Equal(t, "event-1", deadLetters[0].EventID())
+	require.Len(t, deadLetters[0].Attempts(), 3)
+	require.Equal(t, "boom", deadLetters[0].Attempts()[2].Error())
+
+	failing.Store(false)
+	err = b.Redeliver(ctx, "flaky", deadLetters[0])
+	require.NoError(t, err)
+	require.Len(t, handled, 1)
+	require.Equal(t, "event-1", handled[0].(domain.IdentifiedEvent).EventID()) //nolint:forcetypeassert
+	require.ErrorIs(t, b.Redeliver(ctx, "missing", deadLetters[0]), bus.ErrSubscriptionNotFound)
+}
+
+func TestBus_HandlerReceivesEnvelope(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	b := local.NewBus(zap.NewNop(), local.WithTracer(noop.NewTracer(), "miner:upbit"))
+	var received []*domain.Envelope
+	require.NoError(t, b.Subscribe(ctx, domain.CoinUpdatedEventTopic, func(_ context.Context, event domain.Event) error {
+		envelope, ok := event.(*domain.Envelope)
+		if !ok {
+			return errors.New("not an envelope")
+		}
+		received = append(received, envelope)
+		return nil
+	}))
+
+	b.Publish(ctx, domain.NewCoinUpdatedEvent(time.Now(), "upbit:KRW-A"))
+
+	require.Len(t, received, 1)
+	require.NotEmpty(t, received[0].EventID())
+	require.Equal(t, 2, received[0].Version())
+	require.Equal(t, "miner:upbit", received[0].Producer())
+	_, err := domain.ParseCoinUpdatedEvent([]byte(`{"coin_id":`))
+	require.ErrorIs(t, err, domain.ErrInvalidPayload)
+}
+
+func TestBus_PublishConfirmedWaitsForAsyncSubscribers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	b := local.NewBus(zap.NewNop(), local.WithAsync(16, 1))
+	var handled atomic.Int32
+	for range 2 {
+		require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(context.Context, domain.Event) error {
+			time.Sleep(50 * time.Millisecond)
+			handled.Add(1)
+			return nil
+		}))
+	}
+
+	err := b.PublishConfirmed(ctx, domain.NewCoinCreatedEvent(time.Now(), "upbit:KRW-A"))
+
+	require.NoError(t, err)
+	require.Equal(t, int32(2), handled.Load())
+	b.Close()
+	err = b.PublishConfirmed(ctx, domain.NewCoinCreatedEvent(time.Now(), "upbit:KRW-B"))
+	require.ErrorIs(t, err, bus.ErrClosed)
+}
+
+func TestBus_CancelledRetryIsDeadLettered(t *testing.T) {
+	t.Parallel()
+	repo := realrepositorytest.New(t)
+	b := local.NewBus(zap.NewNop(), local.WithDeadLetters(repo))
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := bus.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour, Jitter: 0}
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(context.Context, domain.Event) error {
+		cancel()
+		return errors.New("boom")
+	}, bus.WithName("flaky"), bus.WithRetryPolicy(policy)))
+
+	b.Publish(ctx, domain.NewEnvelope("event-1", domain.CoinCreatedEventTopic, 1, time.Now(), "", nil, []byte(`{}`)))
+
+	deadLetters, err := repo.ListDeadLetters(context.Background())
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Len(t, deadLetters[0].Attempts(), 1)
+}
+
+func TestBus_CloseStopsWaitingForRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	b := local.NewBus(zap.NewNop(), local.WithAsync(16, 1), local.WithDeadLetters(repo))
+	policy := bus.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour, Jitter: 0}
+	var attempts atomic.Int32
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(context.Context, domain.Event) error {
+		attempts.Add(1)
+		return errors.New("boom")
+	}, bus.WithName("flaky"), bus.WithRetryPolicy(policy)))
+	b.Publish(ctx, domain.NewEnvelope("event-1", domain.CoinCreatedEventTopic, 1, time.Now(), "", nil, []byte(`{}`)))
+	require.Eventually(t, func() bool { return attempts.Load() == 1 }, time.Second, time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		b.Close()
+		close(closed)
+	}()
+
+	require.Eventually(t, func() bool {
+		select {
+		case <-closed:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	deadLetters, err := repo.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, int32(1), attempts.Load())
+}
@@ -0,0 +1,81 @@
+package local
+
+import (
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+)
+
+type Options struct {
+	// QueueSize 구독자마다 쌓아둘 수 있는 이벤트 수. 0이면 Publish가 핸들러를 직접 실행한다.
+	QueueSize int
+	// Workers 구독자마다 이벤트를 처리하는 고루틴 수.
+	Workers int
+	// OrderingKey 같은 키의 이벤트는 한 구독자 안에서 발행한 순서대로 처리된다.
+	OrderingKey func(event domain.Event) string
+	// DeadLetters 재시도를 모두 실패한 이벤트를 남길 곳. nil이면 로그만 남기고 버린다.
+	DeadLetters coinrepository.CreateDeadLetterCommand
+	// RetryPolicy 구독할 때 정하지 않으면 쓰는 재시도 정책.
+	RetryPolicy bus.RetryPolicy
+	// Tracer 핸들러마다 발행한 span에 이어진 span을 남긴다.
+	Tracer tracer.Tracer
+	// Producer 봉투에 담기지 않은 이벤트를 발행할 때 남기는 발행자.
+	Producer string
+}
+
+// NewOptions 기본은 동기 모드이다.
+func NewOptions() *Options {
+	return &Options{
+		QueueSize:   0,
+		Workers:     1,
+		OrderingKey: TopicOrderingKey,
+		DeadLetters: nil,
+		RetryPolicy: bus.DefaultRetryPolicy(),
+		Tracer:      noop.NewTracer(),
+		Producer:    "",
+	}
+}
+
+type Option func(*Options)
+
+// WithAsync 구독자마다 queueSize 크기의 큐와 workers개의 고루틴을 두고 비동기로 처리한다.
+// 큐가 가득 차면 Publish는 자리가 날 때까지 기다린다.
+func WithAsync(queueSize int, workers int) Option {
+	return func(o *Options) {
+		o.QueueSize = max(queueSize, 1)
+		o.Workers = max(workers, 1)
+	}
+}
+
+// WithOrderingKey 순서를 보장할 단위를 바꾼다. 키가 다른 이벤트는 여러 worker가 나눠 처리한다.
+func WithOrderingKey(orderingKey func(event domain.Event) string) Option {
+	return func(o *Options) {
+		o.OrderingKey = orderingKey
+	}
+}
+
+// TopicOrderingKey 한 토픽의 이벤트를 모두 발행한 순서대로 처리한다.
+func TopicOrderingKey(event domain.Event) string {
+	return event.Topic()
+}
+
+func WithDeadLetters(deadLetters coinrepository.CreateDeadLetterCommand) Option {
+	return func(o *Options) {
+		o.DeadLetters = deadLetters
+	}
+}
+
+func WithRetryPolicy(policy bus.RetryPolicy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = policy
+	}
+}
+
+func WithTracer(tracer tracer.Tracer, producer string) Option {
+	return func(o *Options) {
+		o.Tracer = tracer
+		o.Producer = producer
+	}
+}
@@ -0,0 +1,382 @@
+// Package nats JetStream으로 프로세스 사이에 이벤트를 전달한다.
+package nats
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/google/uuid"
+	natsclient "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var _ bus.Bus = (*Bus)(nil)
+
+const (
+	// subscriptionHeader Redeliver로 보낸 메시지를 받을 구독. 다른 구독은 받자마자 ack한다.
+	subscriptionHeader = "Coin-Cache-Subscription"
+	// 봉투의 정보는 헤더로 옮긴다. trace context는 W3C 헤더 이름(traceparent, tracestate) 그대로 담는다.
+	eventIDHeader     = "Coin-Cache-Event-Id"
+	versionHeader     = "Coin-Cache-Event-Version"
+	occurredAtHeader  = "Coin-Cache-Occurred-At"
+	producerHeader    = "Coin-Cache-Producer"
+	traceParentHeader = "traceparent"
+	traceStateHeader  = "tracestate"
+)
+
+// Bus 구독마다 durable consumer를 만들어, 프로세스가 다시 시작해도 처리하지 못한 이벤트를 이어서 받는다.
+// 같은 이름으로 여러 프로세스가 구독하면 이벤트를 나눠 처리한다. FanOut 구독은 나누지 않는다.
+type Bus struct {
+	logger  *zap.Logger
+	options *Options
+	conn    *natsclient.Conn
+	js      jetstream.JetStream
+
+	mu       sync.Mutex
+	consumes []jetstream.ConsumeContext
+}
+
+// NewBus url의 NATS에 연결하고 스트림이 없으면 만든다.
+func NewBus(ctx context.Context, logger *zap.Logger, url string, opts ...Option) (*Bus, error) {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	conn, err := natsclient.Connect(url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{ //nolint:exhaustruct
+		Name:      options.Stream,
+		Subjects:  []string{options.SubjectPrefix + ".>"},
+		Retention: jetstream.LimitsPolicy,
+		Discard:   jetstream.DiscardOld,
+		MaxAge:    options.MaxAge,
+		MaxBytes:  options.MaxBytes,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	return &Bus{
+		logger:   logger,
+		options:  options,
+		conn:     conn,
+		js:       js,
+		mu:       sync.Mutex{},
+		consumes: nil,
+	}, nil
+}
+
+func (b *Bus) subject(topic string) string {
+	return b.options.SubjectPrefix + "." + topic
+}
+
+// Publish implements bus.Bus. 봉투의 ID로 JetStream이 중복 발행을 걸러낸다.
+func (b *Bus) Publish(ctx context.Context, event domain.Event) {
+	err := b.publish(ctx, bus.Seal(ctx, b.options.Tracer, b.options.Producer, event), "")
+	if err != nil {
+		b.logger.Error("failed to publish event", zap.String("topic", event.Topic()), zap.Error(err))
+	}
+}
+
+// PublishConfirmed JetStream이 이벤트를 저장했다고 ack하면 반환한다.
+func (b *Bus) PublishConfirmed(ctx context.Context, event domain.Event) error {
+	return b.publish(ctx, bus.Seal(ctx, b.options.Tracer, b.options.Producer, event), "")
+}
+
+// Redeliver subscription이라는 이름의 구독에만 event를 다시 전달한다.
+func (b *Bus) Redeliver(ctx context.Context, subscription string, event domain.Event) error {
+	return b.publish(ctx, bus.Seal(ctx, b.options.Tracer, b.options.Producer, event), subscription)
+}
+
+func (b *Bus) publish(ctx context.Context, envelope *domain.Envelope, subscription string) error {
+	msg := natsclient.NewMsg(b.subject(envelope.Topic()))
+	msg.Data = envelope.Payload()
+	msg.Header.Set(eventIDHeader, envelope.EventID())
+	msg.Header.Set(versionHeader, strconv.Itoa(envelope.Version()))
+	msg.Header.Set(occurredAtHeader, envelope.OccurredAt().Format(time.RFC3339Nano))
+	msg.Header.Set(producerHeader, envelope.Producer())
+	for key, value := range envelope.TraceContext() {
+		msg.Header.Set(key, value)
+	}
+	var opts []jetstream.PublishOpt
+	if subscription == "" {
+		opts = append(opts, jetstream.WithMsgID(envelope.EventID()))
+	} else {
+		msg.Header.Set(subscriptionHeader, subscription)
+	}
+	_, err := b.js.PublishMsg(ctx, msg, opts...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Subscribe implements bus.Bus.
+// 이름을 주지 않으면 토픽으로 이름을 지으므로, 이름 없는 구독끼리는 이벤트를 나눠 받는다.
+// 처음 만드는 durable consumer는 스트림에 쌓인 과거 이벤트를 다시 처리하지 않도록 구독한 뒤의 이벤트부터 받는다.
+// FanOut 구독은 프로세스마다 ephemeral consumer를 만들어 구독한 뒤에 발행된 이벤트를 모두 받는다.
+func (b *Bus) Subscribe(
+	ctx context.Context,
+	topic string,
+	handler func(ctx context.Context, event domain.Event) error,
+	opts ...bus.SubscribeOption,
+) error {
+	options := bus.NewSubscribeOptions()
+	options.RetryPolicy = b.options.RetryPolicy
+	for _, opt := range opts {
+		opt(options)
+	}
+	name := options.Name
+	if name == "" {
+		name = topic
+	}
+	config := jetstream.ConsumerConfig{ //nolint:exhaustruct
+		Durable:       durableName(name, topic),
+		FilterSubject: b.subject(topic),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       b.options.AckWait,
+		MaxDeliver:    max(options.RetryPolicy.MaxAttempts, 1),
+	}
+	if options.FanOut {
+		config.Durable = ""
+		config.DeliverPolicy = jetstream.DeliverNewPolicy
+		config.InactiveThreshold = b.options.InactiveThreshold
+	} else {
+		deliverPolicy, err := b.deliverPolicy(ctx, config.Durable)
+		if err != nil {
+			return errors.Wrapf(err, "subscribe %v to %v", name, topic)
+		}
+		config.DeliverPolicy = deliverPolicy
+	}
+	err := b.subscribe(ctx, topic, handler, name, options.RetryPolicy, config)
+	if err != nil {
+		return errors.Wrapf(err, "subscribe %v to %v", name, topic)
+	}
+	return nil
+}
+
+// deliverPolicy 이미 있는 durable consumer는 바꿀 수 없으므로 그대로 두고, 새로 만들 때만 DeliverNew를 쓴다.
+func (b *Bus) deliverPolicy(ctx context.Context, durable string) (jetstream.DeliverPolicy, error) {
+	consumer, err := b.js.Consumer(ctx, b.options.Stream, durable)
+	if errors.Is(err, jetstream.ErrConsumerNotFound) {
+		return jetstream.DeliverNewPolicy, nil
+	}
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return consumer.CachedInfo().Config.DeliverPolicy, nil
+}
+
+func (b *Bus) subscribe(
+	ctx context.Context,
+	topic string,
+	handler func(ctx context.Context, event domain.Event) error,
+	name string,
+	policy bus.RetryPolicy,
+	config jetstream.ConsumerConfig,
+) error {
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.options.Stream, config)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	subscription := &subscription{
+		bus:        b,
+		ctx:        context.WithoutCancel(ctx),
+		name:       name,
+		topic:      topic,
+		handler:    handler,
+		policy:     policy,
+		attemptsMu: sync.Mutex{},
+		attempts:   map[uint64][]*domain.DeliveryAttempt{},
+	}
+	consume, err := consumer.Consume(subscription.handle)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consumes = append(b.consumes, consume)
+	return nil
+}
+
+// durableName durable 이름에는 '.'을 쓸 수 없다.
+func durableName(name string, topic string) string {
+	replacer := strings.NewReplacer(".", "_", ":", "_", "*", "_", ">", "_", " ", "_")
+	return replacer.Replace(name + "__" + topic)
+}
+
+// Close 구독을 멈추고 연결을 닫는다. ack하지 못한 이벤트는 다음 구독자가 받는다.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	for _, consume := range b.consumes {
+		consume.Stop()
+	}
+	b.consumes = nil
+	b.mu.Unlock()
+	err := b.conn.Drain()
+	if err != nil {
+		b.logger.Error("failed to drain connection", zap.Error(err))
+	}
+}
+
+type subscription struct {
+	bus     *Bus
+	ctx     context.Context //nolint:containedctx
+	name    string
+	topic   string
+	handler func(ctx context.Context, event domain.Event) error
+	policy  bus.RetryPolicy
+
+	// attempts 이 프로세스에서 실패한 시도를 스트림 순번별로 모아 dead letter에 남긴다.
+	attemptsMu sync.Mutex
+	attempts   map[uint64][]*domain.DeliveryAttempt
+}
+
+// handle 성공하면 ack, 실패하면 backoff만큼 늦춰 nak한다. 마지막 시도까지 실패하면 dead letter로 남기고 term한다.
+func (s *subscription) handle(msg jetstream.Msg) {
+	logger := s.bus.logger.With(zap.String("subscription", s.name), zap.String("topic", s.topic))
+	if target := msg.Headers().Get(subscriptionHeader); target != "" && target != s.name {
+		s.ack(logger, msg.Ack())
+		return
+	}
+	metadata, err := msg.Metadata()
+	if err != nil {
+		logger.Error("failed to read metadata", zap.Error(err))
+		s.ack(logger, msg.Term())
+		return
+	}
+	event := openEnvelope(s.topic, msg)
+	ctx, span := bus.StartHandling(s.ctx, s.bus.options.Tracer, s.name, event)
+	err = s.handler(ctx, event)
+	span.Int64("attempt", int64(metadata.NumDelivered)) //nolint:gosec
+	span.Error(err)
+	span.End()
+	sequence := metadata.Sequence.Stream
+	if err == nil {
+		s.forgetAttempts(sequence)
+		s.ack(logger, msg.Ack())
+		return
+	}
+	attempts := s.recordAttempt(sequence, err)
+	attempt := int(metadata.NumDelivered) //nolint:gosec
+	if attempt >= s.policy.MaxAttempts {
+		s.forgetAttempts(sequence)
+		s.deadLetter(logger, event, withUnknownAttempts(attempts, attempt))
+		s.ack(logger, msg.Term())
+		return
+	}
+	backoff := s.policy.Backoff(attempt)
+	var retryAfterError *bus.RetryAfterError
+	if errors.As(err, &retryAfterError) {
+		backoff = retryAfterError.Duration()
+	}
+	logger.Info("retry event", zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+	s.ack(logger, msg.NakWithDelay(backoff))
+}
+
+func (s *subscription) ack(logger *zap.Logger, err error) {
+	if err != nil {
+		logger.Error("failed to acknowledge event", zap.Error(err))
+	}
+}
+
+// recordAttempt 실패한 시도를 남기고 지금까지의 시도를 반환한다.
+// 다른 프로세스로 넘어가 끝난 이벤트의 기록은 남아 있을 수 있으므로, 재시도가 모두 끝났을 시간이 지난 기록은 지운다.
+func (s *subscription) recordAttempt(sequence uint64, err error) []*domain.DeliveryAttempt {
+	s.attemptsMu.Lock()
+	defer s.attemptsMu.Unlock()
+	now := time.Now()
+	expiry := time.Duration(s.policy.MaxAttempts) * (s.policy.MaxBackoff + s.bus.options.AckWait)
+	for other, attempts := range s.attempts {
+		if now.Sub(attempts[len(attempts)-1].AttemptedAt()) > expiry {
+			delete(s.attempts, other)
+		}
+	}
+	s.attempts[sequence] = append(s.attempts[sequence], domain.NewDeliveryAttempt(now, err.Error()))
+	return slices.Clone(s.attempts[sequence])
+}
+
+func (s *subscription) forgetAttempts(sequence uint64) {
+	s.attemptsMu.Lock()
+	defer s.attemptsMu.Unlock()
+	delete(s.attempts, sequence)
+}
+
+// unknownAttemptError 다른 프로세스에서 실패했거나 AckWait 안에 끝내지 못해 기록이 없는 시도.
+const unknownAttemptError = "no record in this process (handled elsewhere or timed out)"
+
+// withUnknownAttempts 전달된 횟수만큼 시도가 남도록 기록이 없는 시도를 앞에 채운다.
+func withUnknownAttempts(attempts []*domain.DeliveryAttempt, delivered int) []*domain.DeliveryAttempt {
+	var ret []*domain.DeliveryAttempt
+	for range delivered - len(attempts) {
+		ret = append(ret, domain.NewDeliveryAttempt(time.Time{}, unknownAttemptError))
+	}
+	return append(ret, attempts...)
+}
+
+func (s *subscription) deadLetter(logger *zap.Logger, event *domain.Envelope, attempts []*domain.DeliveryAttempt) {
+	logger.Error(
+		"error handling event",
+		zap.Int("attempts", len(attempts)),
+		zap.String("error", attempts[len(attempts)-1].Error()),
+	)
+	if s.bus.options.DeadLetters == nil {
+		return
+	}
+	deadLetter := domain.NewDeadLetter(
+		uuid.Must(uuid.NewV7()).String(),
+		s.name,
+		event.EventID(),
+		event.Topic(),
+		event.Payload(),
+		attempts,
+		time.Now(),
+	)
+	err := s.bus.options.DeadLetters.CreateDeadLetter(s.ctx, deadLetter)
+	if err != nil {
+		logger.Error("failed to save dead letter", zap.Error(err))
+	}
+}
+
+// openEnvelope 헤더에서 봉투를 복원한다. 헤더가 없거나 잘못된 값이면 기본값을 쓴다.
+func openEnvelope(topic string, msg jetstream.Msg) *domain.Envelope {
+	headers := msg.Headers()
+	version, err := strconv.Atoi(headers.Get(versionHeader))
+	if err != nil {
+		version = 1
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, headers.Get(occurredAtHeader))
+	if err != nil {
+		occurredAt = time.Time{}
+	}
+	traceContext := map[string]string{}
+	for _, key := range []string{traceParentHeader, traceStateHeader} {
+		if value := headers.Get(key); value != "" {
+			traceContext[key] = value
+		}
+	}
+	return domain.NewEnvelope(
+		headers.Get(eventIDHeader),
+		topic,
+		version,
+		occurredAt,
+		headers.Get(producerHeader),
+		traceContext,
+		msg.Data(),
+	)
+}
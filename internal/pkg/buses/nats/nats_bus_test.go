@@ -0,0 +1,276 @@
+package nats_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	natsbus "github.com/biosvos/coin-cache-service/internal/pkg/buses/nats"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
+	"github.com/nats-io/nats-server/v2/server"
+	natsclient "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startServer 테스트마다 JetStream을 켠 nats-server를 띄운다.
+func startServer(t *testing.T) string {
+	t.Helper()
+	s, err := server.NewServer(&server.Options{ //nolint:exhaustruct
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go s.Start()
+	t.Cleanup(s.Shutdown)
+	require.True(t, s.ReadyForConnections(5*time.Second))
+	return s.ClientURL()
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (r *recorder) add(event domain.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.events)
+}
+
+func TestBus_RedeliversFailedAndRetryAfterEvents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	policy := bus.RetryPolicy{MaxAttempts: 5, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0}
+	b, err := natsbus.NewBus(ctx, zap.NewNop(), startServer(t), natsbus.WithRetryPolicy(policy))
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+	var received recorder
+	var attempts int
+	var retriedAt time.Time
+	var waited time.Duration
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, event domain.Event) error {
+		attempts++
+		switch attempts {
+		case 1:
+			return errors.New("boom") // nak
+		case 2:
+			retriedAt = time.Now()
+			return bus.NewRetryAfterError(300 * time.Millisecond)
+		}
+		waited = time.Since(retriedAt)
+		received.add(event)
+		return nil
+	}, bus.WithName("trader")))
+
+	b.Publish(ctx, domain.NewEnvelope(
+		"event-1", domain.CoinCreatedEventTopic, 1, time.Now(), "", nil, []byte(`{"coin_id":"upbit:KRW-A"}`),
+	))
+
+	require.Eventually(t, func() bool { return received.len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 3, attempts)
+	require.GreaterOrEqual(t, waited, 300*time.Millisecond)
+	event, ok := received.events[0].(*domain.Envelope)
+	require.True(t, ok)
+	require.Equal(t, "event-1", event.EventID())
+	coinCreated, err := domain.ParseCoinCreatedEvent(event.Payload())
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-A"), coinCreated.CoinID)
+}
+
+func TestBus_DeadLetterKeepsEveryAttempt(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := realrepositorytest.New(t)
+	policy := bus.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0}
+	b, err := natsbus.NewBus(
+		ctx, zap.NewNop(), startServer(t), natsbus.WithRetryPolicy(policy), natsbus.WithDeadLetters(repo),
+	)
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+	var attempts atomic.Int32
+	require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(context.Context, domain.Event) error {
+		return errors.Errorf("boom %v", attempts.Add(1))
+	}, bus.WithName("flaky")))
+
+	require.NoError(t, b.PublishConfirmed(ctx, domain.NewCoinCreatedEvent(time.Now(), "upbit:KRW-A")))
+
+	var deadLetters []*domain.DeadLetter
+	require.Eventually(t, func() bool {
+		deadLetters, err = repo.ListDeadLetters(ctx)
+		return err == nil && len(deadLetters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	var errs []string
+	for _, attempt := range deadLetters[0].Attempts() {
+		errs = append(errs, attempt.Error())
+	}
+	require.Equal(t, []string{"boom 1", "boom 2", "boom 3"}, errs)
+}
+
+func TestBus_DurableConsumerResumesAfterRestart(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	url := startServer(t)
+	first, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	var received recorder
+	handler := func(_ context.Context, event domain.Event) error {
+		received.add(event)
+		return nil
+	}
+	require.NoError(t, first.Subscribe(ctx, domain.CoinDeletedEventTopic, handler, bus.WithName("prohibitor")))
+	first.Close() // 구독자가 멈춘 사이에 발행한다.
+	publisher, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(publisher.Close)
+	publisher.Publish(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-A"))
+	// 같은 ID는 한 번만 저장된다.
+	duplicated := domain.NewEnvelope("event-2", domain.CoinDeletedEventTopic, 1, time.Now(), "", nil, []byte(`{}`))
+	publisher.Publish(ctx, duplicated)
+	publisher.Publish(ctx, duplicated)
+
+	second, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(second.Close)
+	require.NoError(t, second.Subscribe(ctx, domain.CoinDeletedEventTopic, handler, bus.WithName("prohibitor")))
+
+	require.Eventually(t, func() bool { return received.len() == 2 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 2, received.len())
+}
+
+func TestBus_PublishConfirmedFailsWithoutStream(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	url := startServer(t)
+	b, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+	conn, err := natsclient.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	stored := b.PublishConfirmed(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-A"))
+	require.NoError(t, js.DeleteStream(ctx, "COIN_EVENTS"))
+	lost := b.PublishConfirmed(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-B"))
+
+	require.NoError(t, stored)
+	require.Error(t, lost)
+}
+
+func TestBus_SubscribeFailsWithoutStream(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	url := startServer(t)
+	b, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+	conn, err := natsclient.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	require.NoError(t, js.DeleteStream(ctx, "COIN_EVENTS"))
+
+	err = b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(context.Context, domain.Event) error {
+		return nil
+	}, bus.WithName("prohibitor"))
+
+	require.Error(t, err)
+}
+
+func TestBus_FanOutDeliversToEveryProcess(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	url := startServer(t)
+	recorders := make([]*recorder, 2)
+	for i := range recorders {
+		b, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+		require.NoError(t, err)
+		t.Cleanup(b.Close)
+		received := &recorder{}
+		recorders[i] = received
+		require.NoError(t, b.Subscribe(ctx, domain.CoinCreatedEventTopic, func(_ context.Context, event domain.Event) error {
+			received.add(event)
+			return nil
+		}, bus.WithName("broadcaster"), bus.WithFanOut()))
+	}
+	publisher, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(publisher.Close)
+
+	for _, coinID := range []domain.CoinID{"upbit:KRW-A", "upbit:KRW-B"} {
+		require.NoError(t, publisher.PublishConfirmed(ctx, domain.NewCoinCreatedEvent(time.Now(), coinID)))
+	}
+
+	for _, received := range recorders {
+		require.Eventually(t, func() bool { return received.len() == 2 }, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestBus_NewDurableConsumerSkipsRetainedEvents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	url := startServer(t)
+	publisher, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(publisher.Close)
+	require.NoError(t, publisher.PublishConfirmed(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-OLD")))
+	b, err := natsbus.NewBus(ctx, zap.NewNop(), url)
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+	var received recorder
+
+	err = b.Subscribe(ctx, domain.CoinDeletedEventTopic, func(_ context.Context, event domain.Event) error {
+		received.add(event)
+		return nil
+	}, bus.WithName("prohibitor"))
+	require.NoError(t, publisher.PublishConfirmed(ctx, domain.NewCoinDeletedEvent(time.Now(), "upbit:KRW-NEW")))
+
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return received.len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 1, received.len())
+	coinDeleted, err := domain.ParseCoinDeletedEvent(received.events[0].Payload())
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-NEW"), coinDeleted.CoinID)
+}
+
+func TestBus_StreamRetention(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	url := startServer(t)
+	b, err := natsbus.NewBus(ctx, zap.NewNop(), url, natsbus.WithRetention(time.Hour, 1<<20))
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+	conn, err := natsclient.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	stream, err := js.Stream(ctx, "COIN_EVENTS")
+
+	require.NoError(t, err)
+	config := stream.CachedInfo().Config
+	require.Equal(t, jetstream.LimitsPolicy, config.Retention)
+	require.Equal(t, time.Hour, config.MaxAge)
+	require.Equal(t, int64(1<<20), config.MaxBytes)
+}
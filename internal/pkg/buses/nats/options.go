@@ -0,0 +1,96 @@
+package nats
+
+import (
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/bus"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+)
+
+type Options struct {
+	// Stream 이벤트를 담는 JetStream 스트림 이름.
+	Stream string
+	// SubjectPrefix 토픽 앞에 붙여 subject를 만든다. e.g. coin-events.coin.created
+	SubjectPrefix string
+	// MaxAge 스트림이 이벤트를 보관하는 기간. 0이면 기간으로 지우지 않는다.
+	MaxAge time.Duration
+	// MaxBytes 스트림의 최대 크기. 넘치면 오래된 이벤트부터 지운다. -1이면 크기로 지우지 않는다.
+	MaxBytes int64
+	// AckWait 핸들러가 이 시간 안에 끝내지 못하면 다시 전달된다.
+	AckWait time.Duration
+	// InactiveThreshold FanOut 구독의 consumer는 프로세스가 이 시간 동안 받아가지 않으면 지워진다.
+	InactiveThreshold time.Duration
+	// DeadLetters 재시도를 모두 실패한 이벤트를 남길 곳. nil이면 로그만 남기고 버린다.
+	DeadLetters coinrepository.CreateDeadLetterCommand
+	// RetryPolicy 구독할 때 정하지 않으면 쓰는 재시도 정책.
+	RetryPolicy bus.RetryPolicy
+	// Tracer 핸들러마다 발행한 span에 이어진 span을 남긴다.
+	Tracer tracer.Tracer
+	// Producer 봉투에 담기지 않은 이벤트를 발행할 때 남기는 발행자.
+	Producer string
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Stream:            "COIN_EVENTS",
+		SubjectPrefix:     "coin-events",
+		MaxAge:            7 * 24 * time.Hour,
+		MaxBytes:          -1,
+		AckWait:           time.Minute,
+		InactiveThreshold: time.Minute,
+		DeadLetters:       nil,
+		RetryPolicy:       bus.DefaultRetryPolicy(),
+		Tracer:            noop.NewTracer(),
+		Producer:          "",
+	}
+}
+
+type Option func(*Options)
+
+func WithStream(stream string, subjectPrefix string) Option {
+	return func(o *Options) {
+		o.Stream = stream
+		o.SubjectPrefix = subjectPrefix
+	}
+}
+
+// WithRetention 스트림이 보관할 기간과 크기를 정한다. 이미 있는 스트림도 이 설정으로 바꾼다.
+func WithRetention(maxAge time.Duration, maxBytes int64) Option {
+	return func(o *Options) {
+		o.MaxAge = maxAge
+		o.MaxBytes = maxBytes
+	}
+}
+
+func WithAckWait(ackWait time.Duration) Option {
+	return func(o *Options) {
+		o.AckWait = ackWait
+	}
+}
+
+func WithInactiveThreshold(inactiveThreshold time.Duration) Option {
+	return func(o *Options) {
+		o.InactiveThreshold = inactiveThreshold
+	}
+}
+
+func WithDeadLetters(deadLetters coinrepository.CreateDeadLetterCommand) Option {
+	return func(o *Options) {
+		o.DeadLetters = deadLetters
+	}
+}
+
+func WithRetryPolicy(policy bus.RetryPolicy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = policy
+	}
+}
+
+func WithTracer(tracer tracer.Tracer, producer string) Option {
+	return func(o *Options) {
+		o.Tracer = tracer
+		o.Producer = producer
+	}
+}
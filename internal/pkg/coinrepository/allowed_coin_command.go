@@ -0,0 +1,20 @@
+package coinrepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type AllowedCoinCommand interface {
+	CreateAllowedCoinCommand
+	DeleteAllowedCoinCommand
+}
+
+type CreateAllowedCoinCommand interface {
+	CreateAllowedCoin(ctx context.Context, allowedCoin *domain.AllowedCoin) (*domain.AllowedCoin, error)
+}
+
+type DeleteAllowedCoinCommand interface {
+	DeleteAllowedCoin(ctx context.Context, allowedCoin *domain.AllowedCoin) error
+}
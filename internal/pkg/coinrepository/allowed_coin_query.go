@@ -0,0 +1,20 @@
+package coinrepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type AllowedCoinQuery interface {
+	ListAllowedCoinsQuery
+	GetAllowedCoinQuery
+}
+
+type ListAllowedCoinsQuery interface {
+	ListAllowedCoins(ctx context.Context) ([]*domain.AllowedCoin, error)
+}
+
+type GetAllowedCoinQuery interface {
+	GetAllowedCoin(ctx context.Context, coinID domain.CoinID) (*domain.AllowedCoin, error)
+}
@@ -11,10 +11,15 @@ type BannedCoinCommand interface {
 	DeleteBannedCoinCommand
 }
 
+// CreateBannedCoinCommand events는 금지 코인과 함께 outbox에 기록된다.
 type CreateBannedCoinCommand interface {
-	CreateBannedCoin(ctx context.Context, bannedCoin *domain.BannedCoin) (*domain.BannedCoin, error)
+	CreateBannedCoin(
+		ctx context.Context,
+		bannedCoin *domain.BannedCoin,
+		events ...domain.Event,
+	) (*domain.BannedCoin, error)
 }
 
 type DeleteBannedCoinCommand interface {
-	DeleteBannedCoin(ctx context.Context, bannedCoin *domain.BannedCoin) error
+	DeleteBannedCoin(ctx context.Context, bannedCoin *domain.BannedCoin, events ...domain.Event) error
 }
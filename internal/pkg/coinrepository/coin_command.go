@@ -10,16 +10,30 @@ type CoinCommand interface {
 	CreateCoinCommand
 	UpdateCoinCommand
 	DeleteCoinCommand
+	SaveCoinsCommand
 }
 
+// CreateCoinCommand events는 코인과 함께 outbox에 기록된다. 나머지 명령도 같다.
 type CreateCoinCommand interface {
-	CreateCoin(ctx context.Context, coin *domain.Coin) (*domain.Coin, error)
+	CreateCoin(ctx context.Context, coin *domain.Coin, events ...domain.Event) (*domain.Coin, error)
 }
 
 type UpdateCoinCommand interface {
-	UpdateCoin(ctx context.Context, coin *domain.Coin) (*domain.Coin, error)
+	UpdateCoin(ctx context.Context, coin *domain.Coin, events ...domain.Event) (*domain.Coin, error)
 }
 
 type DeleteCoinCommand interface {
-	DeleteCoin(ctx context.Context, coin *domain.Coin) error
+	DeleteCoin(ctx context.Context, coin *domain.Coin, events ...domain.Event) error
+}
+
+// SaveCoinsCommand 여러 코인의 추가, 수정, 삭제를 한 번에 반영한다. 하나라도 실패하면 아무것도 바뀌지 않는다.
+type SaveCoinsCommand interface {
+	SaveCoins(ctx context.Context, changes *CoinChanges, events ...domain.Event) error
+}
+
+// CoinChanges SaveCoins로 반영할 코인들.
+type CoinChanges struct {
+	Created []*domain.Coin
+	Updated []*domain.Coin
+	Deleted []*domain.Coin
 }
@@ -7,6 +7,19 @@ type CoinRepository interface {
 	BannedCoinCommand
 	BannedCoinQuery
 
+	AllowedCoinCommand
+	AllowedCoinQuery
+
 	TradeCommand
 	ListTradesQuery
+	ListTradesInRangeQuery
+
+	EventLogCommand
+	EventLogQuery
+
+	OutboxCommand
+	OutboxQuery
+
+	DeadLetterCommand
+	DeadLetterQuery
 }
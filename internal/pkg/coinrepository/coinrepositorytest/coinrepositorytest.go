@@ -0,0 +1,431 @@
+// Package coinrepositorytest coinrepository.CoinRepository 구현이 모두 지켜야 하는 동작을 시험한다.
+package coinrepositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// Run newRepository가 만든 빈 저장소로 하위 테스트를 하나씩 실행한다. 정리할 것이 있다면 newRepository에서 t.Cleanup으로 등록한다.
+func Run(t *testing.T, newRepository func(t *testing.T) coinrepository.CoinRepository) { //nolint:funlen
+	t.Helper()
+	tests := map[string]func(t *testing.T, repo coinrepository.CoinRepository){
+		"Coin":                       testCoin,
+		"CoinErrors":                 testCoinErrors,
+		"SaveCoins":                  testSaveCoins,
+		"SaveCoinsRollsBackOnUpdate": testSaveCoinsRollsBackOnUpdate,
+		"BannedCoinWithReasons":      testBannedCoinWithReasons,
+		"ManualBannedCoin":           testManualBannedCoin,
+		"AllowedCoin":                testAllowedCoin,
+		"SaveTrades":                 testSaveTrades,
+		"ListTradesInRange":          testListTradesInRange,
+		"DeleteTrades":               testDeleteTrades,
+		"Outbox":                     testOutbox,
+		"DeadLetter":                 testDeadLetter,
+		"EventLogTrims":              testEventLogTrims,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			test(t, newRepository(t))
+		})
+	}
+}
+
+// date 저장소마다 시간대와 monotonic clock을 다르게 다루므로 UTC의 고정된 시각만 쓴다.
+func date(day int) time.Time {
+	return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+func testCoin(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	coin := domain.NewCoin("upbit:KRW-A", date(21)).
+		SetNames("에이", "A coin").
+		SetWarning(true).
+		SetCautions(domain.CautionTradingVolumeSoaring, domain.CautionPriceFluctuations)
+	_, err := repo.CreateCoin(ctx, coin)
+	require.NoError(t, err)
+	_, err = repo.CreateCoin(ctx, domain.NewCoin("bithumb:KRW-B", date(21)))
+	require.NoError(t, err)
+
+	got, err := repo.GetCoin(ctx, "upbit:KRW-A")
+
+	require.NoError(t, err)
+	require.Empty(t, got.Changes(coin))
+	require.True(t, got.IsWarning())
+	require.True(t, got.ModifiedAt().Equal(date(21)))
+	coins, err := repo.ListCoins(ctx)
+	require.NoError(t, err)
+	require.Len(t, coins, 2)
+	updated := domain.NewCoin("upbit:KRW-A", date(22)).SetNames("에이", "A")
+	_, err = repo.UpdateCoin(ctx, updated)
+	require.NoError(t, err)
+	got, err = repo.GetCoin(ctx, "upbit:KRW-A")
+	require.NoError(t, err)
+	require.Empty(t, got.Changes(updated))
+	require.NoError(t, repo.DeleteCoin(ctx, updated))
+	_, err = repo.GetCoin(ctx, "upbit:KRW-A")
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+}
+
+func testCoinErrors(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	coin := domain.NewCoin("upbit:KRW-A", date(21))
+	_, err := repo.CreateCoin(ctx, coin)
+	require.NoError(t, err)
+
+	_, err = repo.CreateCoin(ctx, coin, domain.NewCoinCreatedEvent(date(21), coin.ID()))
+
+	require.ErrorIs(t, err, coinrepository.ErrCoinAlreadyExists)
+	require.ErrorIs(t, err, coinrepository.ErrAlreadyExists)
+	_, err = repo.GetCoin(ctx, "upbit:KRW-NONE")
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	require.ErrorIs(t, err, coinrepository.ErrNotFound)
+	_, err = repo.UpdateCoin(ctx, domain.NewCoin("upbit:KRW-NONE", date(21)))
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	err = repo.DeleteCoin(ctx, domain.NewCoin("upbit:KRW-NONE", date(21)))
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	// 실패한 명령의 이벤트는 기록되지 않는다.
+	events, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func testSaveCoins(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-A", date(21)))
+	require.NoError(t, err)
+	_, err = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-B", date(21)))
+	require.NoError(t, err)
+
+	err = repo.SaveCoins(ctx, &coinrepository.CoinChanges{
+		Created: []*domain.Coin{domain.NewCoin("upbit:KRW-C", date(22))},
+		Updated: []*domain.Coin{domain.NewCoin("upbit:KRW-A", date(22)).SetWarning(true)},
+		Deleted: []*domain.Coin{domain.NewCoin("upbit:KRW-B", date(21))},
+	}, domain.NewCoinCreatedEvent(date(22), "upbit:KRW-C"))
+
+	require.NoError(t, err)
+	coins, err := repo.ListCoins(ctx)
+	require.NoError(t, err)
+	var ids []domain.CoinID
+	for _, coin := range coins {
+		ids = append(ids, coin.ID())
+	}
+	require.ElementsMatch(t, []domain.CoinID{"upbit:KRW-A", "upbit:KRW-C"}, ids)
+	coin, err := repo.GetCoin(ctx, "upbit:KRW-A")
+	require.NoError(t, err)
+	require.True(t, coin.IsWarning())
+	events, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+}
+
+func testSaveCoinsRollsBackOnUpdate(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	_, err := repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-A", date(21)))
+	require.NoError(t, err)
+	_, err = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-B", date(21)))
+	require.NoError(t, err)
+
+	err = repo.SaveCoins(ctx, &coinrepository.CoinChanges{
+		Created: []*domain.Coin{domain.NewCoin("upbit:KRW-C", date(22))},
+		Updated: []*domain.Coin{
+			domain.NewCoin("upbit:KRW-A", date(22)).SetWarning(true),
+			domain.NewCoin("upbit:KRW-NONE", date(22)),
+		},
+		Deleted: []*domain.Coin{domain.NewCoin("upbit:KRW-B", date(21))},
+	}, domain.NewCoinCreatedEvent(date(22), "upbit:KRW-C"))
+
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	_, err = repo.GetCoin(ctx, "upbit:KRW-C")
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	coin, err := repo.GetCoin(ctx, "upbit:KRW-A")
+	require.NoError(t, err)
+	require.False(t, coin.IsWarning())
+	_, err = repo.GetCoin(ctx, "upbit:KRW-B")
+	require.NoError(t, err)
+	events, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func testBannedCoinWithReasons(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	reasons := []domain.BanReason{domain.BanReasonNotEnoughTrades, domain.BanReasonPriceTooLow}
+	bannedCoin := domain.NewBannedCoin("upbit:KRW-A", date(21), time.Hour, reasons)
+	_, err := repo.CreateBannedCoin(ctx, bannedCoin, domain.NewBannedCoinCreatedEvent("upbit:KRW-A"))
+	require.NoError(t, err)
+	_, err = repo.CreateBannedCoin(ctx, domain.NewBannedCoin("upbit:KRW-B", date(21), time.Hour, nil))
+	require.NoError(t, err)
+
+	got, err := repo.GetBannedCoin(ctx, "upbit:KRW-A")
+
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-A"), got.CoinID())
+	require.True(t, got.BannedAt().Equal(date(21)))
+	require.Equal(t, time.Hour, got.Period())
+	require.Equal(t, reasons, got.Reasons())
+	require.False(t, got.IsManual())
+	bannedCoins, err := repo.ListBannedCoins(ctx)
+	require.NoError(t, err)
+	require.Len(t, bannedCoins, 2)
+	_, err = repo.CreateBannedCoin(ctx, bannedCoin)
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinAlreadyExists)
+	require.NoError(t, repo.DeleteBannedCoin(ctx, got, domain.NewBannedCoinDeletedEvent("upbit:KRW-A")))
+	_, err = repo.GetBannedCoin(ctx, "upbit:KRW-A")
+	require.ErrorIs(t, err, coinrepository.ErrBannedCoinNotFound)
+	events, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, domain.BannedCoinCreatedEventTopic, events[0].Topic())
+	require.Equal(t, domain.BannedCoinDeletedEventTopic, events[1].Topic())
+}
+
+func testManualBannedCoin(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	_, err := repo.CreateBannedCoin(ctx, domain.NewManualBannedCoin("upbit:KRW-A", date(21), time.Hour, "maintenance"))
+	require.NoError(t, err)
+
+	got, err := repo.GetBannedCoin(ctx, "upbit:KRW-A")
+
+	require.NoError(t, err)
+	require.True(t, got.IsManual())
+	require.Equal(t, "maintenance", got.Note())
+	require.Equal(t, []domain.BanReason{domain.BanReasonManual}, got.Reasons())
+}
+
+func testAllowedCoin(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	allowedCoin := domain.NewAllowedCoin("upbit:KRW-A", date(21), "listed on purpose")
+	_, err := repo.CreateAllowedCoin(ctx, allowedCoin)
+	require.NoError(t, err)
+	_, err = repo.CreateAllowedCoin(ctx, domain.NewAllowedCoin("bithumb:KRW-B", date(21), ""))
+	require.NoError(t, err)
+
+	got, err := repo.GetAllowedCoin(ctx, "upbit:KRW-A")
+
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-A"), got.CoinID())
+	require.True(t, got.AllowedAt().Equal(date(21)))
+	require.Equal(t, "listed on purpose", got.Note())
+	allowedCoins, err := repo.ListAllowedCoins(ctx)
+	require.NoError(t, err)
+	require.Len(t, allowedCoins, 2)
+	_, err = repo.CreateAllowedCoin(ctx, allowedCoin)
+	require.ErrorIs(t, err, coinrepository.ErrAllowedCoinAlreadyExists)
+	require.NoError(t, repo.DeleteAllowedCoin(ctx, got))
+	_, err = repo.GetAllowedCoin(ctx, "upbit:KRW-A")
+	require.ErrorIs(t, err, coinrepository.ErrAllowedCoinNotFound)
+	err = repo.DeleteAllowedCoin(ctx, got)
+	require.ErrorIs(t, err, coinrepository.ErrAllowedCoinNotFound)
+}
+
+func testSaveTrades(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	_, err := repo.SaveTrades(ctx, domain.NewTrades("upbit:KRW-A", domain.IntervalDay, date(21), []*domain.Trade{
+		domain.NewTrade(date(20), "100", "100", "100", "100"),
+		domain.NewTrade(date(21), "100", "100", "100", "100"),
+	}))
+	require.NoError(t, err)
+
+	changes, err := repo.SaveTrades(ctx, domain.NewTrades("upbit:KRW-A", domain.IntervalDay, date(21), []*domain.Trade{
+		domain.NewTrade(date(20), "100", "100", "100", "100"),
+		domain.NewTrade(date(21), "110", "100", "120", "90").SetVolume("1.5", "165").SetChange("100", "10", "0.1"),
+		domain.NewTrade(date(22), "110", "110", "110", "110"),
+	}))
+
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, domain.TradeChangeUpdated, changes[0].Kind())
+	require.True(t, changes[0].Date().Equal(date(21)))
+	require.Equal(t, domain.TradeChangeCreated, changes[1].Kind())
+	trades, err := repo.ListTrades(ctx, "upbit:KRW-A", domain.IntervalDay)
+	require.NoError(t, err)
+	require.Equal(t, 3, trades.Size())
+	require.Equal(t, domain.Price("110"), trades.LastPrice())
+	require.Equal(t, domain.Volume("1.5"), trades.Trades()[1].Volume())
+	_, err = repo.ListTrades(ctx, "upbit:KRW-A", domain.IntervalWeek)
+	require.ErrorIs(t, err, coinrepository.ErrTradesNotFound)
+}
+
+func testListTradesInRange(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	var candles []*domain.Trade
+	for day := 20; day <= 24; day++ {
+		candles = append(candles, domain.NewTrade(date(day), "100", "100", "100", "100"))
+	}
+	_, err := repo.SaveTrades(ctx, domain.NewTrades("upbit:KRW-A", domain.IntervalDay, date(24), candles))
+	require.NoError(t, err)
+	tests := map[string]struct {
+		tradeRange coinrepository.TradeRange
+		want       []int
+	}{
+		"all":     {tradeRange: coinrepository.TradeRange{}, want: []int{20, 21, 22, 23, 24}},
+		"from":    {tradeRange: coinrepository.TradeRange{From: date(23)}, want: []int{23, 24}},
+		"to":      {tradeRange: coinrepository.TradeRange{To: date(21)}, want: []int{20, 21}},
+		"limit":   {tradeRange: coinrepository.TradeRange{Limit: 2}, want: []int{23, 24}},
+		"between": {tradeRange: coinrepository.TradeRange{From: date(21), To: date(23), Limit: 2}, want: []int{22, 23}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			trades, err := repo.ListTradesInRange(ctx, "upbit:KRW-A", domain.IntervalDay, test.tradeRange)
+
+			require.NoError(t, err)
+			var days []int
+			for _, trade := range trades.Trades() {
+				days = append(days, trade.Date().Day())
+			}
+			require.Equal(t, test.want, days)
+			require.True(t, trades.ModifiedAt().Equal(date(24)))
+		})
+	}
+	_, err = repo.ListTradesInRange(ctx, "upbit:KRW-A", domain.IntervalWeek, coinrepository.TradeRange{})
+	require.ErrorIs(t, err, coinrepository.ErrTradesNotFound)
+}
+
+func testDeleteTrades(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	// 앞부분이 같은 코인의 캔들은 지우지 않는다.
+	for _, coinID := range []domain.CoinID{"upbit:KRW-A", "upbit:KRW-AB"} {
+		for _, interval := range []domain.Interval{domain.IntervalDay, domain.IntervalWeek} {
+			_, err := repo.SaveTrades(ctx, domain.NewTrades(coinID, interval, date(21), []*domain.Trade{
+				domain.NewTrade(date(21), "100", "100", "100", "100"),
+			}))
+			require.NoError(t, err)
+		}
+	}
+
+	intervals, err := repo.DeleteTrades(ctx, "upbit:KRW-A")
+
+	require.NoError(t, err)
+	require.ElementsMatch(t, []domain.Interval{domain.IntervalDay, domain.IntervalWeek}, intervals)
+	for _, interval := range []domain.Interval{domain.IntervalDay, domain.IntervalWeek} {
+		_, err = repo.ListTrades(ctx, "upbit:KRW-A", interval)
+		require.ErrorIs(t, err, coinrepository.ErrTradesNotFound)
+		trades, err := repo.ListTrades(ctx, "upbit:KRW-AB", interval)
+		require.NoError(t, err)
+		require.Equal(t, 1, trades.Size())
+	}
+	intervals, err = repo.DeleteTrades(ctx, "upbit:KRW-A")
+	require.NoError(t, err)
+	require.Empty(t, intervals)
+}
+
+// testOutbox 봉투의 ID는 bus.Seal처럼 시간 순으로 정렬되는 UUIDv7이다.
+func testOutbox(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	id := uuid.Must(uuid.NewV7()).String()
+	envelope := domain.NewEnvelope(
+		id,
+		domain.CoinCreatedEventTopic,
+		2,
+		date(21),
+		"miner",
+		map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		[]byte(`{"coin_id":"upbit:KRW-A"}`),
+	)
+	for i, coinID := range []domain.CoinID{"upbit:KRW-A", "upbit:KRW-B", "upbit:KRW-C"} {
+		var event domain.Event = domain.NewCoinCreatedEvent(date(21), coinID)
+		if i == 0 {
+			event = envelope
+		}
+		_, err := repo.CreateCoin(ctx, domain.NewCoin(coinID, date(21)), event)
+		require.NoError(t, err)
+	}
+
+	events, err := repo.ListOutboxEvents(ctx, 2)
+
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, id, events[0].EventID())
+	require.Equal(t, 2, events[0].Version())
+	require.True(t, events[0].OccurredAt().Equal(date(21)))
+	require.Equal(t, "miner", events[0].Producer())
+	require.Equal(t, envelope.TraceContext(), events[0].TraceContext())
+	require.Equal(t, envelope.Payload(), events[0].Payload())
+	require.NotEmpty(t, events[1].EventID())
+	require.NoError(t, repo.DeleteOutboxEvent(ctx, events[0].EventID()))
+	require.NoError(t, repo.DeleteOutboxEvent(ctx, events[1].EventID()))
+	events, err = repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	coinCreated, err := domain.ParseCoinCreatedEvent(events[0].Payload())
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("upbit:KRW-C"), coinCreated.CoinID)
+}
+
+func testDeadLetter(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	attempts := []*domain.DeliveryAttempt{
+		domain.NewDeliveryAttempt(date(21), "timeout"),
+		domain.NewDeliveryAttempt(date(21).Add(time.Second), "connection refused"),
+	}
+	for i, subscription := range []string{"prohibitor", "trader"} {
+		err := repo.CreateDeadLetter(ctx, domain.NewDeadLetter(
+			fmt.Sprintf("dead-letter-%v", i+1),
+			subscription,
+			fmt.Sprintf("event-%v", i+1),
+			domain.CoinCreatedEventTopic,
+			[]byte(`{"coin_id":"upbit:KRW-A"}`),
+			attempts,
+			date(21).Add(time.Duration(i)*time.Minute),
+		))
+		require.NoError(t, err)
+	}
+
+	got, err := repo.GetDeadLetter(ctx, "dead-letter-1")
+
+	require.NoError(t, err)
+	require.Equal(t, "prohibitor", got.Subscription())
+	require.Equal(t, "event-1", got.EventID())
+	require.Equal(t, domain.CoinCreatedEventTopic, got.Topic())
+	require.JSONEq(t, `{"coin_id":"upbit:KRW-A"}`, string(got.Payload()))
+	require.True(t, got.FailedAt().Equal(date(21)))
+	require.Len(t, got.Attempts(), 2)
+	for i, attempt := range got.Attempts() {
+		require.True(t, attempt.AttemptedAt().Equal(attempts[i].AttemptedAt()))
+		require.Equal(t, attempts[i].Error(), attempt.Error())
+	}
+	deadLetters, err := repo.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	require.Equal(t, "dead-letter-1", deadLetters[0].ID())
+	require.Equal(t, "dead-letter-2", deadLetters[1].ID())
+	err = repo.CreateDeadLetter(ctx, got)
+	require.ErrorIs(t, err, coinrepository.ErrDeadLetterAlreadyExists)
+	require.NoError(t, repo.DeleteDeadLetter(ctx, "dead-letter-1"))
+	_, err = repo.GetDeadLetter(ctx, "dead-letter-1")
+	require.ErrorIs(t, err, coinrepository.ErrDeadLetterNotFound)
+	err = repo.DeleteDeadLetter(ctx, "dead-letter-1")
+	require.ErrorIs(t, err, coinrepository.ErrDeadLetterNotFound)
+}
+
+func testEventLogTrims(t *testing.T, repo coinrepository.CoinRepository) {
+	ctx := context.Background()
+	const overflow = 3
+	for range coinrepository.EventLogSize + overflow {
+		_, err := repo.AppendEvent(ctx, domain.NewCoinCreatedEvent(date(21), "upbit:KRW-A"), date(21))
+		require.NoError(t, err)
+	}
+
+	event, err := repo.AppendEvent(ctx, domain.NewCoinDeletedEvent(date(21), "upbit:KRW-A"), date(22))
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(coinrepository.EventLogSize+overflow+1), event.ID())
+	events, err := repo.ListEventsAfter(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, events, coinrepository.EventLogSize)
+	require.Equal(t, uint64(overflow+2), events[0].ID())
+	events, err = repo.ListEventsAfter(ctx, event.ID()-1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, domain.CoinDeletedEventTopic, events[0].Topic())
+	require.True(t, events[0].RecordedAt().Equal(date(22)))
+}
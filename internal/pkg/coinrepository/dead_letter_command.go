@@ -0,0 +1,20 @@
+package coinrepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type DeadLetterCommand interface {
+	CreateDeadLetterCommand
+	DeleteDeadLetterCommand
+}
+
+type CreateDeadLetterCommand interface {
+	CreateDeadLetter(ctx context.Context, deadLetter *domain.DeadLetter) error
+}
+
+type DeleteDeadLetterCommand interface {
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
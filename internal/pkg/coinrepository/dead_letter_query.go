@@ -0,0 +1,21 @@
+package coinrepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type DeadLetterQuery interface {
+	ListDeadLettersQuery
+	GetDeadLetterQuery
+}
+
+// ListDeadLettersQuery 실패한 순서대로 조회한다.
+type ListDeadLettersQuery interface {
+	ListDeadLetters(ctx context.Context) ([]*domain.DeadLetter, error)
+}
+
+type GetDeadLetterQuery interface {
+	GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error)
+}
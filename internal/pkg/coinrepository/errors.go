@@ -1,9 +1,27 @@
 package coinrepository
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// 저장소는 아래 오류 중 하나를 감싸서 반환한다. 호출하는 쪽은 errors.Is로 종류만 확인하면 된다.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrConflict      = errors.New("conflict")       // 동시에 같은 레코드를 고쳤다. 다시 시도하면 된다.
+	ErrCorrupt       = errors.New("corrupt record") // 저장된 값을 읽을 수 없다.
+)
 
 var (
-	ErrBannedCoinNotFound      = errors.New("banned coin not found")
-	ErrCoinNotFound            = errors.New("coin not found")
-	ErrBannedCoinAlreadyExists = errors.New("banned coin already exists")
+	ErrCoinNotFound             = fmt.Errorf("coin %w", ErrNotFound)
+	ErrCoinAlreadyExists        = fmt.Errorf("coin %w", ErrAlreadyExists)
+	ErrBannedCoinNotFound       = fmt.Errorf("banned coin %w", ErrNotFound)
+	ErrBannedCoinAlreadyExists  = fmt.Errorf("banned coin %w", ErrAlreadyExists)
+	ErrAllowedCoinNotFound      = fmt.Errorf("allowed coin %w", ErrNotFound)
+	ErrAllowedCoinAlreadyExists = fmt.Errorf("allowed coin %w", ErrAlreadyExists)
+	ErrTradesNotFound           = fmt.Errorf("trades %w", ErrNotFound)
+	ErrDeadLetterNotFound       = fmt.Errorf("dead letter %w", ErrNotFound)
+	ErrDeadLetterAlreadyExists  = fmt.Errorf("dead letter %w", ErrAlreadyExists)
 )
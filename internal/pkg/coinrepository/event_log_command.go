@@ -0,0 +1,20 @@
+package coinrepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+// EventLogSize 이벤트 로그는 최근 이벤트를 이만큼만 남긴다.
+const EventLogSize = 1000
+
+type EventLogCommand interface {
+	AppendEventCommand
+}
+
+// AppendEventCommand 이벤트에 다음 번호를 붙여 기록한다. EventLogSize개가 넘으면 가장 오래된 이벤트를 지운다.
+type AppendEventCommand interface {
+	AppendEvent(ctx context.Context, event domain.Event, recordedAt time.Time) (*domain.LoggedEvent, error)
+}
@@ -0,0 +1,16 @@
+package coinrepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type EventLogQuery interface {
+	ListEventsAfterQuery
+}
+
+// ListEventsAfterQuery id 다음에 기록된 이벤트를 순서대로 조회한다. 이미 지워진 이벤트는 빠진다.
+type ListEventsAfterQuery interface {
+	ListEventsAfter(ctx context.Context, id uint64) ([]*domain.LoggedEvent, error)
+}
@@ -0,0 +1,14 @@
+package coinrepository
+
+import (
+	"context"
+)
+
+type OutboxCommand interface {
+	DeleteOutboxEventCommand
+}
+
+// DeleteOutboxEventCommand 발행을 마친 이벤트를 outbox에서 지운다.
+type DeleteOutboxEventCommand interface {
+	DeleteOutboxEvent(ctx context.Context, id string) error
+}
@@ -0,0 +1,16 @@
+package coinrepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type OutboxQuery interface {
+	ListOutboxEventsQuery
+}
+
+// ListOutboxEventsQuery 아직 발행하지 않은 이벤트를 기록한 순서대로 최대 limit개 조회한다.
+type ListOutboxEventsQuery interface {
+	ListOutboxEvents(ctx context.Context, limit int) ([]*domain.Envelope, error)
+}
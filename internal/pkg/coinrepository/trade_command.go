@@ -12,9 +12,11 @@ type TradeCommand interface {
 }
 
 type SaveTradesCommand interface {
-	SaveTrades(ctx context.Context, trades *domain.Trades) error
+	// SaveTrades 바뀐 캔들만 저장하고, 무엇이 바뀌었는지 반환한다.
+	SaveTrades(ctx context.Context, trades *domain.Trades) ([]*domain.TradeChange, error)
 }
 
+// DeleteTradesCommand 코인의 캔들을 단위에 상관없이 모두 지우고, 지운 단위를 반환한다.
 type DeleteTradesCommand interface {
-	DeleteTrades(ctx context.Context, id domain.CoinID) error
+	DeleteTrades(ctx context.Context, id domain.CoinID) ([]domain.Interval, error)
 }
@@ -2,10 +2,29 @@ package coinrepository
 
 import (
 	"context"
+	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
 )
 
 type ListTradesQuery interface {
-	ListTrades(ctx context.Context, id domain.CoinID) (*domain.Trades, error)
+	ListTrades(ctx context.Context, id domain.CoinID, interval domain.Interval) (*domain.Trades, error)
+}
+
+type ListTradesInRangeQuery interface {
+	// ListTradesInRange 범위 안의 캔들만 읽는다. 범위 밖의 캔들은 읽지도 않는다.
+	ListTradesInRange(
+		ctx context.Context,
+		id domain.CoinID,
+		interval domain.Interval,
+		tradeRange TradeRange,
+	) (*domain.Trades, error)
+}
+
+// TradeRange 캔들의 일시가 From 이상 To 이하인 것을 고른다. 0인 값은 제한하지 않는다.
+// Limit을 넘으면 최근 캔들을 남긴다.
+type TradeRange struct {
+	From  time.Time
+	To    time.Time
+	Limit int
 }
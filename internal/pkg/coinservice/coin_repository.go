@@ -1,10 +1,38 @@
 package coinservice
 
 import (
+	"context"
+	"time"
+
 	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
 )
 
 type CoinService interface {
+	ExchangeQuery
+	IntervalsQuery
 	coinrepository.ListCoinsQuery
 	coinrepository.ListTradesQuery
+	ListTradesBeforeQuery
+}
+
+// ExchangeQuery 서비스가 어느 거래소의 코인을 다루는지 알려준다.
+type ExchangeQuery interface {
+	Exchange() domain.Exchange
+}
+
+// IntervalsQuery 거래소가 캔들을 주는 단위. 다른 단위의 캔들은 조회할 수 없다.
+type IntervalsQuery interface {
+	Intervals() []domain.Interval
+}
+
+// ListTradesBeforeQuery to 이전의 캔들을 최대 count개 조회한다. 과거 이력을 채울 때 사용한다.
+type ListTradesBeforeQuery interface {
+	ListTradesBefore(
+		ctx context.Context,
+		id domain.CoinID,
+		interval domain.Interval,
+		to time.Time,
+		count int,
+	) (*domain.Trades, error)
 }
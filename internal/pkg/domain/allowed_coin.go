@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// AllowedCoin 자동 금지 규칙을 적용하지 않는 코인
+type AllowedCoin struct {
+	coinID    CoinID
+	allowedAt time.Time
+	note      string
+}
+
+func NewAllowedCoin(coinID CoinID, allowedAt time.Time, note string) *AllowedCoin {
+	return &AllowedCoin{coinID: coinID, allowedAt: allowedAt, note: note}
+}
+
+func (a *AllowedCoin) CoinID() CoinID {
+	return a.coinID
+}
+
+func (a *AllowedCoin) AllowedAt() time.Time {
+	return a.allowedAt
+}
+
+func (a *AllowedCoin) Note() string {
+	return a.note
+}
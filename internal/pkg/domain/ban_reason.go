@@ -0,0 +1,12 @@
+package domain
+
+// BanReason 코인이 금지된 이유
+type BanReason string
+
+const (
+	BanReasonCaution         BanReason = "caution"           // 유의 종목으로 지정됨
+	BanReasonNotEnoughTrades BanReason = "not_enough_trades" // 거래 일수가 부족함
+	BanReasonPriceTooHigh    BanReason = "price_too_high"    // 가격이 너무 높음
+	BanReasonPriceTooLow     BanReason = "price_too_low"     // 가격이 너무 낮음
+	BanReasonManual          BanReason = "manual"            // 운영자가 직접 금지함
+)
@@ -1,19 +1,41 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type BannedCoin struct {
 	coinID   CoinID
 	bannedAt time.Time
 	period   time.Duration
+	reasons  []BanReason
+	note     string
 }
 
-func NewBannedCoin(coinID CoinID, bannedAt time.Time, period time.Duration) *BannedCoin {
-	return &BannedCoin{coinID: coinID, bannedAt: bannedAt, period: period}
+func NewBannedCoin(coinID CoinID, bannedAt time.Time, period time.Duration, reasons []BanReason) *BannedCoin {
+	return &BannedCoin{coinID: coinID, bannedAt: bannedAt, period: period, reasons: reasons}
+}
+
+// NewManualBannedCoin 운영자가 직접 금지한 코인
+func NewManualBannedCoin(coinID CoinID, bannedAt time.Time, period time.Duration, note string) *BannedCoin {
+	return &BannedCoin{
+		coinID:   coinID,
+		bannedAt: bannedAt,
+		period:   period,
+		reasons:  []BanReason{BanReasonManual},
+		note:     note,
+	}
+}
+
+func (b *BannedCoin) SetNote(note string) *BannedCoin {
+	ret := *b
+	ret.note = note
+	return &ret
 }
 
 func (b *BannedCoin) IsBanOver(now time.Time) bool {
-	return b.bannedAt.Add(b.period).After(now)
+	return !b.ExpiredAt().After(now)
 }
 
 func (b *BannedCoin) CoinID() CoinID {
@@ -31,3 +53,15 @@ func (b *BannedCoin) ExpiredAt() time.Time {
 func (b *BannedCoin) Period() time.Duration {
 	return b.period
 }
+
+func (b *BannedCoin) Reasons() []BanReason {
+	return b.reasons
+}
+
+func (b *BannedCoin) Note() string {
+	return b.note
+}
+
+func (b *BannedCoin) IsManual() bool {
+	return slices.Contains(b.reasons, BanReasonManual)
+}
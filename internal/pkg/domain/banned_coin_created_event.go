@@ -10,13 +10,8 @@ type BannedCoinCreatedEvent struct {
 	CoinID CoinID `json:"coin_id"`
 }
 
-func ParseBannedCoinCreatedEvent(payload []byte) *BannedCoinCreatedEvent {
-	var event BannedCoinCreatedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
-	}
-	return &event
+func ParseBannedCoinCreatedEvent(payload []byte) (*BannedCoinCreatedEvent, error) {
+	return parsePayload[BannedCoinCreatedEvent](BannedCoinCreatedEventTopic, payload)
 }
 
 func NewBannedCoinCreatedEvent(coinID CoinID) *BannedCoinCreatedEvent {
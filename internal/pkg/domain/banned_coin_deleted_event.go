@@ -14,13 +14,8 @@ func NewBannedCoinDeletedEvent(coinID CoinID) *BannedCoinDeletedEvent {
 	return &BannedCoinDeletedEvent{CoinID: coinID}
 }
 
-func ParseBannedCoinDeletedEvent(payload []byte) *BannedCoinDeletedEvent {
-	var event BannedCoinDeletedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
-	}
-	return &event
+func ParseBannedCoinDeletedEvent(payload []byte) (*BannedCoinDeletedEvent, error) {
+	return parsePayload[BannedCoinDeletedEvent](BannedCoinDeletedEventTopic, payload)
 }
 
 func (e *BannedCoinDeletedEvent) Topic() string {
@@ -0,0 +1,12 @@
+package domain
+
+// CautionCategory 거래소가 지정한 주의 종목 사유.
+type CautionCategory string
+
+const (
+	CautionPriceFluctuations            CautionCategory = "price_fluctuations"
+	CautionTradingVolumeSoaring         CautionCategory = "trading_volume_soaring"
+	CautionDepositAmountSoaring         CautionCategory = "deposit_amount_soaring"
+	CautionGlobalPriceDifferences       CautionCategory = "global_price_differences"
+	CautionConcentrationOfSmallAccounts CautionCategory = "concentration_of_small_accounts"
+)
@@ -1,36 +1,127 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type CoinID string
 
+// CoinField 코인 정보 중 바뀔 수 있는 항목.
+type CoinField string
+
+const (
+	CoinFieldKoreanName  CoinField = "korean_name"
+	CoinFieldEnglishName CoinField = "english_name"
+	CoinFieldWarning     CoinField = "warning"
+	CoinFieldCautions    CoinField = "cautions"
+)
+
 type Coin struct {
-	id         CoinID
-	caution    bool
-	modifiedAt time.Time
+	id          CoinID
+	koreanName  string
+	englishName string
+	warning     bool
+	cautions    []CautionCategory
+	modifiedAt  time.Time
+}
+
+func NewCoin(id CoinID, modifiedAt time.Time) *Coin {
+	return &Coin{
+		id:          id,
+		koreanName:  "",
+		englishName: "",
+		warning:     false,
+		cautions:    nil,
+		modifiedAt:  modifiedAt,
+	}
 }
 
 func (c *Coin) SetModifiedAt(now time.Time) *Coin {
-	return NewCoin(c.id, c.caution, now)
+	ret := *c
+	ret.modifiedAt = now
+	return &ret
+}
+
+func (c *Coin) SetNames(koreanName string, englishName string) *Coin {
+	ret := *c
+	ret.koreanName = koreanName
+	ret.englishName = englishName
+	return &ret
 }
 
-func NewCoin(id CoinID, caution bool, modifiedAt time.Time) *Coin {
-	return &Coin{id: id, caution: caution, modifiedAt: modifiedAt}
+// SetWarning 투자 유의 종목 여부를 정한다.
+func (c *Coin) SetWarning(warning bool) *Coin {
+	ret := *c
+	ret.warning = warning
+	return &ret
+}
+
+// SetCautions 주의 종목 사유를 정한다. 순서는 상관없다.
+func (c *Coin) SetCautions(cautions ...CautionCategory) *Coin {
+	ret := *c
+	ret.cautions = slices.Clone(cautions)
+	slices.Sort(ret.cautions)
+	ret.cautions = slices.Compact(ret.cautions)
+	return &ret
 }
 
 func (c *Coin) ID() CoinID {
 	return c.id
 }
 
+func (c *Coin) Quote() Quote {
+	return c.id.Quote()
+}
+
+func (c *Coin) Base() string {
+	return c.id.Base()
+}
+
+func (c *Coin) KoreanName() string {
+	return c.koreanName
+}
+
+func (c *Coin) EnglishName() string {
+	return c.englishName
+}
+
+func (c *Coin) IsWarning() bool {
+	return c.warning
+}
+
+func (c *Coin) Cautions() []CautionCategory {
+	return c.cautions
+}
+
 func (c *Coin) IsOld(now time.Time) bool {
 	const coinPeriod = time.Minute * 10
 	return c.modifiedAt.Add(coinPeriod).Before(now)
 }
 
+// IsDanger 유의 종목이거나 주의 사유가 하나라도 있으면 위험하다.
 func (c *Coin) IsDanger() bool {
-	return c.caution
+	return c.warning || len(c.cautions) > 0
 }
 
 func (c *Coin) ModifiedAt() time.Time {
 	return c.modifiedAt
 }
+
+// Changes other와 다른 항목을 돌려준다. 수정 시각은 비교하지 않는다.
+func (c *Coin) Changes(other *Coin) []CoinField {
+	var ret []CoinField
+	if c.koreanName != other.koreanName {
+		ret = append(ret, CoinFieldKoreanName)
+	}
+	if c.englishName != other.englishName {
+		ret = append(ret, CoinFieldEnglishName)
+	}
+	if c.warning != other.warning {
+		ret = append(ret, CoinFieldWarning)
+	}
+	if !slices.Equal(c.cautions, other.cautions) {
+		ret = append(ret, CoinFieldCautions)
+	}
+	return ret
+}
@@ -14,13 +14,8 @@ type CoinCreatedEvent struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-func ParseCoinCreatedEvent(payload []byte) *CoinCreatedEvent {
-	var event CoinCreatedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
-	}
-	return &event
+func ParseCoinCreatedEvent(payload []byte) (*CoinCreatedEvent, error) {
+	return parsePayload[CoinCreatedEvent](CoinCreatedEventTopic, payload)
 }
 
 func NewCoinCreatedEvent(createdAt time.Time, coinID CoinID) *CoinCreatedEvent {
@@ -18,13 +18,8 @@ func NewCoinDeletedEvent(deletedAt time.Time, coinID CoinID) *CoinDeletedEvent {
 	return &CoinDeletedEvent{DeletedAt: deletedAt, CoinID: coinID}
 }
 
-func ParseCoinDeletedEvent(payload []byte) *CoinDeletedEvent {
-	var event CoinDeletedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
-	}
-	return &event
+func ParseCoinDeletedEvent(payload []byte) (*CoinDeletedEvent, error) {
+	return parsePayload[CoinDeletedEvent](CoinDeletedEventTopic, payload)
 }
 
 func (e *CoinDeletedEvent) Topic() string {
@@ -5,26 +5,28 @@ import (
 	"time"
 )
 
-var _ Event = (*CoinUpdatedEvent)(nil)
+var _ VersionedEvent = (*CoinUpdatedEvent)(nil)
 
 const CoinUpdatedEventTopic = "coin.updated"
 
 type CoinUpdatedEvent struct {
-	CoinID    CoinID    `json:"coin_id"`
-	UpdatedAt time.Time `json:"updated_at"`
+	CoinID    CoinID      `json:"coin_id"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Changes   []CoinField `json:"changes,omitempty"`
 }
 
-func ParseCoinUpdatedEvent(payload []byte) *CoinUpdatedEvent {
-	var event CoinUpdatedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
-	}
-	return &event
+func ParseCoinUpdatedEvent(payload []byte) (*CoinUpdatedEvent, error) {
+	return parsePayload[CoinUpdatedEvent](CoinUpdatedEventTopic, payload)
+}
+
+// NewCoinUpdatedEvent changes가 비어 있으면 정보는 그대로이고 수정 시각만 갱신되었다.
+func NewCoinUpdatedEvent(updatedAt time.Time, coinID CoinID, changes ...CoinField) *CoinUpdatedEvent {
+	return &CoinUpdatedEvent{UpdatedAt: updatedAt, CoinID: coinID, Changes: changes}
 }
 
-func NewCoinUpdatedEvent(updatedAt time.Time, coinID CoinID) *CoinUpdatedEvent {
-	return &CoinUpdatedEvent{UpdatedAt: updatedAt, CoinID: coinID}
+// Version 2부터 바뀐 항목(changes)을 담는다.
+func (e *CoinUpdatedEvent) Version() int {
+	return 2 //nolint:mnd
 }
 
 func (e *CoinUpdatedEvent) Topic() string {
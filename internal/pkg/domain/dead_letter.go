@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+var _ IdentifiedEvent = (*DeadLetter)(nil)
+
+// DeliveryAttempt 핸들러가 이벤트 처리에 실패한 한 번의 기록.
+type DeliveryAttempt struct {
+	attemptedAt time.Time
+	err         string
+}
+
+func NewDeliveryAttempt(attemptedAt time.Time, err string) *DeliveryAttempt {
+	return &DeliveryAttempt{attemptedAt: attemptedAt, err: err}
+}
+
+func (a *DeliveryAttempt) AttemptedAt() time.Time {
+	return a.attemptedAt
+}
+
+func (a *DeliveryAttempt) Error() string {
+	return a.err
+}
+
+// DeadLetter 재시도를 모두 실패한 이벤트. 처리하지 못한 구독에 다시 전달하거나 버릴 수 있다.
+// 원래 이벤트처럼 다시 발행할 수 있도록 Event를 구현한다.
+type DeadLetter struct {
+	id           string
+	subscription string
+	eventID      string // 원래 이벤트에 ID가 없었다면 비어 있다.
+	topic        string
+	payload      []byte
+	attempts     []*DeliveryAttempt
+	failedAt     time.Time
+}
+
+func NewDeadLetter(
+	id string,
+	subscription string,
+	eventID string,
+	topic string,
+	payload []byte,
+	attempts []*DeliveryAttempt,
+	failedAt time.Time,
+) *DeadLetter {
+	return &DeadLetter{
+		id:           id,
+		subscription: subscription,
+		eventID:      eventID,
+		topic:        topic,
+		payload:      payload,
+		attempts:     attempts,
+		failedAt:     failedAt,
+	}
+}
+
+func (d *DeadLetter) ID() string {
+	return d.id
+}
+
+func (d *DeadLetter) Subscription() string {
+	return d.subscription
+}
+
+// EventID 원래 이벤트의 ID. 다시 전달해도 핸들러가 같은 이벤트로 알아볼 수 있다.
+func (d *DeadLetter) EventID() string {
+	return d.eventID
+}
+
+func (d *DeadLetter) Topic() string {
+	return d.topic
+}
+
+func (d *DeadLetter) Payload() []byte {
+	return d.payload
+}
+
+func (d *DeadLetter) Attempts() []*DeliveryAttempt {
+	return d.attempts
+}
+
+func (d *DeadLetter) FailedAt() time.Time {
+	return d.failedAt
+}
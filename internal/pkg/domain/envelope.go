@@ -0,0 +1,85 @@
+package domain
+
+import "time"
+
+var _ IdentifiedEvent = (*Envelope)(nil)
+
+// IdentifiedEvent 고유한 ID가 있는 이벤트. 같은 이벤트가 다시 전달될 수 있으므로 핸들러는 ID로 중복을 거를 수 있다.
+type IdentifiedEvent interface {
+	Event
+	EventID() string
+}
+
+// VersionedEvent payload의 형식이 바뀐 이벤트는 버전을 올린다. 구현하지 않은 이벤트는 1이다.
+type VersionedEvent interface {
+	Event
+	Version() int
+}
+
+// EventVersion event의 payload 버전.
+func EventVersion(event Event) int {
+	if versioned, ok := event.(VersionedEvent); ok {
+		return versioned.Version()
+	}
+	return 1
+}
+
+// Envelope 모든 이벤트에 공통으로 붙는 정보와 payload를 함께 담는다.
+// traceContext는 발행한 쪽의 W3C trace context(traceparent, tracestate)이다.
+type Envelope struct {
+	id           string
+	topic        string
+	version      int
+	occurredAt   time.Time
+	producer     string
+	traceContext map[string]string
+	payload      []byte
+}
+
+func NewEnvelope(
+	id string,
+	topic string,
+	version int,
+	occurredAt time.Time,
+	producer string,
+	traceContext map[string]string,
+	payload []byte,
+) *Envelope {
+	return &Envelope{
+		id:           id,
+		topic:        topic,
+		version:      version,
+		occurredAt:   occurredAt,
+		producer:     producer,
+		traceContext: traceContext,
+		payload:      payload,
+	}
+}
+
+func (e *Envelope) EventID() string {
+	return e.id
+}
+
+func (e *Envelope) Topic() string {
+	return e.topic
+}
+
+func (e *Envelope) Version() int {
+	return e.version
+}
+
+func (e *Envelope) OccurredAt() time.Time {
+	return e.occurredAt
+}
+
+func (e *Envelope) Producer() string {
+	return e.producer
+}
+
+func (e *Envelope) TraceContext() map[string]string {
+	return e.traceContext
+}
+
+func (e *Envelope) Payload() []byte {
+	return e.payload
+}
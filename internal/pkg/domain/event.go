@@ -1,6 +1,24 @@
 package domain
 
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidPayload = errors.New("invalid event payload")
+
 type Event interface {
 	Topic() string
 	Payload() []byte
 }
+
+// parsePayload 잘못된 payload는 ErrInvalidPayload로 감싸 반환한다.
+func parsePayload[T any](topic string, payload []byte) (*T, error) {
+	var ret T
+	err := json.Unmarshal(payload, &ret)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidPayload, "%v: %v", topic, err)
+	}
+	return &ret, nil
+}
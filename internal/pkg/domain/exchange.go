@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Exchange 코인을 거래하는 거래소.
+type Exchange string
+
+const (
+	ExchangeUpbit   Exchange = "upbit"
+	ExchangeBithumb Exchange = "bithumb"
+)
+
+// DefaultExchange 거래소가 붙지 않은 코인 ID는 업비트 마켓으로 본다.
+const DefaultExchange = ExchangeUpbit
+
+var ErrUnknownExchange = errors.New("unknown exchange")
+
+func Exchanges() []Exchange {
+	return []Exchange{ExchangeUpbit, ExchangeBithumb}
+}
+
+func ParseExchange(s string) (Exchange, error) {
+	exchange := Exchange(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Exchanges() {
+		if exchange == known {
+			return exchange, nil
+		}
+	}
+	return "", errors.Wrap(ErrUnknownExchange, s)
+}
+
+const exchangeSeparator = ":"
+
+// NewCoinID 거래소와 마켓으로 코인 ID를 만든다. e.g. upbit:KRW-BTC, bithumb:BTC_KRW
+func NewCoinID(exchange Exchange, market string) CoinID {
+	return CoinID(string(exchange) + exchangeSeparator + market)
+}
+
+// ParseCoinID 거래소가 붙지 않은 s는 exchange의 마켓으로 본다. exchange가 비어 있으면 DefaultExchange를 쓴다.
+func ParseCoinID(s string, exchange Exchange) CoinID {
+	if strings.Contains(s, exchangeSeparator) {
+		return CoinID(s)
+	}
+	if exchange == "" {
+		exchange = DefaultExchange
+	}
+	return NewCoinID(exchange, s)
+}
+
+func (c CoinID) Exchange() Exchange {
+	exchange, _, ok := strings.Cut(string(c), exchangeSeparator)
+	if !ok {
+		return DefaultExchange
+	}
+	return Exchange(exchange)
+}
+
+// Market 거래소 안에서 쓰는 마켓 이름. e.g. KRW-BTC
+func (c CoinID) Market() string {
+	_, market, ok := strings.Cut(string(c), exchangeSeparator)
+	if !ok {
+		return string(c)
+	}
+	return market
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Interval 캔들 하나가 나타내는 기간
+type Interval string
+
+const (
+	IntervalMinute1   Interval = "1m"
+	IntervalMinute3   Interval = "3m"
+	IntervalMinute5   Interval = "5m"
+	IntervalMinute15  Interval = "15m"
+	IntervalMinute30  Interval = "30m"
+	IntervalMinute60  Interval = "60m"
+	IntervalMinute240 Interval = "240m"
+	IntervalDay       Interval = "1d"
+	IntervalWeek      Interval = "1w"
+	IntervalMonth     Interval = "1mo"
+)
+
+var ErrUnknownInterval = errors.New("unknown interval")
+
+func Intervals() []Interval {
+	return []Interval{
+		IntervalMinute1,
+		IntervalMinute3,
+		IntervalMinute5,
+		IntervalMinute15,
+		IntervalMinute30,
+		IntervalMinute60,
+		IntervalMinute240,
+		IntervalDay,
+		IntervalWeek,
+		IntervalMonth,
+	}
+}
+
+func ParseInterval(s string) (Interval, error) {
+	interval := Interval(s)
+	if interval.Duration() == 0 {
+		return "", errors.Wrap(ErrUnknownInterval, s)
+	}
+	return interval, nil
+}
+
+// Duration 캔들 하나의 기간. 월 캔들은 30일로 계산한다.
+func (i Interval) Duration() time.Duration {
+	const day = 24 * time.Hour
+	switch i {
+	case IntervalMinute1:
+		return time.Minute
+	case IntervalMinute3:
+		return 3 * time.Minute
+	case IntervalMinute5:
+		return 5 * time.Minute
+	case IntervalMinute15:
+		return 15 * time.Minute
+	case IntervalMinute30:
+		return 30 * time.Minute
+	case IntervalMinute60:
+		return time.Hour
+	case IntervalMinute240:
+		return 4 * time.Hour
+	case IntervalDay:
+		return day
+	case IntervalWeek:
+		return 7 * day
+	case IntervalMonth:
+		return 30 * day
+	default:
+		return 0
+	}
+}
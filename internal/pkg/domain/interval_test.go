@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInterval(t *testing.T) {
+	t.Parallel()
+	tests := map[string]time.Duration{
+		"1m":   time.Minute,
+		"15m":  15 * time.Minute,
+		"240m": 4 * time.Hour,
+		"1d":   24 * time.Hour,
+		"1w":   7 * 24 * time.Hour,
+		"1mo":  30 * 24 * time.Hour,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			interval, err := domain.ParseInterval(input)
+
+			require.NoError(t, err)
+			require.Equal(t, want, interval.Duration())
+		})
+	}
+}
+
+func TestParseInterval_Unknown(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"", "2h", "1D", "10m", "1d="} {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := domain.ParseInterval(input)
+
+			require.ErrorIs(t, err, domain.ErrUnknownInterval)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+var _ Event = (*LoggedEvent)(nil)
+
+// LoggedEvent 이벤트 로그에 순서대로 기록된 이벤트. id는 1부터 증가한다.
+type LoggedEvent struct {
+	id         uint64
+	topic      string
+	payload    []byte
+	recordedAt time.Time
+}
+
+func NewLoggedEvent(id uint64, topic string, payload []byte, recordedAt time.Time) *LoggedEvent {
+	return &LoggedEvent{id: id, topic: topic, payload: payload, recordedAt: recordedAt}
+}
+
+func (e *LoggedEvent) ID() uint64 {
+	return e.id
+}
+
+func (e *LoggedEvent) Topic() string {
+	return e.topic
+}
+
+func (e *LoggedEvent) Payload() []byte {
+	return e.payload
+}
+
+func (e *LoggedEvent) RecordedAt() time.Time {
+	return e.recordedAt
+}
+
+// Topics 버스로 오가는 모든 이벤트 주제.
+func Topics() []string {
+	return []string{
+		CoinCreatedEventTopic,
+		CoinUpdatedEventTopic,
+		CoinDeletedEventTopic,
+		BannedCoinCreatedEventTopic,
+		BannedCoinDeletedEventTopic,
+		TradesUpdatedEventTopic,
+		TradesDeletedEventTopic,
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Quote 코인 가격을 매기는 기준 화폐. e.g. KRW-BTC의 KRW
+type Quote string
+
+const (
+	QuoteKRW  Quote = "KRW"
+	QuoteBTC  Quote = "BTC"
+	QuoteUSDT Quote = "USDT"
+)
+
+var ErrUnknownQuote = errors.New("unknown quote")
+
+func Quotes() []Quote {
+	return []Quote{QuoteKRW, QuoteBTC, QuoteUSDT}
+}
+
+func ParseQuote(s string) (Quote, error) {
+	quote := Quote(strings.ToUpper(strings.TrimSpace(s)))
+	for _, known := range Quotes() {
+		if quote == known {
+			return quote, nil
+		}
+	}
+	return "", errors.Wrap(ErrUnknownQuote, s)
+}
+
+// Quote 업비트 "KRW-BTC"와 빗썸 "BTC_KRW"의 "KRW".
+func (c CoinID) Quote() Quote {
+	quote, _ := splitMarket(c.Market())
+	return quote
+}
+
+// Base 업비트 "KRW-BTC"와 빗썸 "BTC_KRW"의 "BTC".
+func (c CoinID) Base() string {
+	_, base := splitMarket(c.Market())
+	return base
+}
+
+// splitMarket 업비트는 "기준-대상", 빗썸은 "대상_기준" 순서로 마켓 이름을 짓는다.
+func splitMarket(market string) (Quote, string) {
+	if quote, base, ok := strings.Cut(market, "-"); ok {
+		return Quote(quote), base
+	}
+	if base, quote, ok := strings.Cut(market, "_"); ok {
+		return Quote(quote), base
+	}
+	return "", market
+}
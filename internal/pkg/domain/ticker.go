@@ -0,0 +1,91 @@
+package domain
+
+import "time"
+
+// Ticker 코인의 실시간 현재가.
+type Ticker struct {
+	coinID   CoinID
+	price    Price
+	tradedAt time.Time
+
+	changePrice  Price  // 전일 종가 대비 변화액. 하락하면 음수이다.
+	changeRate   Rate   // 전일 종가 대비 변화율. 하락하면 음수이다.
+	volume24h    Volume // 24시간 누적 거래량
+	tradedValue  Price  // 24시간 누적 거래 대금
+	openingPrice Price
+	highPrice    Price
+	lowPrice     Price
+}
+
+func NewTicker(coinID CoinID, price Price, tradedAt time.Time) *Ticker {
+	return &Ticker{
+		coinID:   coinID,
+		price:    price,
+		tradedAt: tradedAt,
+	}
+}
+
+// SetChange 전일 종가 대비 변화 정보를 설정한 현재가를 반환한다.
+func (t *Ticker) SetChange(changePrice Price, changeRate Rate) *Ticker {
+	ret := *t
+	ret.changePrice = changePrice
+	ret.changeRate = changeRate
+	return &ret
+}
+
+// SetVolume 24시간 거래량과 거래 대금을 설정한 현재가를 반환한다.
+func (t *Ticker) SetVolume(volume24h Volume, tradedValue Price) *Ticker {
+	ret := *t
+	ret.volume24h = volume24h
+	ret.tradedValue = tradedValue
+	return &ret
+}
+
+// SetRange 당일 시가, 고가, 저가를 설정한 현재가를 반환한다.
+func (t *Ticker) SetRange(openingPrice, highPrice, lowPrice Price) *Ticker {
+	ret := *t
+	ret.openingPrice = openingPrice
+	ret.highPrice = highPrice
+	ret.lowPrice = lowPrice
+	return &ret
+}
+
+func (t *Ticker) CoinID() CoinID {
+	return t.coinID
+}
+
+func (t *Ticker) Price() Price {
+	return t.price
+}
+
+func (t *Ticker) TradedAt() time.Time {
+	return t.tradedAt
+}
+
+func (t *Ticker) ChangePrice() Price {
+	return t.changePrice
+}
+
+func (t *Ticker) ChangeRate() Rate {
+	return t.changeRate
+}
+
+func (t *Ticker) Volume24h() Volume {
+	return t.volume24h
+}
+
+func (t *Ticker) TradedValue() Price {
+	return t.tradedValue
+}
+
+func (t *Ticker) OpeningPrice() Price {
+	return t.openingPrice
+}
+
+func (t *Ticker) HighPrice() Price {
+	return t.highPrice
+}
+
+func (t *Ticker) LowPrice() Price {
+	return t.lowPrice
+}
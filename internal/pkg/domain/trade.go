@@ -5,6 +5,12 @@ import "time"
 // Price 소수점을 가지는 가격이 많다. 따라서 문자열로 표현한다.
 type Price string
 
+// Volume 거래량. 가격과 마찬가지로 문자열로 표현한다.
+type Volume string
+
+// Rate 변화율. e.g. 0.0123 (1.23%)
+type Rate string
+
 // Trade 특정 일자의 트레이드 정보
 type Trade struct {
 	date         time.Time // e.g. 2025-01-21
@@ -12,6 +18,12 @@ type Trade struct {
 	openingPrice Price
 	maxPrice     Price
 	minPrice     Price
+
+	volume           Volume // 누적 거래량
+	tradedValue      Price  // 누적 거래 대금
+	prevClosingPrice Price  // 전일 종가
+	changePrice      Price  // 전일 종가 대비 변화액
+	changeRate       Rate   // 전일 종가 대비 변화율
 }
 
 func NewTrade(date time.Time, lastPrice, openingPrice, maxPrice, minPrice Price) *Trade {
@@ -24,6 +36,23 @@ func NewTrade(date time.Time, lastPrice, openingPrice, maxPrice, minPrice Price)
 	}
 }
 
+// SetVolume 거래량과 거래 대금을 설정한 트레이드를 반환한다.
+func (t *Trade) SetVolume(volume Volume, tradedValue Price) *Trade {
+	ret := *t
+	ret.volume = volume
+	ret.tradedValue = tradedValue
+	return &ret
+}
+
+// SetChange 전일 종가 대비 변화 정보를 설정한 트레이드를 반환한다.
+func (t *Trade) SetChange(prevClosingPrice, changePrice Price, changeRate Rate) *Trade {
+	ret := *t
+	ret.prevClosingPrice = prevClosingPrice
+	ret.changePrice = changePrice
+	ret.changeRate = changeRate
+	return &ret
+}
+
 func (t *Trade) Date() time.Time {
 	return t.date
 }
@@ -43,3 +72,37 @@ func (t *Trade) MaxPrice() Price {
 func (t *Trade) MinPrice() Price {
 	return t.minPrice
 }
+
+func (t *Trade) Volume() Volume {
+	return t.volume
+}
+
+func (t *Trade) TradedValue() Price {
+	return t.tradedValue
+}
+
+func (t *Trade) PrevClosingPrice() Price {
+	return t.prevClosingPrice
+}
+
+func (t *Trade) ChangePrice() Price {
+	return t.changePrice
+}
+
+func (t *Trade) ChangeRate() Rate {
+	return t.changeRate
+}
+
+// Equal 같은 일시의 같은 내용인지 비교한다.
+func (t *Trade) Equal(other *Trade) bool {
+	return t.date.Equal(other.date) &&
+		t.lastPrice == other.lastPrice &&
+		t.openingPrice == other.openingPrice &&
+		t.maxPrice == other.maxPrice &&
+		t.minPrice == other.minPrice &&
+		t.volume == other.volume &&
+		t.tradedValue == other.tradedValue &&
+		t.prevClosingPrice == other.prevClosingPrice &&
+		t.changePrice == other.changePrice &&
+		t.changeRate == other.changeRate
+}
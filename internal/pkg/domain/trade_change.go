@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// TradeChangeKind 저장소에 캔들이 어떻게 반영되었는지 나타낸다.
+type TradeChangeKind string
+
+const (
+	TradeChangeCreated TradeChangeKind = "created" // 새 캔들이 추가됨
+	TradeChangeUpdated TradeChangeKind = "updated" // 진행 중인 캔들이 갱신됨
+)
+
+type TradeChange struct {
+	kind  TradeChangeKind
+	trade *Trade
+}
+
+func NewTradeChange(kind TradeChangeKind, trade *Trade) *TradeChange {
+	return &TradeChange{kind: kind, trade: trade}
+}
+
+func (c *TradeChange) Kind() TradeChangeKind {
+	return c.kind
+}
+
+func (c *TradeChange) Trade() *Trade {
+	return c.trade
+}
+
+func (c *TradeChange) Date() time.Time {
+	return c.trade.Date()
+}
@@ -7,21 +7,26 @@ import (
 
 type Trades struct {
 	coinID     CoinID
+	interval   Interval
 	modifiedAt time.Time
 	trades     []*Trade
 }
 
-func NewTrades(coinID CoinID, modifiedAt time.Time, trades []*Trade) *Trades {
+func NewTrades(coinID CoinID, interval Interval, modifiedAt time.Time, trades []*Trade) *Trades {
 	sort.Slice(trades, func(i, j int) bool {
 		return trades[i].Date().Before(trades[j].Date())
 	})
-	return &Trades{coinID: coinID, modifiedAt: modifiedAt, trades: trades}
+	return &Trades{coinID: coinID, interval: interval, modifiedAt: modifiedAt, trades: trades}
 }
 
 func (t *Trades) CoinID() CoinID {
 	return t.coinID
 }
 
+func (t *Trades) Interval() Interval {
+	return t.interval
+}
+
 func (t *Trades) ModifiedAt() time.Time {
 	return t.modifiedAt
 }
@@ -42,3 +47,11 @@ func (t *Trades) IsEnoughTrade() bool {
 func (t *Trades) LastPrice() Price {
 	return t.trades[len(t.trades)-1].LastPrice()
 }
+
+// OldestDate 가장 오래된 트레이드의 일시. 트레이드가 없다면 zero value를 반환한다.
+func (t *Trades) OldestDate() time.Time {
+	if len(t.trades) == 0 {
+		return time.Time{}
+	}
+	return t.trades[0].Date()
+}
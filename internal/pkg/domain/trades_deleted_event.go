@@ -7,20 +7,16 @@ var _ Event = (*TradesDeletedEvent)(nil)
 const TradesDeletedEventTopic = "trades.deleted"
 
 type TradesDeletedEvent struct {
-	CoinID CoinID `json:"coin_id"`
+	CoinID   CoinID   `json:"coin_id"`
+	Interval Interval `json:"interval"`
 }
 
-func NewTradesDeletedEvent(coinID CoinID) *TradesDeletedEvent {
-	return &TradesDeletedEvent{CoinID: coinID}
+func NewTradesDeletedEvent(coinID CoinID, interval Interval) *TradesDeletedEvent {
+	return &TradesDeletedEvent{CoinID: coinID, Interval: interval}
 }
 
-func ParseTradesDeletedEvent(payload []byte) *TradesDeletedEvent {
-	var event TradesDeletedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
-	}
-	return &event
+func ParseTradesDeletedEvent(payload []byte) (*TradesDeletedEvent, error) {
+	return parsePayload[TradesDeletedEvent](TradesDeletedEventTopic, payload)
 }
 
 func (e *TradesDeletedEvent) Topic() string {
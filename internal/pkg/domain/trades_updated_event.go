@@ -1,28 +1,38 @@
 package domain
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 var _ Event = (*TradesUpdatedEvent)(nil)
 
 const TradesUpdatedEventTopic = "trades.updated"
 
 type TradesUpdatedEvent struct {
-	CoinID CoinID `json:"coin_id"`
+	CoinID   CoinID      `json:"coin_id"`
+	Interval Interval    `json:"interval"`
+	Created  []time.Time `json:"created,omitempty"` // 새로 추가된 캔들의 일시
+	Updated  []time.Time `json:"updated,omitempty"` // 갱신된 진행 중 캔들의 일시
 }
 
-func NewTradesUpdatedEvent(coinID CoinID) *TradesUpdatedEvent {
-	return &TradesUpdatedEvent{CoinID: coinID}
-}
-
-func ParseTradesUpdatedEvent(payload []byte) *TradesUpdatedEvent {
-	var event TradesUpdatedEvent
-	err := json.Unmarshal(payload, &event)
-	if err != nil {
-		panic(err)
+func NewTradesUpdatedEvent(coinID CoinID, interval Interval, changes []*TradeChange) *TradesUpdatedEvent {
+	event := TradesUpdatedEvent{CoinID: coinID, Interval: interval, Created: nil, Updated: nil}
+	for _, change := range changes {
+		switch change.Kind() {
+		case TradeChangeCreated:
+			event.Created = append(event.Created, change.Date())
+		case TradeChangeUpdated:
+			event.Updated = append(event.Updated, change.Date())
+		}
 	}
 	return &event
 }
 
+func ParseTradesUpdatedEvent(payload []byte) (*TradesUpdatedEvent, error) {
+	return parsePayload[TradesUpdatedEvent](TradesUpdatedEventTopic, payload)
+}
+
 func (e *TradesUpdatedEvent) Topic() string {
 	return TradesUpdatedEventTopic
 }
@@ -5,4 +5,5 @@ import "github.com/pkg/errors"
 var (
 	ErrKeyNotFound      = errors.New("key not found")
 	ErrKeyAlreadyExists = errors.New("key already exists")
+	ErrConflict         = errors.New("transaction conflict")
 )
@@ -3,7 +3,16 @@ package keyvalue
 type Store interface {
 	Create(key []byte, value []byte) error
 	List(prefix []byte) ([][]byte, error)
+	// Keys prefix로 시작하는 키를 키 순서로 돌려준다.
+	Keys(prefix []byte) ([][]byte, error)
 	Get(key []byte) ([]byte, error)
 	Update(key []byte, value []byte) error
 	Delete(key []byte) error
+	// Commit mutations를 모두 반영하거나 하나도 반영하지 않는다.
+	Commit(mutations ...Mutation) error
+	// Txn fn을 하나의 트랜잭션으로 실행한다. fn이 오류를 반환하면 아무것도 반영하지 않는다.
+	// fn이 읽은 키를 다른 트랜잭션이 먼저 바꿨다면 ErrConflict이다.
+	Txn(fn func(txn Txn) error) error
+	// WriteBatch fn이 쓴 값을 모아서 반영한다. 트랜잭션 크기를 넘는 대량 쓰기에 쓰며, 원자적이지 않다.
+	WriteBatch(fn func(batch Batch) error) error
 }
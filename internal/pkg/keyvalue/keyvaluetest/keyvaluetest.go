@@ -0,0 +1,184 @@
+// Package keyvaluetest keyvalue.Store 구현이 모두 지켜야 하는 동작을 시험한다.
+package keyvaluetest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// Run newStore가 만든 빈 Store로 하위 테스트를 하나씩 실행한다. 정리할 것이 있다면 newStore에서 t.Cleanup으로 등록한다.
+func Run(t *testing.T, newStore func(t *testing.T) keyvalue.Store) { //nolint:funlen
+	t.Helper()
+	tests := map[string]func(t *testing.T, store keyvalue.Store){
+		"Create":                   testCreate,
+		"Get":                      testGet,
+		"Update":                   testUpdate,
+		"Delete":                   testDelete,
+		"ListInKeyOrder":           testListInKeyOrder,
+		"KeysInKeyOrder":           testKeysInKeyOrder,
+		"CommitIsAtomic":           testCommitIsAtomic,
+		"TxnReadsOwnWrites":        testTxnReadsOwnWrites,
+		"TxnDiscardsWritesOnError": testTxnDiscardsWritesOnError,
+		"CompareAndSwap":           testCompareAndSwap,
+		"WriteBatch":               testWriteBatch,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			test(t, newStore(t))
+		})
+	}
+}
+
+func testCreate(t *testing.T, store keyvalue.Store) {
+	err := store.Create([]byte("key"), []byte("value"))
+	require.NoError(t, err)
+
+	err = store.Create([]byte("key"), []byte("value2"))
+
+	require.ErrorIs(t, err, keyvalue.ErrKeyAlreadyExists)
+}
+
+func testGet(t *testing.T, store keyvalue.Store) {
+	value, err := store.Get([]byte("key"))
+
+	require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+	require.Empty(t, value)
+}
+
+func testUpdate(t *testing.T, store keyvalue.Store) {
+	err := store.Update([]byte("key"), []byte("value"))
+
+	require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+}
+
+func testDelete(t *testing.T, store keyvalue.Store) {
+	err := store.Delete([]byte("key"))
+
+	require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+}
+
+func testListInKeyOrder(t *testing.T, store keyvalue.Store) {
+	for _, key := range []string{"key:b", "other", "key:c", "key:a"} {
+		err := store.Create([]byte(key), []byte(key))
+		require.NoError(t, err)
+	}
+
+	values, err := store.List([]byte("key:"))
+
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("key:a"), []byte("key:b"), []byte("key:c")}, values)
+}
+
+func testKeysInKeyOrder(t *testing.T, store keyvalue.Store) {
+	for _, key := range []string{"key:b", "other", "key:a"} {
+		err := store.Create([]byte(key), []byte("value"))
+		require.NoError(t, err)
+	}
+
+	keys, err := store.Keys([]byte("key:"))
+
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("key:a"), []byte("key:b")}, keys)
+}
+
+func testCommitIsAtomic(t *testing.T, store keyvalue.Store) {
+	err := store.Create([]byte("exists"), []byte("value"))
+	require.NoError(t, err)
+
+	err = store.Commit(
+		keyvalue.CreateMutation([]byte("new"), []byte("value")),
+		keyvalue.CreateMutation([]byte("exists"), []byte("value2")),
+	)
+
+	require.ErrorIs(t, err, keyvalue.ErrKeyAlreadyExists)
+	_, err = store.Get([]byte("new"))
+	require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+}
+
+func testTxnReadsOwnWrites(t *testing.T, store keyvalue.Store) {
+	err := store.Create([]byte("key:deleted"), []byte("value"))
+	require.NoError(t, err)
+
+	err = store.Txn(func(txn keyvalue.Txn) error {
+		err := txn.Create([]byte("key:created"), []byte("value"))
+		require.NoError(t, err)
+		err = txn.Delete([]byte("key:deleted"))
+		require.NoError(t, err)
+		value, _, err := txn.Get([]byte("key:created"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), value)
+		_, _, err = txn.Get([]byte("key:deleted"))
+		require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+		values, err := txn.List([]byte("key:"))
+		require.NoError(t, err)
+		require.Len(t, values, 1)
+		return nil
+	})
+
+	require.NoError(t, err)
+}
+
+func testTxnDiscardsWritesOnError(t *testing.T, store keyvalue.Store) {
+	boom := errors.New("boom")
+
+	err := store.Txn(func(txn keyvalue.Txn) error {
+		err := txn.Create([]byte("key"), []byte("value"))
+		require.NoError(t, err)
+		return boom
+	})
+
+	require.ErrorIs(t, err, boom)
+	_, err = store.Get([]byte("key"))
+	require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+}
+
+func testCompareAndSwap(t *testing.T, store keyvalue.Store) {
+	err := store.Txn(func(txn keyvalue.Txn) error {
+		return txn.CompareAndSwap([]byte("key"), 0, []byte("value"))
+	})
+	require.NoError(t, err)
+	var version keyvalue.Version
+	err = store.Txn(func(txn keyvalue.Txn) error {
+		var err error
+		_, version, err = txn.Get([]byte("key"))
+		return err
+	})
+	require.NoError(t, err)
+	require.NotZero(t, version)
+	err = store.Update([]byte("key"), []byte("value2"))
+	require.NoError(t, err)
+
+	err = store.Txn(func(txn keyvalue.Txn) error {
+		return txn.CompareAndSwap([]byte("key"), version, []byte("value3"))
+	})
+
+	require.ErrorIs(t, err, keyvalue.ErrConflict)
+	value, err := store.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), value)
+}
+
+func testWriteBatch(t *testing.T, store keyvalue.Store) {
+	err := store.Create([]byte("key:0"), []byte("value"))
+	require.NoError(t, err)
+
+	err = store.WriteBatch(func(batch keyvalue.Batch) error {
+		for i := range 1000 {
+			err := batch.Set([]byte("key:"+strconv.Itoa(i+1)), []byte("value"))
+			if err != nil {
+				return err
+			}
+		}
+		return batch.Delete([]byte("key:0"))
+	})
+
+	require.NoError(t, err)
+	values, err := store.List([]byte("key:"))
+	require.NoError(t, err)
+	require.Len(t, values, 1000)
+}
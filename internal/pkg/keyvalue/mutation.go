@@ -0,0 +1,28 @@
+package keyvalue
+
+type MutationKind int
+
+const (
+	MutationCreate MutationKind = iota // 키가 이미 있으면 ErrKeyAlreadyExists
+	MutationUpdate                     // 키가 없으면 ErrKeyNotFound
+	MutationDelete                     // 키가 없으면 ErrKeyNotFound
+)
+
+// Mutation Commit으로 여러 키를 한꺼번에 바꿀 때 쓰는 변경 하나.
+type Mutation struct {
+	Kind  MutationKind
+	Key   []byte
+	Value []byte
+}
+
+func CreateMutation(key []byte, value []byte) Mutation {
+	return Mutation{Kind: MutationCreate, Key: key, Value: value}
+}
+
+func UpdateMutation(key []byte, value []byte) Mutation {
+	return Mutation{Kind: MutationUpdate, Key: key, Value: value}
+}
+
+func DeleteMutation(key []byte) Mutation {
+	return Mutation{Kind: MutationDelete, Key: key, Value: nil}
+}
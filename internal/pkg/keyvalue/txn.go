@@ -0,0 +1,23 @@
+package keyvalue
+
+// Version 키를 마지막으로 쓴 시점. 없는 키의 버전은 0이다.
+type Version uint64
+
+// Txn Store.Txn 안에서 쓰는 트랜잭션. 같은 트랜잭션에서 쓴 값은 바로 다시 읽힌다.
+// 다만 그 값의 버전은 커밋해야 정해지므로 CompareAndSwap에는 커밋된 값의 버전을 쓴다.
+type Txn interface {
+	Get(key []byte) ([]byte, Version, error)
+	List(prefix []byte) ([][]byte, error)
+	Create(key []byte, value []byte) error
+	Update(key []byte, value []byte) error
+	Delete(key []byte) error
+	// CompareAndSwap 키의 버전이 version일 때만 value를 쓴다. 다르면 ErrConflict이다.
+	// version이 0이면 키가 없어야 한다.
+	CompareAndSwap(key []byte, version Version, value []byte) error
+}
+
+// Batch Store.WriteBatch 안에서 쓴다. 읽지 않고 쓰기만 하므로 키가 있는지 확인하지 않는다.
+type Batch interface {
+	Set(key []byte, value []byte) error
+	Delete(key []byte) error
+}
@@ -25,93 +25,139 @@ func (s *Store) Close() {
 }
 
 func (s *Store) Create(key []byte, value []byte) error {
-	err := s.db.Update(func(txn *badger.Txn) error {
-		_, err := txn.Get(key)
-		if err == nil {
-			return keyvalue.ErrKeyAlreadyExists
-		}
-		return txn.Set(key, value)
+	return s.Txn(func(txn keyvalue.Txn) error {
+		return txn.Create(key, value)
+	})
+}
+
+func (s *Store) List(prefix []byte) ([][]byte, error) {
+	var ret [][]byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		ret, err = list(txn, prefix)
+		return err
 	})
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, storeError(err)
 	}
-	return nil
+	return ret, nil
 }
 
-func (s *Store) List(prefix []byte) ([][]byte, error) {
+func (s *Store) Keys(prefix []byte) ([][]byte, error) {
 	var ret [][]byte
 	err := s.db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.IteratorOptions{}) //nolint:exhaustruct
+		it := txn.NewIterator(badger.IteratorOptions{PrefetchValues: false}) //nolint:exhaustruct
 		defer it.Close()
 		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			item := it.Item()
-			value, err := getValue(item)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			ret = append(ret, value)
+			ret = append(ret, it.Item().KeyCopy(nil))
 		}
 		return nil
 	})
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, storeError(err)
 	}
 	return ret, nil
 }
 
 func (s *Store) Get(key []byte) ([]byte, error) {
 	var ret []byte
-	err := s.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(key)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		value, err := getValue(item)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		ret = value
-		return nil
+	err := s.db.View(func(badgerTxn *badger.Txn) error {
+		var err error
+		ret, _, err = (&txn{txn: badgerTxn}).Get(key)
+		return err
 	})
 	if err != nil {
-		if errors.Is(err, badger.ErrKeyNotFound) {
-			return nil, keyvalue.ErrKeyNotFound
-		}
-		return nil, errors.WithStack(err)
+		return nil, storeError(err)
 	}
 	return ret, nil
 }
 
 func (s *Store) Update(key []byte, value []byte) error {
-	err := s.db.Update(func(txn *badger.Txn) error {
-		_, err := txn.Get(key)
-		if err != nil {
-			return errors.WithStack(err)
+	return s.Txn(func(txn keyvalue.Txn) error {
+		return txn.Update(key, value)
+	})
+}
+
+func (s *Store) Delete(key []byte) error {
+	return s.Txn(func(txn keyvalue.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+func (s *Store) Commit(mutations ...keyvalue.Mutation) error {
+	return s.Txn(func(txn keyvalue.Txn) error {
+		for _, mutation := range mutations {
+			err := apply(txn, mutation)
+			if err != nil {
+				return err
+			}
 		}
-		return txn.Set(key, value)
+		return nil
+	})
+}
+
+func (s *Store) Txn(fn func(txn keyvalue.Txn) error) error {
+	err := s.db.Update(func(badgerTxn *badger.Txn) error {
+		return fn(&txn{txn: badgerTxn})
 	})
 	if err != nil {
-		if errors.Is(err, badger.ErrKeyNotFound) {
-			return keyvalue.ErrKeyNotFound
-		}
-		return errors.WithStack(err)
+		return storeError(err)
 	}
 	return nil
 }
 
-func (s *Store) Delete(key []byte) error {
-	err := s.db.Update(func(txn *badger.Txn) error {
-		_, err := txn.Get(key)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		return txn.Delete(key)
-	})
+// WriteBatch badger.WriteBatch는 트랜잭션 크기를 넘으면 알아서 나눠 쓴다.
+func (s *Store) WriteBatch(fn func(batch keyvalue.Batch) error) error {
+	batch := s.db.NewWriteBatch()
+	err := fn(batch)
+	if err != nil {
+		batch.Cancel()
+		return storeError(err)
+	}
+	err = batch.Flush()
 	if err != nil {
-		if errors.Is(err, badger.ErrKeyNotFound) {
-			return keyvalue.ErrKeyNotFound
+		return storeError(err)
+	}
+	return nil
+}
+
+func apply(txn keyvalue.Txn, mutation keyvalue.Mutation) error {
+	switch mutation.Kind {
+	case keyvalue.MutationCreate:
+		return txn.Create(mutation.Key, mutation.Value)
+	case keyvalue.MutationUpdate:
+		return txn.Update(mutation.Key, mutation.Value)
+	case keyvalue.MutationDelete:
+		return txn.Delete(mutation.Key)
+	default:
+		return errors.Errorf("unknown mutation kind %v", mutation.Kind)
+	}
+}
+
+func list(txn *badger.Txn, prefix []byte) ([][]byte, error) {
+	var ret [][]byte
+	it := txn.NewIterator(badger.IteratorOptions{}) //nolint:exhaustruct
+	defer it.Close()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		value, err := getValue(it.Item())
+		if err != nil {
+			return nil, errors.WithStack(err)
 		}
+		ret = append(ret, value)
+	}
+	return ret, nil
+}
+
+// storeError badger 오류를 keyvalue 오류로 바꾼다.
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound), errors.Is(err, keyvalue.ErrKeyNotFound):
+		return keyvalue.ErrKeyNotFound
+	case errors.Is(err, keyvalue.ErrKeyAlreadyExists):
+		return keyvalue.ErrKeyAlreadyExists
+	case errors.Is(err, badger.ErrConflict), errors.Is(err, keyvalue.ErrConflict):
+		return keyvalue.ErrConflict
+	default:
 		return errors.WithStack(err)
 	}
-	return nil
 }
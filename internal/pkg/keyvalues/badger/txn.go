@@ -0,0 +1,80 @@
+package badger
+
+import (
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+var _ keyvalue.Txn = (*txn)(nil)
+
+// txn badger는 읽은 키를 기억해 두었다가 커밋할 때 다른 트랜잭션이 먼저 바꿨으면 ErrConflict를 낸다.
+type txn struct {
+	txn *badger.Txn
+}
+
+func (t *txn) Get(key []byte) ([]byte, keyvalue.Version, error) {
+	item, err := t.txn.Get(key)
+	if err != nil {
+		return nil, 0, storeError(err)
+	}
+	value, err := getValue(item)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return value, keyvalue.Version(item.Version()), nil
+}
+
+func (t *txn) List(prefix []byte) ([][]byte, error) {
+	return list(t.txn, prefix)
+}
+
+func (t *txn) Create(key []byte, value []byte) error {
+	version, err := t.version(key)
+	if err != nil {
+		return err
+	}
+	if version != 0 {
+		return keyvalue.ErrKeyAlreadyExists
+	}
+	return errors.WithStack(t.txn.Set(key, value))
+}
+
+func (t *txn) Update(key []byte, value []byte) error {
+	_, err := t.txn.Get(key)
+	if err != nil {
+		return storeError(err)
+	}
+	return errors.WithStack(t.txn.Set(key, value))
+}
+
+func (t *txn) Delete(key []byte) error {
+	_, err := t.txn.Get(key)
+	if err != nil {
+		return storeError(err)
+	}
+	return errors.WithStack(t.txn.Delete(key))
+}
+
+func (t *txn) CompareAndSwap(key []byte, version keyvalue.Version, value []byte) error {
+	current, err := t.version(key)
+	if err != nil {
+		return err
+	}
+	if current != version {
+		return keyvalue.ErrConflict
+	}
+	return errors.WithStack(t.txn.Set(key, value))
+}
+
+// version 없는 키는 0이다.
+func (t *txn) version(key []byte) (keyvalue.Version, error) {
+	item, err := t.txn.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, storeError(err)
+	}
+	return keyvalue.Version(item.Version()), nil
+}
@@ -0,0 +1,263 @@
+// Package memory 프로세스 메모리에 값을 두는 keyvalue.Store. 테스트에서 badger 대신 쓴다.
+package memory
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/pkg/errors"
+)
+
+var _ keyvalue.Store = (*Store)(nil)
+
+type entry struct {
+	value   []byte
+	version keyvalue.Version
+}
+
+// Store 트랜잭션을 하나씩 차례로 실행하므로 트랜잭션끼리 충돌하지 않는다.
+// 버전은 커밋할 때마다 하나씩 늘어난다.
+type Store struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	version keyvalue.Version
+}
+
+func NewStore() *Store {
+	return &Store{
+		mu:      sync.Mutex{},
+		entries: map[string]*entry{},
+		version: 0,
+	}
+}
+
+func (s *Store) Create(key []byte, value []byte) error {
+	return s.Txn(func(txn keyvalue.Txn) error {
+		return txn.Create(key, value)
+	})
+}
+
+func (s *Store) List(prefix []byte) ([][]byte, error) {
+	var ret [][]byte
+	err := s.Txn(func(txn keyvalue.Txn) error {
+		var err error
+		ret, err = txn.List(prefix)
+		return err
+	})
+	return ret, err
+}
+
+func (s *Store) Keys(prefix []byte) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.entries {
+		if strings.HasPrefix(key, string(prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	ret := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		ret = append(ret, []byte(key))
+	}
+	return ret, nil
+}
+
+func (s *Store) Get(key []byte) ([]byte, error) {
+	var ret []byte
+	err := s.Txn(func(txn keyvalue.Txn) error {
+		var err error
+		ret, _, err = txn.Get(key)
+		return err
+	})
+	return ret, err
+}
+
+func (s *Store) Update(key []byte, value []byte) error {
+	return s.Txn(func(txn keyvalue.Txn) error {
+		return txn.Update(key, value)
+	})
+}
+
+func (s *Store) Delete(key []byte) error {
+	return s.Txn(func(txn keyvalue.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+func (s *Store) Commit(mutations ...keyvalue.Mutation) error {
+	return s.Txn(func(txn keyvalue.Txn) error {
+		for _, mutation := range mutations {
+			err := apply(txn, mutation)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) Txn(fn func(txn keyvalue.Txn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := &txn{store: s, writes: map[string]*entry{}}
+	err := fn(t)
+	if err != nil {
+		return err
+	}
+	s.write(t.writes)
+	return nil
+}
+
+func (s *Store) WriteBatch(fn func(batch keyvalue.Batch) error) error {
+	b := &batch{writes: map[string]*entry{}}
+	err := fn(b)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.write(b.writes)
+	return nil
+}
+
+// write value가 nil인 entry는 지운다.
+func (s *Store) write(writes map[string]*entry) {
+	if len(writes) == 0 {
+		return
+	}
+	s.version++
+	for key, written := range writes {
+		if written.value == nil {
+			delete(s.entries, key)
+			continue
+		}
+		s.entries[key] = &entry{value: written.value, version: s.version}
+	}
+}
+
+func apply(txn keyvalue.Txn, mutation keyvalue.Mutation) error {
+	switch mutation.Kind {
+	case keyvalue.MutationCreate:
+		return txn.Create(mutation.Key, mutation.Value)
+	case keyvalue.MutationUpdate:
+		return txn.Update(mutation.Key, mutation.Value)
+	case keyvalue.MutationDelete:
+		return txn.Delete(mutation.Key)
+	default:
+		return errors.Errorf("unknown mutation kind %v", mutation.Kind)
+	}
+}
+
+// txn 쓴 값은 writes에 모았다가 fn이 성공하면 반영한다.
+type txn struct {
+	store  *Store
+	writes map[string]*entry
+}
+
+func (t *txn) lookup(key []byte) (*entry, bool) {
+	if written, ok := t.writes[string(key)]; ok {
+		return written, written.value != nil
+	}
+	stored, ok := t.store.entries[string(key)]
+	return stored, ok
+}
+
+func (t *txn) Get(key []byte) ([]byte, keyvalue.Version, error) {
+	found, ok := t.lookup(key)
+	if !ok {
+		return nil, 0, keyvalue.ErrKeyNotFound
+	}
+	return bytes.Clone(found.value), found.version, nil
+}
+
+// List 키 순서로 돌려준다.
+func (t *txn) List(prefix []byte) ([][]byte, error) {
+	var keys []string
+	for key := range t.store.entries {
+		if strings.HasPrefix(key, string(prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range t.writes {
+		if strings.HasPrefix(key, string(prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	var ret [][]byte
+	for _, key := range keys {
+		found, ok := t.lookup([]byte(key))
+		if !ok {
+			continue
+		}
+		ret = append(ret, bytes.Clone(found.value))
+	}
+	return ret, nil
+}
+
+func (t *txn) Create(key []byte, value []byte) error {
+	if _, ok := t.lookup(key); ok {
+		return keyvalue.ErrKeyAlreadyExists
+	}
+	t.set(key, value)
+	return nil
+}
+
+func (t *txn) Update(key []byte, value []byte) error {
+	if _, ok := t.lookup(key); !ok {
+		return keyvalue.ErrKeyNotFound
+	}
+	t.set(key, value)
+	return nil
+}
+
+func (t *txn) Delete(key []byte) error {
+	if _, ok := t.lookup(key); !ok {
+		return keyvalue.ErrKeyNotFound
+	}
+	t.writes[string(key)] = &entry{value: nil, version: 0}
+	return nil
+}
+
+func (t *txn) CompareAndSwap(key []byte, version keyvalue.Version, value []byte) error {
+	var current keyvalue.Version
+	if found, ok := t.lookup(key); ok {
+		current = found.version
+	}
+	if current != version {
+		return keyvalue.ErrConflict
+	}
+	t.set(key, value)
+	return nil
+}
+
+// set 커밋할 때의 버전을 미리 매겨 같은 트랜잭션에서 CompareAndSwap할 수 있게 한다.
+func (t *txn) set(key []byte, value []byte) {
+	if value == nil {
+		value = []byte{}
+	}
+	t.writes[string(key)] = &entry{value: bytes.Clone(value), version: t.store.version + 1}
+}
+
+type batch struct {
+	writes map[string]*entry
+}
+
+func (b *batch) Set(key []byte, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	b.writes[string(key)] = &entry{value: bytes.Clone(value), version: 0}
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.writes[string(key)] = &entry{value: nil, version: 0}
+	return nil
+}
@@ -0,0 +1,16 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue/keyvaluetest"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/memory"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+	keyvaluetest.Run(t, func(*testing.T) keyvalue.Store {
+		return memory.NewStore()
+	})
+}
@@ -0,0 +1,60 @@
+package realrepository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type AllowedCoin struct {
+	SchemaVersion int `json:"schema_version"`
+
+	ID        string    `json:"id"`
+	AllowedAt time.Time `json:"allowed_at"`
+	Note      string    `json:"note"`
+}
+
+func NewAllowedCoin(coin *domain.AllowedCoin) *AllowedCoin {
+	return &AllowedCoin{
+		SchemaVersion: allowedCoinSchema.version(),
+
+		ID:        string(coin.CoinID()),
+		AllowedAt: coin.AllowedAt(),
+		Note:      coin.Note(),
+	}
+}
+
+const allowedCoinPrefix = "allowed_coin:"
+
+func AllowedCoinKey(coinID domain.CoinID) []byte {
+	return []byte(allowedCoinPrefix + string(coinID))
+}
+
+func (c *AllowedCoin) Key() []byte {
+	return AllowedCoinKey(domain.CoinID(c.ID))
+}
+
+func (c *AllowedCoin) Value() []byte {
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+var allowedCoinSchema = &schema{
+	prefix:     allowedCoinPrefix,
+	migrations: []migration{qualifyCoinID("id")},
+	new: func() record {
+		return &AllowedCoin{} //nolint:exhaustruct
+	},
+}
+
+func (c *AllowedCoin) schema() *schema {
+	return allowedCoinSchema
+}
+
+func (c *AllowedCoin) ToDomain() *domain.AllowedCoin {
+	return domain.NewAllowedCoin(domain.CoinID(c.ID), c.AllowedAt, c.Note)
+}
@@ -8,16 +8,28 @@ import (
 )
 
 type BannedCoin struct {
-	ID       string        `json:"id,omitempty"`
-	BannedAt time.Time     `json:"banned_at,omitempty"`
-	Period   time.Duration `json:"period,omitempty"`
+	SchemaVersion int `json:"schema_version"`
+
+	ID       string        `json:"id"`
+	BannedAt time.Time     `json:"banned_at"`
+	Period   time.Duration `json:"period"`
+	Reasons  []string      `json:"reasons"`
+	Note     string        `json:"note"`
 }
 
 func NewBannedCoin(coin *domain.BannedCoin) *BannedCoin {
+	var reasons []string
+	for _, reason := range coin.Reasons() {
+		reasons = append(reasons, string(reason))
+	}
 	return &BannedCoin{
+		SchemaVersion: bannedCoinSchema.version(),
+
 		ID:       string(coin.CoinID()),
 		BannedAt: coin.BannedAt(),
 		Period:   coin.Period(),
+		Reasons:  reasons,
+		Note:     coin.Note(),
 	}
 }
 
@@ -39,6 +51,22 @@ func (c *BannedCoin) Value() []byte {
 	return bytes
 }
 
+var bannedCoinSchema = &schema{
+	prefix:     bannedCoinPrefix,
+	migrations: []migration{qualifyCoinID("id")},
+	new: func() record {
+		return &BannedCoin{} //nolint:exhaustruct
+	},
+}
+
+func (c *BannedCoin) schema() *schema {
+	return bannedCoinSchema
+}
+
 func (c *BannedCoin) ToDomain() *domain.BannedCoin {
-	return domain.NewBannedCoin(domain.CoinID(c.ID), c.BannedAt, c.Period)
+	var reasons []domain.BanReason
+	for _, reason := range c.Reasons {
+		reasons = append(reasons, domain.BanReason(reason))
+	}
+	return domain.NewBannedCoin(domain.CoinID(c.ID), c.BannedAt, c.Period, reasons).SetNote(c.Note)
 }
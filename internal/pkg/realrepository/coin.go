@@ -8,18 +8,32 @@ import (
 )
 
 type Coin struct {
-	ID         string    `json:"id,omitempty"`
-	Danger     bool      `json:"danger,omitempty"`
-	ModifiedAt time.Time `json:"modified_at,omitempty"`
+	SchemaVersion int `json:"schema_version"`
+
+	ID          string    `json:"id"`
+	KoreanName  string    `json:"korean_name"`
+	EnglishName string    `json:"english_name"`
+	Warning     bool      `json:"warning"` // 버전 0에서는 danger 필드였다.
+	Cautions    []string  `json:"cautions"`
+	ModifiedAt  time.Time `json:"modified_at"`
 }
 
 const coinPrefix = "coin:"
 
 func NewCoin(domainCoin *domain.Coin) *Coin {
+	var cautions []string
+	for _, caution := range domainCoin.Cautions() {
+		cautions = append(cautions, string(caution))
+	}
 	return &Coin{
-		ID:         string(domainCoin.ID()),
-		Danger:     domainCoin.IsDanger(),
-		ModifiedAt: domainCoin.ModifiedAt(),
+		SchemaVersion: coinSchema.version(),
+
+		ID:          string(domainCoin.ID()),
+		KoreanName:  domainCoin.KoreanName(),
+		EnglishName: domainCoin.EnglishName(),
+		Warning:     domainCoin.IsWarning(),
+		Cautions:    cautions,
+		ModifiedAt:  domainCoin.ModifiedAt(),
 	}
 }
 
@@ -39,10 +53,36 @@ func (c *Coin) Value() []byte {
 	return bytes
 }
 
+var coinSchema = &schema{
+	prefix:     coinPrefix,
+	migrations: []migration{migrateCoinV0},
+	new: func() record {
+		return &Coin{} //nolint:exhaustruct
+	},
+}
+
+// migrateCoinV0 처음에는 경고를 danger 필드에 저장했다.
+func migrateCoinV0(fields map[string]any) ([]record, error) {
+	if danger, ok := fields["danger"]; ok {
+		if _, ok := fields["warning"]; !ok {
+			fields["warning"] = danger
+		}
+		delete(fields, "danger")
+	}
+	return qualifyCoinID("id")(fields)
+}
+
+func (c *Coin) schema() *schema {
+	return coinSchema
+}
+
 func (c *Coin) ToDomain() *domain.Coin {
-	return domain.NewCoin(
-		domain.CoinID(c.ID),
-		c.Danger,
-		c.ModifiedAt,
-	)
+	var cautions []domain.CautionCategory
+	for _, caution := range c.Cautions {
+		cautions = append(cautions, domain.CautionCategory(caution))
+	}
+	return domain.NewCoin(domain.CoinID(c.ID), c.ModifiedAt).
+		SetNames(c.KoreanName, c.EnglishName).
+		SetWarning(c.Warning).
+		SetCautions(cautions...)
 }
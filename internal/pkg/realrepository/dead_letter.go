@@ -0,0 +1,81 @@
+package realrepository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type DeliveryAttempt struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	Error       string    `json:"error"`
+}
+
+type DeadLetter struct {
+	SchemaVersion int `json:"schema_version"`
+
+	ID           string             `json:"id"`
+	Subscription string             `json:"subscription"`
+	EventID      string             `json:"event_id,omitempty"`
+	Topic        string             `json:"topic"`
+	Payload      []byte             `json:"payload"`
+	Attempts     []*DeliveryAttempt `json:"attempts"`
+	FailedAt     time.Time          `json:"failed_at"`
+}
+
+func NewDeadLetter(deadLetter *domain.DeadLetter) *DeadLetter {
+	var attempts []*DeliveryAttempt
+	for _, attempt := range deadLetter.Attempts() {
+		attempts = append(attempts, &DeliveryAttempt{AttemptedAt: attempt.AttemptedAt(), Error: attempt.Error()})
+	}
+	return &DeadLetter{
+		SchemaVersion: deadLetterSchema.version(),
+
+		ID:           deadLetter.ID(),
+		Subscription: deadLetter.Subscription(),
+		EventID:      deadLetter.EventID(),
+		Topic:        deadLetter.Topic(),
+		Payload:      deadLetter.Payload(),
+		Attempts:     attempts,
+		FailedAt:     deadLetter.FailedAt(),
+	}
+}
+
+const deadLetterPrefix = "dead_letter:"
+
+func DeadLetterKey(id string) []byte {
+	return []byte(deadLetterPrefix + id)
+}
+
+func (d *DeadLetter) Key() []byte {
+	return DeadLetterKey(d.ID)
+}
+
+func (d *DeadLetter) Value() []byte {
+	bytes, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+var deadLetterSchema = &schema{
+	prefix:     deadLetterPrefix,
+	migrations: []migration{unversioned},
+	new: func() record {
+		return &DeadLetter{} //nolint:exhaustruct
+	},
+}
+
+func (d *DeadLetter) schema() *schema {
+	return deadLetterSchema
+}
+
+func (d *DeadLetter) ToDomain() *domain.DeadLetter {
+	var attempts []*domain.DeliveryAttempt
+	for _, attempt := range d.Attempts {
+		attempts = append(attempts, domain.NewDeliveryAttempt(attempt.AttemptedAt, attempt.Error))
+	}
+	return domain.NewDeadLetter(d.ID, d.Subscription, d.EventID, d.Topic, d.Payload, attempts, d.FailedAt)
+}
@@ -0,0 +1,31 @@
+package realrepository
+
+import (
+	"fmt"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/pkg/errors"
+)
+
+// translate keyvalue 오류를 coinrepository 오류로 바꾼다.
+// notFound와 alreadyExists는 레코드마다 다른 오류를 쓰도록 넘긴다.
+func translate(err error, notFound error, alreadyExists error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, keyvalue.ErrKeyNotFound):
+		return errors.WithStack(notFound)
+	case errors.Is(err, keyvalue.ErrKeyAlreadyExists):
+		return errors.WithStack(alreadyExists)
+	case errors.Is(err, keyvalue.ErrConflict):
+		return errors.WithStack(coinrepository.ErrConflict)
+	default:
+		return errors.WithStack(err)
+	}
+}
+
+// corrupt 저장된 값을 읽지 못한 오류를 ErrCorrupt로 감싼다.
+func corrupt(err error) error {
+	return errors.WithStack(fmt.Errorf("%w: %w", coinrepository.ErrCorrupt, err))
+}
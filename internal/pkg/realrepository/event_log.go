@@ -0,0 +1,64 @@
+package realrepository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+type LoggedEvent struct {
+	SchemaVersion int `json:"schema_version"`
+
+	ID         uint64    `json:"id"`
+	Topic      string    `json:"topic"`
+	Payload    []byte    `json:"payload"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+func NewLoggedEvent(event *domain.LoggedEvent) *LoggedEvent {
+	return &LoggedEvent{
+		SchemaVersion: loggedEventSchema.version(),
+
+		ID:         event.ID(),
+		Topic:      event.Topic(),
+		Payload:    event.Payload(),
+		RecordedAt: event.RecordedAt(),
+	}
+}
+
+const eventPrefix = "event:"
+
+// LoggedEventKey 키 순서가 번호 순서와 같도록 번호를 0으로 채운다.
+func LoggedEventKey(id uint64) []byte {
+	return []byte(fmt.Sprintf("%v%020d", eventPrefix, id))
+}
+
+func (e *LoggedEvent) Key() []byte {
+	return LoggedEventKey(e.ID)
+}
+
+func (e *LoggedEvent) Value() []byte {
+	bytes, err := json.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+var loggedEventSchema = &schema{
+	prefix:     eventPrefix,
+	migrations: []migration{unversioned},
+	new: func() record {
+		return &LoggedEvent{} //nolint:exhaustruct
+	},
+}
+
+func (e *LoggedEvent) schema() *schema {
+	return loggedEventSchema
+}
+
+func (e *LoggedEvent) ToDomain() *domain.LoggedEvent {
+	return domain.NewLoggedEvent(e.ID, e.Topic, e.Payload, e.RecordedAt)
+}
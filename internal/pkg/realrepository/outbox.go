@@ -0,0 +1,103 @@
+package realrepository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/google/uuid"
+)
+
+type OutboxEvent struct {
+	SchemaVersion int `json:"schema_version"`
+
+	ID           string            `json:"id"`
+	Topic        string            `json:"topic"`
+	Version      int               `json:"version"`
+	OccurredAt   time.Time         `json:"occurred_at"`
+	Producer     string            `json:"producer,omitempty"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	Payload      []byte            `json:"payload"`
+}
+
+// NewOutboxEvent 봉투에 담기지 않은 이벤트는 발행자 정보 없이 담는다.
+// 봉투의 ID는 시간 순으로 정렬되는 UUIDv7이므로 키 순서가 기록 순서와 같다.
+func NewOutboxEvent(event domain.Event, now time.Time) *OutboxEvent {
+	envelope, ok := event.(*domain.Envelope)
+	if !ok {
+		envelope = domain.NewEnvelope(
+			uuid.Must(uuid.NewV7()).String(),
+			event.Topic(),
+			domain.EventVersion(event),
+			now,
+			"",
+			nil,
+			event.Payload(),
+		)
+	}
+	return &OutboxEvent{
+		SchemaVersion: outboxEventSchema.version(),
+
+		ID:           envelope.EventID(),
+		Topic:        envelope.Topic(),
+		Version:      envelope.Version(),
+		OccurredAt:   envelope.OccurredAt(),
+		Producer:     envelope.Producer(),
+		TraceContext: envelope.TraceContext(),
+		Payload:      envelope.Payload(),
+	}
+}
+
+const outboxPrefix = "outbox:"
+
+func OutboxEventKey(id string) []byte {
+	return []byte(outboxPrefix + id)
+}
+
+func (e *OutboxEvent) Key() []byte {
+	return OutboxEventKey(e.ID)
+}
+
+func (e *OutboxEvent) Value() []byte {
+	bytes, err := json.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+var outboxEventSchema = &schema{
+	prefix:     outboxPrefix,
+	migrations: []migration{migrateOutboxEventV0},
+	new: func() record {
+		return &OutboxEvent{} //nolint:exhaustruct
+	},
+}
+
+// migrateOutboxEventV0 봉투에 버전을 남기기 전에 기록한 이벤트는 버전 1이다.
+func migrateOutboxEventV0(fields map[string]any) ([]record, error) {
+	if _, ok := fields["version"]; !ok {
+		fields["version"] = 1
+	}
+	return nil, nil
+}
+
+func (e *OutboxEvent) schema() *schema {
+	return outboxEventSchema
+}
+
+func (e *OutboxEvent) ToDomain() *domain.Envelope {
+	return domain.NewEnvelope(e.ID, e.Topic, e.Version, e.OccurredAt, e.Producer, e.TraceContext, e.Payload)
+}
+
+// outboxMutations events를 outbox에 넣는 변경. 엔티티의 변경과 함께 Commit한다.
+func outboxMutations(events []domain.Event) []keyvalue.Mutation {
+	now := time.Now()
+	var ret []keyvalue.Mutation
+	for _, event := range events {
+		outboxEvent := NewOutboxEvent(event, now)
+		ret = append(ret, keyvalue.CreateMutation(outboxEvent.Key(), outboxEvent.Value()))
+	}
+	return ret
+}
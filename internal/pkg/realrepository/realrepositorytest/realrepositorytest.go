@@ -0,0 +1,24 @@
+// Package realrepositorytest 테스트에서 쓸 메모리 저장소를 만든다.
+package realrepositorytest
+
+import (
+	"testing"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/memory"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository"
+	"github.com/stretchr/testify/require"
+)
+
+// New 빈 메모리 저장소를 쓰는 Repository.
+func New(t *testing.T) *realrepository.Repository {
+	t.Helper()
+	return Open(t, memory.NewStore())
+}
+
+// Open store를 쓰는 Repository. 같은 store로 다시 열면 재시작을 흉내낼 수 있다.
+func Open(t *testing.T, store *memory.Store) *realrepository.Repository {
+	t.Helper()
+	ret, err := realrepository.NewRepository(store)
+	require.NoError(t, err)
+	return ret
+}
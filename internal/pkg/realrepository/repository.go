@@ -1,42 +1,57 @@
 package realrepository
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
-	"log"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
 	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
-	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/badger"
 	"github.com/pkg/errors"
 )
 
 var _ coinrepository.CoinRepository = (*Repository)(nil)
 
+// Repository kv를 닫는 것은 kv를 만든 쪽이 맡는다.
 type Repository struct {
-	kv *badger.Store
+	kv       keyvalue.Store
+	tradesMu sync.Mutex // 캔들은 읽고 비교한 뒤 저장하므로 동시에 저장하지 않는다.
+
+	eventsMu    sync.Mutex // 이벤트 번호를 차례로 매긴다.
+	lastEventID uint64
 }
 
-func NewRepository(path string) *Repository {
-	kv, err := badger.NewStore(path)
+// NewRepository 기록된 이벤트의 마지막 번호를 읽어 다음 번호를 이어서 매긴다.
+func NewRepository(kv keyvalue.Store) (*Repository, error) {
+	ret := &Repository{
+		kv:          kv,
+		tradesMu:    sync.Mutex{},
+		eventsMu:    sync.Mutex{},
+		lastEventID: 0,
+	}
+	events, err := ret.listLoggedEvents()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return &Repository{kv: kv}
-}
-
-func (r *Repository) Close() {
-	r.kv.Close()
+	if len(events) > 0 {
+		ret.lastEventID = events[len(events)-1].ID
+	}
+	return ret, nil
 }
 
 // CreateCoin implements coinrepository.CoinRepository.
-func (r *Repository) CreateCoin(_ context.Context, domainCoin *domain.Coin) (*domain.Coin, error) {
+func (r *Repository) CreateCoin(
+	_ context.Context,
+	domainCoin *domain.Coin,
+	events ...domain.Event,
+) (*domain.Coin, error) {
 	coin := NewCoin(domainCoin)
-	err := r.kv.Create(coin.Key(), coin.Value())
+	err := r.kv.Commit(append(outboxMutations(events), keyvalue.CreateMutation(coin.Key(), coin.Value()))...)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, translate(err, coinrepository.ErrCoinNotFound, coinrepository.ErrCoinAlreadyExists)
 	}
 	return domainCoin, nil
 }
@@ -50,9 +65,9 @@ func (r *Repository) ListCoins(_ context.Context) ([]*domain.Coin, error) {
 	}
 	for _, item := range items {
 		var coin Coin
-		err := json.Unmarshal(item, &coin)
+		_, err := decode(item, &coin)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
 		}
 		coins = append(coins, &coin)
 	}
@@ -68,85 +83,273 @@ func (r *Repository) GetCoin(_ context.Context, coinID domain.CoinID) (*domain.C
 	key := CoinKey(coinID)
 	item, err := r.kv.Get(key)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, translate(err, coinrepository.ErrCoinNotFound, coinrepository.ErrCoinAlreadyExists)
 	}
 	var ret Coin
-	err = json.Unmarshal(item, &ret)
+	_, err = decode(item, &ret)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
 	return ret.ToDomain(), nil
 }
 
 // UpdateCoin implements coinrepository.CoinRepository.
-func (r *Repository) UpdateCoin(_ context.Context, domainCoin *domain.Coin) (*domain.Coin, error) {
+func (r *Repository) UpdateCoin(
+	_ context.Context,
+	domainCoin *domain.Coin,
+	events ...domain.Event,
+) (*domain.Coin, error) {
 	coin := NewCoin(domainCoin)
-	err := r.kv.Update(coin.Key(), coin.Value())
+	err := r.kv.Commit(append(outboxMutations(events), keyvalue.UpdateMutation(coin.Key(), coin.Value()))...)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, translate(err, coinrepository.ErrCoinNotFound, coinrepository.ErrCoinAlreadyExists)
 	}
 	return domainCoin, nil
 }
 
 // DeleteCoin implements coinrepository.CoinRepository.
-func (r *Repository) DeleteCoin(_ context.Context, domainCoin *domain.Coin) error {
+func (r *Repository) DeleteCoin(_ context.Context, domainCoin *domain.Coin, events ...domain.Event) error {
 	coin := NewCoin(domainCoin)
-	err := r.kv.Delete(coin.Key())
+	err := r.kv.Commit(append(outboxMutations(events), keyvalue.DeleteMutation(coin.Key()))...)
 	if err != nil {
-		return errors.WithStack(err)
+		return translate(err, coinrepository.ErrCoinNotFound, coinrepository.ErrCoinAlreadyExists)
 	}
 	return nil
 }
 
-// SaveTrades implements coinrepository.CoinRepository.
-func (r *Repository) SaveTrades(_ context.Context, domainTrades *domain.Trades) error {
-	trades := NewTrades(domainTrades.CoinID(), domainTrades.ModifiedAt(), domainTrades.Trades())
-	err := r.kv.Update(trades.Key(), trades.Value())
+// SaveCoins implements coinrepository.CoinRepository.
+func (r *Repository) SaveCoins(_ context.Context, changes *coinrepository.CoinChanges, events ...domain.Event) error {
+	mutations := outboxMutations(events)
+	for _, coin := range changes.Created {
+		coin := NewCoin(coin)
+		mutations = append(mutations, keyvalue.CreateMutation(coin.Key(), coin.Value()))
+	}
+	for _, coin := range changes.Updated {
+		coin := NewCoin(coin)
+		mutations = append(mutations, keyvalue.UpdateMutation(coin.Key(), coin.Value()))
+	}
+	for _, coin := range changes.Deleted {
+		mutations = append(mutations, keyvalue.DeleteMutation(CoinKey(coin.ID())))
+	}
+	err := r.kv.Commit(mutations...)
 	if err != nil {
-		if errors.Is(err, keyvalue.ErrKeyNotFound) {
-			err := r.kv.Create(trades.Key(), trades.Value())
+		return translate(err, coinrepository.ErrCoinNotFound, coinrepository.ErrCoinAlreadyExists)
+	}
+	return nil
+}
+
+// SaveTrades implements coinrepository.CoinRepository.
+// 캔들마다 따로 저장하며, 내용이 같은 캔들은 다시 쓰지 않는다. 모든 캔들을 한 트랜잭션으로 저장한다.
+func (r *Repository) SaveTrades(_ context.Context, domainTrades *domain.Trades) ([]*domain.TradeChange, error) {
+	r.tradesMu.Lock()
+	defer r.tradesMu.Unlock()
+
+	coinID := domainTrades.CoinID()
+	interval := domainTrades.Interval()
+	var changes []*domain.TradeChange
+	err := r.kv.Txn(func(txn keyvalue.Txn) error {
+		for _, domainTrade := range domainTrades.Trades() {
+			change, err := saveTrade(txn, NewTrade(coinID, interval, domainTrade))
 			if err != nil {
-				return errors.WithStack(err)
+				return err
 			}
-			return nil
+			if change != nil {
+				changes = append(changes, change)
+			}
+		}
+		trades := NewTrades(domainTrades)
+		_, version, err := txn.Get(trades.Key())
+		switch {
+		case errors.Is(err, keyvalue.ErrKeyNotFound), err == nil && len(changes) > 0:
+			return txn.CompareAndSwap(trades.Key(), version, trades.Value())
+		default:
+			return err
 		}
-		return errors.WithStack(err)
+	})
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrTradesNotFound, coinrepository.ErrConflict)
 	}
-	return nil
+	return changes, nil
+}
+
+// saveTrade 캔들이 없으면 추가하고, 내용이 다르면 갱신한다. 바뀐 것이 없으면 nil을 반환한다.
+func saveTrade(txn keyvalue.Txn, trade *Trade) (*domain.TradeChange, error) {
+	item, version, err := txn.Get(trade.Key())
+	if errors.Is(err, keyvalue.ErrKeyNotFound) {
+		err := txn.CompareAndSwap(trade.Key(), 0, trade.Value())
+		if err != nil {
+			return nil, err
+		}
+		return domain.NewTradeChange(domain.TradeChangeCreated, trade.ToDomain()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored Trade
+	_, err = decode(item, &stored)
+	if err != nil {
+		return nil, err
+	}
+	if stored.ToDomain().Equal(trade.ToDomain()) {
+		return nil, nil //nolint:nilnil
+	}
+	err = txn.CompareAndSwap(trade.Key(), version, trade.Value())
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTradeChange(domain.TradeChangeUpdated, trade.ToDomain()), nil
 }
 
 // ListTrades implements coinrepository.CoinRepository.
-func (r *Repository) ListTrades(_ context.Context, id domain.CoinID) (*domain.Trades, error) {
-	key := NewTrades(id, time.Time{}, nil)
-	item, err := r.kv.Get(key.Key())
+func (r *Repository) ListTrades(
+	_ context.Context,
+	id domain.CoinID,
+	interval domain.Interval,
+) (*domain.Trades, error) {
+	trades, err := r.getTrades(id, interval)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.kv.List(TradePrefix(id, interval))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	var trades Trades
-	err = json.Unmarshal(item, &trades)
+	var tradeItems []*Trade
+	for _, item := range items {
+		var trade Trade
+		_, err := decode(item, &trade)
+		if err != nil {
+			return nil, err
+		}
+		tradeItems = append(tradeItems, &trade)
+	}
+	return trades.ToDomain(tradeItems), nil
+}
+
+// ListTradesInRange implements coinrepository.CoinRepository.
+// 키가 일시순으로 정렬되므로 키만 읽어 범위를 고르고, 고른 캔들만 읽는다.
+func (r *Repository) ListTradesInRange(
+	_ context.Context,
+	id domain.CoinID,
+	interval domain.Interval,
+	tradeRange coinrepository.TradeRange,
+) (*domain.Trades, error) {
+	trades, err := r.getTrades(id, interval)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := r.kv.Keys(TradePrefix(id, interval))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	ret := trades.ToDomain()
-	return ret, nil
+	var tradeItems []*Trade
+	for _, key := range tradeKeysInRange(id, interval, keys, tradeRange) {
+		item, err := r.kv.Get(key)
+		if errors.Is(err, keyvalue.ErrKeyNotFound) {
+			continue // 키를 읽은 뒤에 지워졌다.
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var trade Trade
+		_, err = decode(item, &trade)
+		if err != nil {
+			return nil, err
+		}
+		tradeItems = append(tradeItems, &trade)
+	}
+	return trades.ToDomain(tradeItems), nil
+}
+
+func (r *Repository) getTrades(id domain.CoinID, interval domain.Interval) (*Trades, error) {
+	item, err := r.kv.Get(TradesKey(id, interval))
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrTradesNotFound, coinrepository.ErrConflict)
+	}
+	var trades Trades
+	_, err = decode(item, &trades)
+	if err != nil {
+		return nil, err
+	}
+	return &trades, nil
+}
+
+// tradeKeysInRange keys는 키 순서, 곧 일시순이다.
+func tradeKeysInRange(
+	id domain.CoinID,
+	interval domain.Interval,
+	keys [][]byte,
+	tradeRange coinrepository.TradeRange,
+) [][]byte {
+	if !tradeRange.From.IsZero() {
+		from := TradeKey(id, interval, tradeRange.From)
+		keys = slices.DeleteFunc(keys, func(key []byte) bool { return bytes.Compare(key, from) < 0 })
+	}
+	if !tradeRange.To.IsZero() {
+		to := TradeKey(id, interval, tradeRange.To)
+		keys = slices.DeleteFunc(keys, func(key []byte) bool { return bytes.Compare(key, to) > 0 })
+	}
+	if tradeRange.Limit > 0 && len(keys) > tradeRange.Limit {
+		keys = keys[len(keys)-tradeRange.Limit:]
+	}
+	return keys
 }
 
 // DeleteTrades implements coinrepository.CoinRepository.
-func (r *Repository) DeleteTrades(_ context.Context, id domain.CoinID) error {
-	key := NewTrades(id, time.Time{}, nil)
-	err := r.kv.Delete(key.Key())
+// 설정에서 뺀 단위의 캔들도 지우도록 코인의 키를 모두 지운다. 캔들이 많으면 한 트랜잭션에 담기지 않으므로
+// 나눠서 지우며, 목록을 먼저 지워 중간에 멈춰도 찾을 수 없게 한다.
+func (r *Repository) DeleteTrades(_ context.Context, id domain.CoinID) ([]domain.Interval, error) {
+	r.tradesMu.Lock()
+	defer r.tradesMu.Unlock()
+
+	items, err := r.kv.List(coinTradesPrefix(id))
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
-	return nil
+	var ret []domain.Interval
+	for _, item := range items {
+		var trades Trades
+		_, err := decode(item, &trades)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, trades.Interval)
+	}
+	keys, err := r.kv.Keys(coinTradePrefix(id))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = r.kv.WriteBatch(func(batch keyvalue.Batch) error {
+		for _, interval := range ret {
+			err := batch.Delete(TradesKey(id, interval))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		for _, key := range keys {
+			err := batch.Delete(key)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrTradesNotFound, coinrepository.ErrConflict)
+	}
+	return ret, nil
 }
 
 // CreateBannedCoin implements coinrepository.CoinRepository.
-func (r *Repository) CreateBannedCoin(_ context.Context, bannedCoin *domain.BannedCoin) (*domain.BannedCoin, error) {
+func (r *Repository) CreateBannedCoin(
+	_ context.Context,
+	bannedCoin *domain.BannedCoin,
+	events ...domain.Event,
+) (*domain.BannedCoin, error) {
 	coin := NewBannedCoin(bannedCoin)
-	err := r.kv.Create(coin.Key(), coin.Value())
+	err := r.kv.Commit(append(outboxMutations(events), keyvalue.CreateMutation(coin.Key(), coin.Value()))...)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, translate(err, coinrepository.ErrBannedCoinNotFound, coinrepository.ErrBannedCoinAlreadyExists)
 	}
 	return bannedCoin, nil
 }
@@ -160,9 +363,9 @@ func (r *Repository) ListBannedCoins(_ context.Context) ([]*domain.BannedCoin, e
 	}
 	for _, item := range items {
 		var coin BannedCoin
-		err := json.Unmarshal(item, &coin)
+		_, err := decode(item, &coin)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, err
 		}
 		coins = append(coins, &coin)
 	}
@@ -178,24 +381,222 @@ func (r *Repository) GetBannedCoin(_ context.Context, coinID domain.CoinID) (*do
 	key := BannedCoinKey(coinID)
 	item, err := r.kv.Get(key)
 	if err != nil {
+		return nil, translate(err, coinrepository.ErrBannedCoinNotFound, coinrepository.ErrBannedCoinAlreadyExists)
+	}
+	var ret BannedCoin
+	_, err = decode(item, &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret.ToDomain(), nil
+}
+
+// DeleteBannedCoin implements coinrepository.CoinRepository.
+func (r *Repository) DeleteBannedCoin(
+	_ context.Context,
+	bannedCoin *domain.BannedCoin,
+	events ...domain.Event,
+) error {
+	err := r.kv.Commit(append(outboxMutations(events), keyvalue.DeleteMutation(BannedCoinKey(bannedCoin.CoinID())))...)
+	if err != nil {
+		return translate(err, coinrepository.ErrBannedCoinNotFound, coinrepository.ErrBannedCoinAlreadyExists)
+	}
+	return nil
+}
+
+// CreateAllowedCoin implements coinrepository.CoinRepository.
+func (r *Repository) CreateAllowedCoin(
+	_ context.Context,
+	allowedCoin *domain.AllowedCoin,
+) (*domain.AllowedCoin, error) {
+	coin := NewAllowedCoin(allowedCoin)
+	err := r.kv.Create(coin.Key(), coin.Value())
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrAllowedCoinNotFound, coinrepository.ErrAllowedCoinAlreadyExists)
+	}
+	return allowedCoin, nil
+}
+
+// ListAllowedCoins implements coinrepository.CoinRepository.
+func (r *Repository) ListAllowedCoins(_ context.Context) ([]*domain.AllowedCoin, error) {
+	items, err := r.kv.List([]byte(allowedCoinPrefix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var ret []*domain.AllowedCoin
+	for _, item := range items {
+		var coin AllowedCoin
+		_, err := decode(item, &coin)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, coin.ToDomain())
+	}
+	return ret, nil
+}
+
+// GetAllowedCoin implements coinrepository.CoinRepository.
+func (r *Repository) GetAllowedCoin(_ context.Context, coinID domain.CoinID) (*domain.AllowedCoin, error) {
+	item, err := r.kv.Get(AllowedCoinKey(coinID))
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrAllowedCoinNotFound, coinrepository.ErrAllowedCoinAlreadyExists)
+	}
+	var ret AllowedCoin
+	_, err = decode(item, &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret.ToDomain(), nil
+}
+
+// DeleteAllowedCoin implements coinrepository.CoinRepository.
+func (r *Repository) DeleteAllowedCoin(_ context.Context, allowedCoin *domain.AllowedCoin) error {
+	err := r.kv.Delete(AllowedCoinKey(allowedCoin.CoinID()))
+	if err != nil {
+		return translate(err, coinrepository.ErrAllowedCoinNotFound, coinrepository.ErrAllowedCoinAlreadyExists)
+	}
+	return nil
+}
+
+// AppendEvent implements coinrepository.CoinRepository.
+func (r *Repository) AppendEvent(
+	_ context.Context,
+	event domain.Event,
+	recordedAt time.Time,
+) (*domain.LoggedEvent, error) {
+	r.eventsMu.Lock()
+	defer r.eventsMu.Unlock()
+	ret := domain.NewLoggedEvent(r.lastEventID+1, event.Topic(), event.Payload(), recordedAt)
+	loggedEvent := NewLoggedEvent(ret)
+	err := r.kv.Txn(func(txn keyvalue.Txn) error {
+		err := txn.Create(loggedEvent.Key(), loggedEvent.Value())
+		if err != nil {
+			return err
+		}
+		if ret.ID() <= coinrepository.EventLogSize {
+			return nil
+		}
+		err = txn.Delete(LoggedEventKey(ret.ID() - coinrepository.EventLogSize))
 		if errors.Is(err, keyvalue.ErrKeyNotFound) {
-			return nil, coinrepository.ErrBannedCoinNotFound
+			return nil
 		}
+		return err
+	})
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrNotFound, coinrepository.ErrAlreadyExists)
+	}
+	r.lastEventID = ret.ID()
+	return ret, nil
+}
+
+// ListEventsAfter implements coinrepository.CoinRepository.
+func (r *Repository) ListEventsAfter(_ context.Context, id uint64) ([]*domain.LoggedEvent, error) {
+	events, err := r.listLoggedEvents()
+	if err != nil {
+		return nil, err
+	}
+	var ret []*domain.LoggedEvent
+	for _, event := range events {
+		if event.ID <= id {
+			continue
+		}
+		ret = append(ret, event.ToDomain())
+	}
+	return ret, nil
+}
+
+// listLoggedEvents 키 순서, 즉 번호 순서로 읽는다.
+func (r *Repository) listLoggedEvents() ([]*LoggedEvent, error) {
+	items, err := r.kv.List([]byte(eventPrefix))
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	var ret BannedCoin
-	err = json.Unmarshal(item, &ret)
+	ret := make([]*LoggedEvent, 0, len(items))
+	for _, item := range items {
+		var event LoggedEvent
+		_, err := decode(item, &event)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &event)
+	}
+	return ret, nil
+}
+
+// ListOutboxEvents implements coinrepository.CoinRepository.
+func (r *Repository) ListOutboxEvents(_ context.Context, limit int) ([]*domain.Envelope, error) {
+	items, err := r.kv.List([]byte(outboxPrefix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var ret []*domain.Envelope
+	for _, item := range items[:min(limit, len(items))] {
+		var event OutboxEvent
+		_, err := decode(item, &event)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, event.ToDomain())
+	}
+	return ret, nil
+}
+
+// DeleteOutboxEvent implements coinrepository.CoinRepository. 이미 지워졌다면 무시한다.
+func (r *Repository) DeleteOutboxEvent(_ context.Context, id string) error {
+	err := r.kv.Delete(OutboxEventKey(id))
+	if err != nil && !errors.Is(err, keyvalue.ErrKeyNotFound) {
+		return translate(err, coinrepository.ErrNotFound, coinrepository.ErrAlreadyExists)
+	}
+	return nil
+}
+
+// CreateDeadLetter implements coinrepository.CoinRepository.
+func (r *Repository) CreateDeadLetter(_ context.Context, domainDeadLetter *domain.DeadLetter) error {
+	deadLetter := NewDeadLetter(domainDeadLetter)
+	err := r.kv.Create(deadLetter.Key(), deadLetter.Value())
+	if err != nil {
+		return translate(err, coinrepository.ErrDeadLetterNotFound, coinrepository.ErrDeadLetterAlreadyExists)
+	}
+	return nil
+}
+
+// ListDeadLetters implements coinrepository.CoinRepository.
+func (r *Repository) ListDeadLetters(_ context.Context) ([]*domain.DeadLetter, error) {
+	items, err := r.kv.List([]byte(deadLetterPrefix))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	var ret []*domain.DeadLetter
+	for _, item := range items {
+		var deadLetter DeadLetter
+		_, err := decode(item, &deadLetter)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, deadLetter.ToDomain())
+	}
+	return ret, nil
+}
+
+// GetDeadLetter implements coinrepository.CoinRepository.
+func (r *Repository) GetDeadLetter(_ context.Context, id string) (*domain.DeadLetter, error) {
+	item, err := r.kv.Get(DeadLetterKey(id))
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrDeadLetterNotFound, coinrepository.ErrDeadLetterAlreadyExists)
+	}
+	var ret DeadLetter
+	_, err = decode(item, &ret)
+	if err != nil {
+		return nil, err
+	}
 	return ret.ToDomain(), nil
 }
 
-// DeleteBannedCoin implements coinrepository.CoinRepository.
-func (r *Repository) DeleteBannedCoin(_ context.Context, bannedCoin *domain.BannedCoin) error {
-	err := r.kv.Delete(BannedCoinKey(bannedCoin.CoinID()))
+// DeleteDeadLetter implements coinrepository.CoinRepository.
+func (r *Repository) DeleteDeadLetter(_ context.Context, id string) error {
+	err := r.kv.Delete(DeadLetterKey(id))
 	if err != nil {
-		return errors.WithStack(err)
+		return translate(err, coinrepository.ErrDeadLetterNotFound, coinrepository.ErrDeadLetterAlreadyExists)
 	}
 	return nil
 }
@@ -5,18 +5,45 @@ import (
 	"testing"
 	"time"
 
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository/coinrepositorytest"
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/badger"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalues/memory"
 	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/realrepository/realrepositorytest"
 	"github.com/stretchr/testify/require"
 )
 
+func TestRepository(t *testing.T) {
+	t.Parallel()
+	coinrepositorytest.Run(t, func(t *testing.T) coinrepository.CoinRepository {
+		return realrepositorytest.New(t)
+	})
+}
+
+func TestRepository_Badger(t *testing.T) {
+	t.Parallel()
+	coinrepositorytest.Run(t, func(t *testing.T) coinrepository.CoinRepository {
+		t.Helper()
+		store, err := badger.NewStore(t.TempDir())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			store.Close()
+		})
+		repo, err := realrepository.NewRepository(store)
+		require.NoError(t, err)
+		return repo
+	})
+}
+
 func TestRepository_CreateCoin(t *testing.T) {
 	t.Parallel()
-	dir := t.TempDir()
-	repo := realrepository.NewRepository(dir)
+	repo := realrepositorytest.New(t)
 	now := time.Now()
 	ctx := context.Background()
-	domainCoin := domain.NewCoin("A", false, now)
+	domainCoin := domain.NewCoin("A", now)
 
 	ret, err := repo.CreateCoin(ctx, domainCoin)
 
@@ -28,11 +55,12 @@ func TestRepository_CreateCoin(t *testing.T) {
 
 func TestRepository_ListCoins(t *testing.T) {
 	t.Parallel()
-	dir := t.TempDir()
-	repo := realrepository.NewRepository(dir)
+	repo := realrepositorytest.New(t)
 	now := time.Now()
 	ctx := context.Background()
-	domainCoin := domain.NewCoin("A", false, now)
+	domainCoin := domain.NewCoin("A", now).
+		SetNames("에이", "A coin").
+		SetCautions(domain.CautionTradingVolumeSoaring, domain.CautionPriceFluctuations)
 	_, _ = repo.CreateCoin(ctx, domainCoin)
 
 	coins, err := repo.ListCoins(ctx)
@@ -40,6 +68,245 @@ func TestRepository_ListCoins(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, coins, 1)
 	require.Equal(t, domain.CoinID("A"), coins[0].ID())
-	require.False(t, coins[0].IsDanger())
+	require.True(t, coins[0].IsDanger())
+	require.Empty(t, coins[0].Changes(domainCoin))
 	require.Equal(t, now.Unix(), coins[0].ModifiedAt().Unix())
 }
+
+func TestRepository_GetBannedCoin(t *testing.T) {
+	t.Parallel()
+	repo := realrepositorytest.New(t)
+	now := time.Now()
+	ctx := context.Background()
+	reasons := []domain.BanReason{domain.BanReasonNotEnoughTrades, domain.BanReasonPriceTooLow}
+	_, _ = repo.CreateBannedCoin(ctx, domain.NewBannedCoin("A", now, time.Hour, reasons))
+
+	bannedCoin, err := repo.GetBannedCoin(ctx, "A")
+
+	require.NoError(t, err)
+	require.Equal(t, domain.CoinID("A"), bannedCoin.CoinID())
+	require.Equal(t, time.Hour, bannedCoin.Period())
+	require.Equal(t, reasons, bannedCoin.Reasons())
+}
+
+func TestRepository_ListTrades(t *testing.T) {
+	t.Parallel()
+	repo := realrepositorytest.New(t)
+	ctx := context.Background()
+	date := time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)
+	trade := domain.NewTrade(date, "110", "100", "120", "90").
+		SetVolume("1.5", "165").
+		SetChange("100", "10", "0.1")
+	_, _ = repo.SaveTrades(ctx, domain.NewTrades("A", domain.IntervalDay, date, []*domain.Trade{trade}))
+
+	trades, err := repo.ListTrades(ctx, "A", domain.IntervalDay)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, trades.Size())
+	require.Equal(t, trade, trades.Trades()[0])
+}
+
+func TestRepository_SaveTrades(t *testing.T) {
+	t.Parallel()
+	repo := realrepositorytest.New(t)
+	ctx := context.Background()
+	yesterday := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)
+	_, _ = repo.SaveTrades(ctx, domain.NewTrades("A", domain.IntervalDay, today, []*domain.Trade{
+		domain.NewTrade(yesterday, "100", "100", "100", "100"),
+		domain.NewTrade(today, "100", "100", "100", "100"),
+	}))
+
+	changes, err := repo.SaveTrades(ctx, domain.NewTrades("A", domain.IntervalDay, today, []*domain.Trade{
+		domain.NewTrade(yesterday, "100", "100", "100", "100"),
+		domain.NewTrade(today, "110", "100", "110", "100"),
+		domain.NewTrade(today.AddDate(0, 0, 1), "110", "110", "110", "110"),
+	}))
+
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, domain.TradeChangeUpdated, changes[0].Kind())
+	require.Equal(t, today, changes[0].Date())
+	require.Equal(t, domain.TradeChangeCreated, changes[1].Kind())
+	trades, err := repo.ListTrades(ctx, "A", domain.IntervalDay)
+	require.NoError(t, err)
+	require.Equal(t, 3, trades.Size())
+	require.Equal(t, domain.Price("110"), trades.LastPrice())
+}
+
+func TestRepository_DeleteTrades(t *testing.T) {
+	t.Parallel()
+	repo := realrepositorytest.New(t)
+	ctx := context.Background()
+	today := time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)
+	for _, coinID := range []domain.CoinID{"A", "AB"} {
+		for _, interval := range []domain.Interval{domain.IntervalDay, domain.IntervalWeek} {
+			_, _ = repo.SaveTrades(ctx, domain.NewTrades(coinID, interval, today, []*domain.Trade{
+				domain.NewTrade(today, "100", "100", "100", "100"),
+			}))
+		}
+	}
+
+	intervals, err := repo.DeleteTrades(ctx, "A")
+
+	require.NoError(t, err)
+	require.ElementsMatch(t, []domain.Interval{domain.IntervalDay, domain.IntervalWeek}, intervals)
+	_, err = repo.ListTrades(ctx, "A", domain.IntervalWeek)
+	require.ErrorIs(t, err, coinrepository.ErrTradesNotFound)
+	trades, err := repo.ListTrades(ctx, "AB", domain.IntervalWeek)
+	require.NoError(t, err)
+	require.Equal(t, 1, trades.Size())
+}
+
+func TestRepository_AppendEvent(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	repo := realrepositorytest.Open(t, store)
+	ctx := context.Background()
+	now := time.Now()
+	for range 1003 {
+		_, err := repo.AppendEvent(ctx, domain.NewCoinCreatedEvent(now, "upbit:KRW-A"), now)
+		require.NoError(t, err)
+	}
+	repo = realrepositorytest.Open(t, store)
+
+	event, err := repo.AppendEvent(ctx, domain.NewCoinDeletedEvent(now, "upbit:KRW-A"), now)
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(1004), event.ID())
+	events, err := repo.ListEventsAfter(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1000)
+	require.Equal(t, uint64(5), events[0].ID())
+	events, err = repo.ListEventsAfter(ctx, 1003)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, domain.CoinDeletedEventTopic, events[0].Topic())
+}
+
+func TestRepository_Errors(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	repo := realrepositorytest.Open(t, store)
+	ctx := context.Background()
+	coin := domain.NewCoin("A", time.Now())
+	_, _ = repo.CreateCoin(ctx, coin)
+
+	_, err := repo.GetCoin(ctx, "B")
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	require.ErrorIs(t, err, coinrepository.ErrNotFound)
+	_, err = repo.UpdateCoin(ctx, domain.NewCoin("B", time.Now()))
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	_, err = repo.CreateCoin(ctx, coin)
+	require.ErrorIs(t, err, coinrepository.ErrCoinAlreadyExists)
+	require.ErrorIs(t, err, coinrepository.ErrAlreadyExists)
+	err = store.Update(realrepository.CoinKey("A"), []byte(`{"id":`))
+	require.NoError(t, err)
+
+	_, err = repo.GetCoin(ctx, "A")
+	require.ErrorIs(t, err, coinrepository.ErrCorrupt)
+}
+
+// baselineRecords 버전을 남기기 전의 저장 형식. 당시 코드가 쓴 그대로이다.
+var baselineRecords = map[string]string{
+	"coin:KRW-BTC":         `{"id":"KRW-BTC","danger":true,"modified_at":"2025-01-21T09:30:00+09:00"}`,
+	"banned_coin:KRW-DOGE": `{"id":"KRW-DOGE","banned_at":"2025-01-21T09:30:00+09:00","period":86400000000000}`,
+	"trades:KRW-BTC": `{"coin_id":"KRW-BTC","modified_at":"2025-01-21T09:30:00+09:00","trades":[` +
+		`{"date":"2025-01-20T09:00:00+09:00","last_price":"150000000","opening_price":"148000000",` +
+		`"max_price":"151000000","min_price":"147000000"},` +
+		`{"date":"2025-01-21T09:00:00+09:00","last_price":"152000000","opening_price":"150000000",` +
+		`"max_price":"153000000","min_price":"149500000"}]}`,
+}
+
+func TestRepository_Migrate(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	for key, value := range baselineRecords {
+		err := store.Create([]byte(key), []byte(value))
+		require.NoError(t, err)
+	}
+	repo := realrepositorytest.Open(t, store)
+	ctx := context.Background()
+	results, err := repo.Migrate(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, 3, upgraded(results))
+	for key, value := range baselineRecords {
+		item, err := store.Get([]byte(key))
+		require.NoError(t, err)
+		require.Equal(t, value, string(item))
+	}
+
+	_, err = repo.Migrate(ctx, false)
+
+	require.NoError(t, err)
+	for key := range baselineRecords {
+		_, err := store.Get([]byte(key))
+		require.ErrorIs(t, err, keyvalue.ErrKeyNotFound)
+	}
+	coin, err := repo.GetCoin(ctx, "upbit:KRW-BTC")
+	require.NoError(t, err)
+	require.True(t, coin.IsWarning())
+	trades, err := repo.ListTrades(ctx, "upbit:KRW-BTC", domain.IntervalDay)
+	require.NoError(t, err)
+	require.Equal(t, 2, trades.Size())
+	require.Equal(t, domain.Price("152000000"), trades.LastPrice())
+	bannedCoin, err := repo.GetBannedCoin(ctx, "upbit:KRW-DOGE")
+	require.NoError(t, err)
+	require.Equal(t, 24*time.Hour, bannedCoin.Period())
+	results, err = repo.Migrate(ctx, true)
+	require.NoError(t, err)
+	require.Zero(t, upgraded(results))
+}
+
+func TestRepository_ReadsDangerAsWarning(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	err := store.Create([]byte("coin:KRW-BTC"), []byte(baselineRecords["coin:KRW-BTC"]))
+	require.NoError(t, err)
+	repo := realrepositorytest.Open(t, store)
+
+	coins, err := repo.ListCoins(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, coins, 1)
+	require.Equal(t, domain.CoinID("upbit:KRW-BTC"), coins[0].ID())
+	require.True(t, coins[0].IsWarning())
+}
+
+func TestRepository_MigrateKeepsNewerRecord(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	err := store.Create([]byte("coin:KRW-BTC"), []byte(baselineRecords["coin:KRW-BTC"]))
+	require.NoError(t, err)
+	repo := realrepositorytest.Open(t, store)
+	ctx := context.Background()
+	_, _ = repo.CreateCoin(ctx, domain.NewCoin("upbit:KRW-BTC", time.Now()))
+
+	_, err = repo.Migrate(ctx, false)
+
+	require.NoError(t, err)
+	coins, err := repo.ListCoins(ctx)
+	require.NoError(t, err)
+	require.Len(t, coins, 1)
+	require.False(t, coins[0].IsWarning())
+}
+
+func upgraded(results []*realrepository.MigrationResult) int {
+	var ret int
+	for _, result := range results {
+		ret += result.Upgraded
+	}
+	return ret
+}
+
+func TestRepository_NewerSchemaVersion(t *testing.T) {
+	t.Parallel()
+	store := memory.NewStore()
+	repo := realrepositorytest.Open(t, store)
+	err := store.Create(realrepository.CoinKey("A"), []byte(`{"schema_version":99,"id":"A"}`))
+	require.NoError(t, err)
+
+	_, err = repo.GetCoin(context.Background(), "A")
+
+	require.ErrorIs(t, err, coinrepository.ErrCorrupt)
+}
@@ -0,0 +1,224 @@
+package realrepository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/keyvalue"
+	"github.com/pkg/errors"
+)
+
+// schemaVersionField 모든 레코드는 이 필드에 스키마 버전을 남긴다. 필드가 없으면 버전 0이다.
+const schemaVersionField = "schema_version"
+
+// record 저장소에 JSON으로 저장하는 레코드.
+type record interface {
+	Key() []byte
+	Value() []byte
+	schema() *schema
+}
+
+// migration 버전 i인 레코드를 i+1로 고친다. 숫자는 json.Number로 들어온다.
+// fields는 그 자리에서 고치고, 다른 레코드로 나눠야 하면 나눈 레코드를 현재 버전으로 만들어 돌려준다.
+type migration func(fields map[string]any) ([]record, error)
+
+// schema 레코드 종류마다 하나씩 둔다. migrations[i]는 버전 i인 레코드를 i+1로 올리므로
+// 필드를 바꿀 때는 migrations 끝에 하나를 더하면 된다.
+type schema struct {
+	prefix     string
+	migrations []migration
+	new        func() record
+}
+
+func (s *schema) version() int {
+	return len(s.migrations)
+}
+
+// schemas migration 레지스트리. Migrate는 이 순서로 레코드를 올린다.
+var schemas = []*schema{
+	coinSchema,
+	tradesSchema,
+	tradeSchema,
+	bannedCoinSchema,
+	allowedCoinSchema,
+	loggedEventSchema,
+	outboxEventSchema,
+	deadLetterSchema,
+}
+
+// unversioned 필드가 버전을 남기기 전과 같은 레코드.
+func unversioned(map[string]any) ([]record, error) {
+	return nil, nil
+}
+
+// qualifyCoinID 거래소를 붙이기 전에 저장한 코인 ID는 업비트 마켓이다.
+func qualifyCoinID(field string) migration {
+	return func(fields map[string]any) ([]record, error) {
+		value, ok := fields[field]
+		if !ok {
+			return nil, nil
+		}
+		id, ok := value.(string)
+		if !ok {
+			return nil, errors.Errorf("%v is %v", field, value)
+		}
+		fields[field] = string(domain.ParseCoinID(id, ""))
+		return nil, nil
+	}
+}
+
+// decode item을 현재 버전으로 올려 v에 읽는다. 올렸다면 true를 반환한다.
+// 나눠진 레코드와 바뀐 키는 Migrate만 반영하며, 서비스는 시작할 때 Migrate를 부른다.
+func decode(item []byte, v record) (bool, error) {
+	_, upgraded, err := upgrade(item, v)
+	return upgraded, err
+}
+
+// upgrade decode와 같지만 migration이 나눈 레코드도 돌려준다. 더 새로운 버전으로 쓴 레코드는 읽을 수 없다.
+func upgrade(item []byte, v record) ([]record, bool, error) {
+	decoder := json.NewDecoder(bytes.NewReader(item))
+	decoder.UseNumber()
+	var fields map[string]any
+	err := decoder.Decode(&fields)
+	if err != nil {
+		return nil, false, corrupt(err)
+	}
+	version, err := schemaVersion(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	current := v.schema().version()
+	if version > current {
+		return nil, false, corrupt(errors.Errorf("%v schema version %v is newer than %v", v.schema().prefix, version, current))
+	}
+	if version == current {
+		err := json.Unmarshal(item, v)
+		if err != nil {
+			return nil, false, corrupt(err)
+		}
+		return nil, false, nil
+	}
+	var extra []record
+	for ; version < current; version++ {
+		records, err := v.schema().migrations[version](fields)
+		if err != nil {
+			return nil, false, corrupt(err)
+		}
+		extra = append(extra, records...)
+	}
+	fields[schemaVersionField] = current
+	item, err = json.Marshal(fields)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	err = json.Unmarshal(item, v)
+	if err != nil {
+		return nil, false, corrupt(err)
+	}
+	return extra, true, nil
+}
+
+func schemaVersion(fields map[string]any) (int, error) {
+	value, ok := fields[schemaVersionField]
+	if !ok {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, corrupt(errors.Errorf("%v is %v", schemaVersionField, value))
+	}
+	ret, err := strconv.Atoi(number.String())
+	if err != nil {
+		return 0, corrupt(err)
+	}
+	return ret, nil
+}
+
+// MigrationResult 레코드 종류별로 읽은 레코드 수와 현재 버전으로 올린 레코드 수.
+type MigrationResult struct {
+	Kind     string
+	Scanned  int
+	Upgraded int
+}
+
+// Migrate 이전 버전으로 저장된 레코드를 모두 현재 버전으로 다시 쓴다. dryRun이면 세기만 한다.
+// 키가 바뀌거나 나눠지는 레코드는 Migrate로만 옮겨지므로 서비스가 시작할 때 다른 일보다 먼저 부른다.
+func (r *Repository) Migrate(_ context.Context, dryRun bool) ([]*MigrationResult, error) {
+	var ret []*MigrationResult
+	for _, schema := range schemas {
+		result, err := r.migrate(schema, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, result)
+	}
+	return ret, nil
+}
+
+func (r *Repository) migrate(schema *schema, dryRun bool) (*MigrationResult, error) {
+	keys, err := r.kv.Keys([]byte(schema.prefix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ret := &MigrationResult{
+		Kind:     strings.TrimSuffix(schema.prefix, ":"),
+		Scanned:  len(keys),
+		Upgraded: 0,
+	}
+	for _, key := range keys {
+		upgraded, err := r.migrateRecord(schema, key, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if upgraded {
+			ret.Upgraded++
+		}
+	}
+	return ret, nil
+}
+
+// migrateRecord 레코드 하나를 한 트랜잭션으로 올린다. 키가 바뀌면 옛 키를 지운다.
+// 새 키에 이미 레코드가 있으면 그 레코드가 더 최근에 쓴 것이므로 남긴다.
+func (r *Repository) migrateRecord(schema *schema, key []byte, dryRun bool) (bool, error) {
+	var upgraded bool
+	err := r.kv.Txn(func(txn keyvalue.Txn) error {
+		item, _, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		stored := schema.new()
+		extra, ok, err := upgrade(item, stored)
+		if err != nil || !ok {
+			return err
+		}
+		upgraded = true
+		if dryRun {
+			return nil
+		}
+		if bytes.Equal(key, stored.Key()) {
+			err = txn.Update(key, stored.Value())
+		} else {
+			err = txn.Delete(key)
+			extra = append([]record{stored}, extra...)
+		}
+		if err != nil {
+			return err
+		}
+		for _, record := range extra {
+			err := txn.Create(record.Key(), record.Value())
+			if err != nil && !errors.Is(err, keyvalue.ErrKeyAlreadyExists) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, translate(err, coinrepository.ErrNotFound, coinrepository.ErrConflict)
+	}
+	return upgraded, nil
+}
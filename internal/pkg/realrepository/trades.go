@@ -5,28 +5,44 @@ import (
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
 )
 
+// Trades 코인의 캔들 단위별 정보. 캔들은 Trade로 하나씩 따로 저장한다.
 type Trades struct {
-	CoinID     domain.CoinID `json:"coin_id,omitempty"`
-	ModifiedAt time.Time     `json:"modified_at,omitempty"`
-	Trades     []*Trade      `json:"trades,omitempty"`
+	SchemaVersion int `json:"schema_version"`
+
+	CoinID     domain.CoinID   `json:"coin_id"`
+	Interval   domain.Interval `json:"interval"`
+	ModifiedAt time.Time       `json:"modified_at"`
 }
 
-func NewTrades(coinID domain.CoinID, modifiedAt time.Time, domainTrades []*domain.Trade) *Trades {
-	var trades []*Trade
-	for _, trade := range domainTrades {
-		trades = append(trades, NewTrade(trade))
+func NewTrades(domainTrades *domain.Trades) *Trades {
+	return &Trades{
+		SchemaVersion: tradesSchema.version(),
+
+		CoinID:     domainTrades.CoinID(),
+		Interval:   domainTrades.Interval(),
+		ModifiedAt: domainTrades.ModifiedAt(),
 	}
-	return &Trades{CoinID: coinID, ModifiedAt: modifiedAt, Trades: trades}
 }
 
 type Trade struct {
-	Date         time.Time `json:"date,omitempty"`
-	LastPrice    string    `json:"last_price,omitempty"`
-	OpeningPrice string    `json:"opening_price,omitempty"`
-	MaxPrice     string    `json:"max_price,omitempty"`
-	MinPrice     string    `json:"min_price,omitempty"`
+	SchemaVersion int `json:"schema_version"`
+
+	CoinID       domain.CoinID   `json:"coin_id"`
+	Interval     domain.Interval `json:"interval"`
+	Date         time.Time       `json:"date"`
+	LastPrice    string          `json:"last_price"`
+	OpeningPrice string          `json:"opening_price"`
+	MaxPrice     string          `json:"max_price"`
+	MinPrice     string          `json:"min_price"`
+
+	Volume           string `json:"volume"`
+	TradedValue      string `json:"traded_value"`
+	PrevClosingPrice string `json:"prev_closing_price"`
+	ChangePrice      string `json:"change_price"`
+	ChangeRate       string `json:"change_rate"`
 }
 
 func (t *Trade) ToDomain() *domain.Trade {
@@ -36,23 +52,56 @@ func (t *Trade) ToDomain() *domain.Trade {
 		domain.Price(t.OpeningPrice),
 		domain.Price(t.MaxPrice),
 		domain.Price(t.MinPrice),
+	).SetVolume(
+		domain.Volume(t.Volume),
+		domain.Price(t.TradedValue),
+	).SetChange(
+		domain.Price(t.PrevClosingPrice),
+		domain.Price(t.ChangePrice),
+		domain.Rate(t.ChangeRate),
 	)
 }
 
-func NewTrade(domainTrade *domain.Trade) *Trade {
+func NewTrade(coinID domain.CoinID, interval domain.Interval, domainTrade *domain.Trade) *Trade {
 	return &Trade{
+		SchemaVersion: tradeSchema.version(),
+
+		CoinID:       coinID,
+		Interval:     interval,
 		Date:         domainTrade.Date(),
 		LastPrice:    string(domainTrade.LastPrice()),
 		OpeningPrice: string(domainTrade.OpeningPrice()),
 		MaxPrice:     string(domainTrade.MaxPrice()),
 		MinPrice:     string(domainTrade.MinPrice()),
+
+		Volume:           string(domainTrade.Volume()),
+		TradedValue:      string(domainTrade.TradedValue()),
+		PrevClosingPrice: string(domainTrade.PrevClosingPrice()),
+		ChangePrice:      string(domainTrade.ChangePrice()),
+		ChangeRate:       string(domainTrade.ChangeRate()),
 	}
 }
 
-const tradesPrefix = "trades:"
+const (
+	tradesPrefix = "trades:"
+	tradePrefix  = "trade:"
+
+	// tradeDateLayout 키를 사전순으로 정렬하면 일시순이 되도록 고정 길이로 표현한다.
+	tradeDateLayout = "20060102T150405Z"
+)
+
+// coinTradesPrefix 코인의 모든 단위 캔들 목록.
+func coinTradesPrefix(coinID domain.CoinID) []byte {
+	return []byte(tradesPrefix + string(coinID) + ":")
+}
+
+// TradesKey 처음에는 trades:<coinID>에 일봉을 모아 저장했다. migrateTradesV0가 옮긴다.
+func TradesKey(coinID domain.CoinID, interval domain.Interval) []byte {
+	return []byte(tradesPrefix + string(coinID) + ":" + string(interval))
+}
 
 func (t *Trades) Key() []byte {
-	return []byte(tradesPrefix + string(t.CoinID))
+	return TradesKey(t.CoinID, t.Interval)
 }
 
 func (t *Trades) Value() []byte {
@@ -63,10 +112,95 @@ func (t *Trades) Value() []byte {
 	return bytes
 }
 
-func (t *Trades) ToDomain() *domain.Trades {
-	var trades []*domain.Trade
-	for _, trade := range t.Trades {
-		trades = append(trades, trade.ToDomain())
+var tradesSchema = &schema{
+	prefix:     tradesPrefix,
+	migrations: []migration{migrateTradesV0},
+	new: func() record {
+		return &Trades{} //nolint:exhaustruct
+	},
+}
+
+// migrateTradesV0 처음에는 코인마다 일봉을 trades 필드에 모아 trades:<coinID>에 저장했다.
+// 캔들은 trade 레코드로 나누고, 나머지는 일봉 목록으로 남긴다.
+func migrateTradesV0(fields map[string]any) ([]record, error) {
+	_, err := qualifyCoinID("coin_id")(fields)
+	if err != nil {
+		return nil, err
+	}
+	embedded, ok := fields["trades"]
+	if !ok {
+		return nil, nil
+	}
+	delete(fields, "trades")
+	fields["interval"] = string(domain.IntervalDay)
+	coinID, ok := fields["coin_id"].(string)
+	if !ok {
+		return nil, errors.Errorf("coin_id is %v", fields["coin_id"])
+	}
+	bytes, err := json.Marshal(embedded)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var trades []*Trade
+	err = json.Unmarshal(bytes, &trades)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ret := make([]record, 0, len(trades))
+	for _, trade := range trades {
+		trade.SchemaVersion = tradeSchema.version()
+		trade.CoinID = domain.CoinID(coinID)
+		trade.Interval = domain.IntervalDay
+		ret = append(ret, trade)
+	}
+	return ret, nil
+}
+
+func (t *Trades) schema() *schema {
+	return tradesSchema
+}
+
+func (t *Trades) ToDomain(trades []*Trade) *domain.Trades {
+	var domainTrades []*domain.Trade
+	for _, trade := range trades {
+		domainTrades = append(domainTrades, trade.ToDomain())
+	}
+	return domain.NewTrades(t.CoinID, t.Interval, t.ModifiedAt, domainTrades)
+}
+
+// coinTradePrefix 코인의 모든 단위 캔들.
+func coinTradePrefix(coinID domain.CoinID) []byte {
+	return []byte(tradePrefix + string(coinID) + ":")
+}
+
+func TradePrefix(coinID domain.CoinID, interval domain.Interval) []byte {
+	return []byte(tradePrefix + string(coinID) + ":" + string(interval) + ":")
+}
+
+func TradeKey(coinID domain.CoinID, interval domain.Interval, date time.Time) []byte {
+	return append(TradePrefix(coinID, interval), date.UTC().Format(tradeDateLayout)...)
+}
+
+func (t *Trade) Key() []byte {
+	return TradeKey(t.CoinID, t.Interval, t.Date)
+}
+
+func (t *Trade) Value() []byte {
+	bytes, err := json.Marshal(t)
+	if err != nil {
+		panic(err)
 	}
-	return domain.NewTrades(t.CoinID, t.ModifiedAt, trades)
+	return bytes
+}
+
+var tradeSchema = &schema{
+	prefix:     tradePrefix,
+	migrations: []migration{qualifyCoinID("coin_id")},
+	new: func() record {
+		return &Trade{} //nolint:exhaustruct
+	},
+}
+
+func (t *Trade) schema() *schema {
+	return tradeSchema
 }
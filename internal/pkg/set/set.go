@@ -34,6 +34,11 @@ func (s *Set[K, V]) ContainKey(key K) bool {
 	return ok
 }
 
+func (s *Set[K, V]) Get(key K) (V, bool) {
+	value, ok := s.items[key]
+	return value, ok
+}
+
 func (s *Set[K, V]) Intersection(other *Set[K, V]) *Set[K, V] {
 	ret := NewSet(s.fn)
 	for key, value := range s.items {
@@ -0,0 +1,34 @@
+package sqliterepository
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+func queryAllowedCoins(ctx context.Context, q querier, where string, args ...any) ([]*domain.AllowedCoin, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT coin_id, allowed_at, note FROM allowed_coins`+where+` ORDER BY coin_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.AllowedCoin
+	for rows.Next() {
+		var coinID, allowedAt, note string
+		err := rows.Scan(&coinID, &allowedAt, &note)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		allowed, err := parseTime(allowedAt)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, domain.NewAllowedCoin(domain.CoinID(coinID), allowed, note))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
@@ -0,0 +1,98 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+func insertBannedCoin(ctx context.Context, tx *sql.Tx, bannedCoin *domain.BannedCoin) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO banned_coins (coin_id, banned_at, period_ns, note) VALUES (?, ?, ?, ?)`,
+		string(bannedCoin.CoinID()), formatTime(bannedCoin.BannedAt()), int64(bannedCoin.Period()), bannedCoin.Note(),
+	)
+	if err != nil {
+		return translate(err, coinrepository.ErrBannedCoinNotFound, coinrepository.ErrBannedCoinAlreadyExists)
+	}
+	for position, reason := range bannedCoin.Reasons() {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO banned_coin_reasons (coin_id, position, reason) VALUES (?, ?, ?)`,
+			string(bannedCoin.CoinID()), position, string(reason),
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func deleteBannedCoin(ctx context.Context, tx *sql.Tx, coinID domain.CoinID) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM banned_coins WHERE coin_id = ?`, string(coinID))
+	return affected(result, err, coinrepository.ErrBannedCoinNotFound)
+}
+
+func queryBannedCoins(ctx context.Context, q querier, where string, args ...any) ([]*domain.BannedCoin, error) {
+	reasons, err := queryBanReasons(ctx, q, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT coin_id, banned_at, period_ns, note FROM banned_coins`+where+` ORDER BY coin_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.BannedCoin
+	for rows.Next() {
+		var coinID, bannedAt, note string
+		var period int64
+		err := rows.Scan(&coinID, &bannedAt, &period, &note)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		banned, err := parseTime(bannedAt)
+		if err != nil {
+			return nil, err
+		}
+		id := domain.CoinID(coinID)
+		ret = append(ret, domain.NewBannedCoin(id, banned, time.Duration(period), reasons[id]).SetNote(note))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
+
+func queryBanReasons(
+	ctx context.Context,
+	q querier,
+	where string,
+	args ...any,
+) (map[domain.CoinID][]domain.BanReason, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT coin_id, reason FROM banned_coin_reasons
+WHERE coin_id IN (SELECT coin_id FROM banned_coins`+where+`) ORDER BY coin_id, position`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	ret := map[domain.CoinID][]domain.BanReason{}
+	for rows.Next() {
+		var coinID, reason string
+		err := rows.Scan(&coinID, &reason)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		ret[domain.CoinID(coinID)] = append(ret[domain.CoinID(coinID)], domain.BanReason(reason))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
@@ -0,0 +1,119 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+const selectCoins = `SELECT id, korean_name, english_name, warning, modified_at FROM coins`
+
+func insertCoin(ctx context.Context, tx *sql.Tx, coin *domain.Coin) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO coins (id, korean_name, english_name, warning, modified_at) VALUES (?, ?, ?, ?, ?)`,
+		string(coin.ID()), coin.KoreanName(), coin.EnglishName(), coin.IsWarning(), formatTime(coin.ModifiedAt()),
+	)
+	if err != nil {
+		return translate(err, coinrepository.ErrCoinNotFound, coinrepository.ErrCoinAlreadyExists)
+	}
+	return insertCoinCautions(ctx, tx, coin)
+}
+
+func updateCoin(ctx context.Context, tx *sql.Tx, coin *domain.Coin) error {
+	result, err := tx.ExecContext(
+		ctx,
+		`UPDATE coins SET korean_name = ?, english_name = ?, warning = ?, modified_at = ? WHERE id = ?`,
+		coin.KoreanName(), coin.EnglishName(), coin.IsWarning(), formatTime(coin.ModifiedAt()), string(coin.ID()),
+	)
+	err = affected(result, err, coinrepository.ErrCoinNotFound)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM coin_cautions WHERE coin_id = ?`, string(coin.ID()))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return insertCoinCautions(ctx, tx, coin)
+}
+
+func insertCoinCautions(ctx context.Context, tx *sql.Tx, coin *domain.Coin) error {
+	for position, caution := range coin.Cautions() {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO coin_cautions (coin_id, position, caution) VALUES (?, ?, ?)`,
+			string(coin.ID()), position, string(caution),
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// deleteCoin 주의 항목은 ON DELETE CASCADE로 함께 지워진다.
+func deleteCoin(ctx context.Context, tx *sql.Tx, coinID domain.CoinID) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM coins WHERE id = ?`, string(coinID))
+	return affected(result, err, coinrepository.ErrCoinNotFound)
+}
+
+// queryCoins coins를 읽고 주의 항목을 붙인다. 주의 항목은 coin_cautions에서 같은 조건으로 읽는다.
+func queryCoins(ctx context.Context, q querier, where string, args ...any) ([]*domain.Coin, error) {
+	cautions, err := queryCoinCautions(ctx, q, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, selectCoins+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.Coin
+	for rows.Next() {
+		var id, koreanName, englishName, modifiedAt string
+		var warning bool
+		err := rows.Scan(&id, &koreanName, &englishName, &warning, &modifiedAt)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		modified, err := parseTime(modifiedAt)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, domain.NewCoin(domain.CoinID(id), modified).
+			SetNames(koreanName, englishName).
+			SetWarning(warning).
+			SetCautions(cautions[domain.CoinID(id)]...))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
+
+func queryCoinCautions(
+	ctx context.Context,
+	q querier,
+	where string,
+	args ...any,
+) (map[domain.CoinID][]domain.CautionCategory, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT coin_id, caution FROM coin_cautions WHERE coin_id IN (SELECT id FROM coins`+where+`) ORDER BY coin_id, position`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	ret := map[domain.CoinID][]domain.CautionCategory{}
+	for rows.Next() {
+		var coinID, caution string
+		err := rows.Scan(&coinID, &caution)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		ret[domain.CoinID(coinID)] = append(ret[domain.CoinID(coinID)], domain.CautionCategory(caution))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
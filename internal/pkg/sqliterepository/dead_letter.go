@@ -0,0 +1,96 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+func insertDeadLetter(ctx context.Context, tx *sql.Tx, deadLetter *domain.DeadLetter) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO dead_letters (id, subscription, event_id, topic, payload, failed_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		deadLetter.ID(), deadLetter.Subscription(), deadLetter.EventID(), deadLetter.Topic(),
+		deadLetter.Payload(), formatTime(deadLetter.FailedAt()),
+	)
+	if err != nil {
+		return translate(err, coinrepository.ErrDeadLetterNotFound, coinrepository.ErrDeadLetterAlreadyExists)
+	}
+	for position, attempt := range deadLetter.Attempts() {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO dead_letter_attempts (dead_letter_id, position, attempted_at, error) VALUES (?, ?, ?, ?)`,
+			deadLetter.ID(), position, formatTime(attempt.AttemptedAt()), attempt.Error(),
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func queryDeadLetters(ctx context.Context, q querier, where string, args ...any) ([]*domain.DeadLetter, error) {
+	attempts, err := queryDeliveryAttempts(ctx, q, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT id, subscription, event_id, topic, payload, failed_at FROM dead_letters`+where+` ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.DeadLetter
+	for rows.Next() {
+		var id, subscription, eventID, topic, failedAt string
+		var payload []byte
+		err := rows.Scan(&id, &subscription, &eventID, &topic, &payload, &failedAt)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		failed, err := parseTime(failedAt)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, domain.NewDeadLetter(id, subscription, eventID, topic, payload, attempts[id], failed))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
+
+func queryDeliveryAttempts(
+	ctx context.Context,
+	q querier,
+	where string,
+	args ...any,
+) (map[string][]*domain.DeliveryAttempt, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT dead_letter_id, attempted_at, error FROM dead_letter_attempts
+WHERE dead_letter_id IN (SELECT id FROM dead_letters`+where+`) ORDER BY dead_letter_id, position`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	ret := map[string][]*domain.DeliveryAttempt{}
+	for rows.Next() {
+		var id, attemptedAt, message string
+		err := rows.Scan(&id, &attemptedAt, &message)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		attempted, err := parseTime(attemptedAt)
+		if err != nil {
+			return nil, err
+		}
+		ret[id] = append(ret[id], domain.NewDeliveryAttempt(attempted, message))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
@@ -0,0 +1,95 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeLayout 자릿수를 고정해 문자열 순서가 시간 순서와 같게 한다.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	ret, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, corrupt(err)
+	}
+	return ret, nil
+}
+
+// querier *sql.DB와 *sql.Tx 모두에서 조회할 수 있게 한다.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// inTx fn이 실패하면 되돌리고, 성공하면 커밋한다.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return translate(err, coinrepository.ErrNotFound, coinrepository.ErrAlreadyExists)
+	}
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return translate(err, coinrepository.ErrNotFound, coinrepository.ErrAlreadyExists)
+	}
+	return nil
+}
+
+// translate sqlite 오류를 coinrepository 오류로 바꾼다.
+// notFound와 alreadyExists는 레코드마다 다른 오류를 쓰도록 넘긴다.
+func translate(err error, notFound error, alreadyExists error) error {
+	var sqliteErr *sqlite.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return errors.WithStack(notFound)
+	case errors.As(err, &sqliteErr):
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+			return errors.WithStack(alreadyExists)
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			return errors.WithStack(coinrepository.ErrConflict)
+		}
+		return errors.WithStack(err)
+	default:
+		return errors.WithStack(err)
+	}
+}
+
+// affected 바뀐 행이 없으면 notFound를 반환한다.
+func affected(result sql.Result, err error, notFound error) error {
+	if err != nil {
+		return translate(err, notFound, coinrepository.ErrAlreadyExists)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if rows == 0 {
+		return errors.WithStack(notFound)
+	}
+	return nil
+}
+
+// corrupt 저장된 값을 읽지 못한 오류를 ErrCorrupt로 감싼다.
+func corrupt(err error) error {
+	return errors.WithStack(fmt.Errorf("%w: %w", coinrepository.ErrCorrupt, err))
+}
@@ -0,0 +1,64 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+// appendEvent 번호는 지금까지 기록한 가장 큰 번호 다음이다. 오래된 이벤트를 지워도 번호는 이어진다.
+func appendEvent(ctx context.Context, tx *sql.Tx, event domain.Event, recordedAt time.Time) (*domain.LoggedEvent, error) {
+	var lastID uint64
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM event_log`).Scan(&lastID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ret := domain.NewLoggedEvent(lastID+1, event.Topic(), event.Payload(), recordedAt)
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO event_log (id, topic, payload, recorded_at) VALUES (?, ?, ?, ?)`,
+		ret.ID(), ret.Topic(), ret.Payload(), formatTime(ret.RecordedAt()),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ret.ID() > coinrepository.EventLogSize {
+		_, err := tx.ExecContext(ctx, `DELETE FROM event_log WHERE id <= ?`, ret.ID()-coinrepository.EventLogSize)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return ret, nil
+}
+
+func queryEventsAfter(ctx context.Context, q querier, id uint64) ([]*domain.LoggedEvent, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT id, topic, payload, recorded_at FROM event_log WHERE id > ? ORDER BY id`,
+		id,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.LoggedEvent
+	for rows.Next() {
+		var eventID uint64
+		var topic, recordedAt string
+		var payload []byte
+		err := rows.Scan(&eventID, &topic, &payload, &recordedAt)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		recorded, err := parseTime(recordedAt)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, domain.NewLoggedEvent(eventID, topic, payload, recorded))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
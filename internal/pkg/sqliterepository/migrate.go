@@ -0,0 +1,78 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrate 아직 적용하지 않은 migration을 번호 순서로 하나씩 적용한다.
+// 파일 이름은 0002_add_index.sql처럼 번호로 시작하며, 한 번 배포한 파일은 고치지 않는다.
+func migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER PRIMARY KEY,
+    applied_at TEXT    NOT NULL
+)`)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	entries, err := fs.ReadDir(migrations, "migrations") // 파일 이름 순서로 정렬되어 있다.
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range entries {
+		version, err := migrationVersion(entry.Name())
+		if err != nil {
+			return err
+		}
+		err = applyMigration(ctx, db, version, "migrations/"+entry.Name())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrationVersion(name string) (int, error) {
+	prefix, _, _ := strings.Cut(name, "_")
+	version, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, errors.Wrapf(err, "migration %v", name)
+	}
+	return version, nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int, path string) error {
+	script, err := migrations.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		var applied int
+		err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, version).
+			Scan(&applied)
+		if err != nil || applied > 0 {
+			return errors.WithStack(err)
+		}
+		_, err = tx.ExecContext(ctx, string(script))
+		if err != nil {
+			return errors.Wrapf(err, "migration %v", path)
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			version,
+			formatTime(time.Now()),
+		)
+		return errors.WithStack(err)
+	})
+}
@@ -0,0 +1,98 @@
+-- 시각은 UTC로, 소수점 아래 9자리를 채운 문자열로 저장하므로 문자열 순서가 시간 순서와 같다.
+-- 가격은 거래소가 준 십진수 문자열을 그대로 둔다. 계산할 때는 CAST(last_price AS REAL)을 쓴다.
+
+CREATE TABLE coins (
+    id           TEXT    PRIMARY KEY,
+    korean_name  TEXT    NOT NULL,
+    english_name TEXT    NOT NULL,
+    warning      INTEGER NOT NULL,
+    modified_at  TEXT    NOT NULL
+);
+
+CREATE TABLE coin_cautions (
+    coin_id  TEXT    NOT NULL REFERENCES coins (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    caution  TEXT    NOT NULL,
+    PRIMARY KEY (coin_id, position)
+);
+
+-- 금지는 캐시하지 않는 코인에도 걸 수 있으므로 coins를 참조하지 않는다.
+CREATE TABLE banned_coins (
+    coin_id   TEXT    PRIMARY KEY,
+    banned_at TEXT    NOT NULL,
+    period_ns INTEGER NOT NULL,
+    note      TEXT    NOT NULL
+);
+
+CREATE TABLE banned_coin_reasons (
+    coin_id  TEXT    NOT NULL REFERENCES banned_coins (coin_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    reason   TEXT    NOT NULL,
+    PRIMARY KEY (coin_id, position)
+);
+
+CREATE TABLE allowed_coins (
+    coin_id    TEXT PRIMARY KEY,
+    allowed_at TEXT NOT NULL,
+    note       TEXT NOT NULL
+);
+
+CREATE TABLE trades (
+    coin_id     TEXT NOT NULL,
+    interval    TEXT NOT NULL,
+    modified_at TEXT NOT NULL,
+    PRIMARY KEY (coin_id, interval)
+);
+
+CREATE TABLE candles (
+    coin_id            TEXT NOT NULL,
+    interval           TEXT NOT NULL,
+    date               TEXT NOT NULL,
+    last_price         TEXT NOT NULL,
+    opening_price      TEXT NOT NULL,
+    max_price          TEXT NOT NULL,
+    min_price          TEXT NOT NULL,
+    volume             TEXT NOT NULL,
+    traded_value       TEXT NOT NULL,
+    prev_closing_price TEXT NOT NULL,
+    change_price       TEXT NOT NULL,
+    change_rate        TEXT NOT NULL,
+    PRIMARY KEY (coin_id, interval, date),
+    FOREIGN KEY (coin_id, interval) REFERENCES trades (coin_id, interval) ON DELETE CASCADE
+);
+
+CREATE TABLE event_log (
+    id          INTEGER PRIMARY KEY,
+    topic       TEXT    NOT NULL,
+    payload     BLOB    NOT NULL,
+    recorded_at TEXT    NOT NULL
+);
+
+-- seq는 기록한 순서이다. relay는 이 순서로 발행한다.
+CREATE TABLE outbox (
+    seq           INTEGER PRIMARY KEY AUTOINCREMENT,
+    id            TEXT    NOT NULL UNIQUE,
+    topic         TEXT    NOT NULL,
+    version       INTEGER NOT NULL,
+    occurred_at   TEXT    NOT NULL,
+    producer      TEXT    NOT NULL,
+    trace_context TEXT    NOT NULL, -- JSON 객체
+    payload       BLOB    NOT NULL
+);
+
+CREATE TABLE dead_letters (
+    id           TEXT PRIMARY KEY,
+    subscription TEXT NOT NULL,
+    event_id     TEXT NOT NULL,
+    topic        TEXT NOT NULL,
+    payload      BLOB NOT NULL,
+    failed_at    TEXT NOT NULL
+);
+
+CREATE TABLE dead_letter_attempts (
+    dead_letter_id TEXT    NOT NULL REFERENCES dead_letters (id) ON DELETE CASCADE,
+    position       INTEGER NOT NULL,
+    attempted_at   TEXT    NOT NULL,
+    error          TEXT    NOT NULL,
+    PRIMARY KEY (dead_letter_id, position)
+);
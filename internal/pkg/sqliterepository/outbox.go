@@ -0,0 +1,79 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// insertOutboxEvents 봉투에 담기지 않은 이벤트는 발행자 정보 없이 담는다.
+func insertOutboxEvents(ctx context.Context, tx *sql.Tx, events []domain.Event) error {
+	now := time.Now()
+	for _, event := range events {
+		envelope, ok := event.(*domain.Envelope)
+		if !ok {
+			envelope = domain.NewEnvelope(
+				uuid.Must(uuid.NewV7()).String(),
+				event.Topic(),
+				domain.EventVersion(event),
+				now,
+				"",
+				nil,
+				event.Payload(),
+			)
+		}
+		traceContext, err := json.Marshal(envelope.TraceContext())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO outbox (id, topic, version, occurred_at, producer, trace_context, payload)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			envelope.EventID(), envelope.Topic(), envelope.Version(), formatTime(envelope.OccurredAt()),
+			envelope.Producer(), string(traceContext), envelope.Payload(),
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func queryOutboxEvents(ctx context.Context, q querier, limit int) ([]*domain.Envelope, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT id, topic, version, occurred_at, producer, trace_context, payload FROM outbox ORDER BY seq LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.Envelope
+	for rows.Next() {
+		var id, topic, occurredAt, producer, traceContext string
+		var version int
+		var payload []byte
+		err := rows.Scan(&id, &topic, &version, &occurredAt, &producer, &traceContext, &payload)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		occurred, err := parseTime(occurredAt)
+		if err != nil {
+			return nil, err
+		}
+		var carrier map[string]string
+		err = json.Unmarshal([]byte(traceContext), &carrier)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		ret = append(ret, domain.NewEnvelope(id, topic, version, occurred, producer, carrier, payload))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
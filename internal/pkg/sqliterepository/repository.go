@@ -0,0 +1,320 @@
+// Package sqliterepository coinrepository.CoinRepository를 SQLite 테이블로 구현한다.
+// cgo 없이 빌드되는 modernc.org/sqlite 드라이버를 쓴다.
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite" // database/sql에 "sqlite" 드라이버를 등록한다.
+)
+
+var _ coinrepository.CoinRepository = (*Repository)(nil)
+
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository path의 데이터베이스를 열고 migration을 적용한다.
+// 쓰기는 한 번에 하나만 가능하므로 연결을 하나만 둔다.
+func NewRepository(ctx context.Context, path string) (*Repository, error) {
+	db, err := sql.Open(
+		"sqlite",
+		"file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	db.SetMaxOpenConns(1)
+	err = migrate(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Repository{db: db}, nil
+}
+
+func (r *Repository) Close() error {
+	return errors.WithStack(r.db.Close())
+}
+
+// CreateCoin implements coinrepository.CoinRepository.
+func (r *Repository) CreateCoin(ctx context.Context, coin *domain.Coin, events ...domain.Event) (*domain.Coin, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := insertCoin(ctx, tx, coin)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvents(ctx, tx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coin, nil
+}
+
+// ListCoins implements coinrepository.CoinRepository.
+func (r *Repository) ListCoins(ctx context.Context) ([]*domain.Coin, error) {
+	return queryCoins(ctx, r.db, "")
+}
+
+// GetCoin implements coinrepository.CoinRepository.
+func (r *Repository) GetCoin(ctx context.Context, coinID domain.CoinID) (*domain.Coin, error) {
+	coins, err := queryCoins(ctx, r.db, ` WHERE id = ?`, string(coinID))
+	if err != nil {
+		return nil, err
+	}
+	if len(coins) == 0 {
+		return nil, errors.WithStack(coinrepository.ErrCoinNotFound)
+	}
+	return coins[0], nil
+}
+
+// UpdateCoin implements coinrepository.CoinRepository.
+func (r *Repository) UpdateCoin(ctx context.Context, coin *domain.Coin, events ...domain.Event) (*domain.Coin, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := updateCoin(ctx, tx, coin)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvents(ctx, tx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coin, nil
+}
+
+// DeleteCoin implements coinrepository.CoinRepository.
+func (r *Repository) DeleteCoin(ctx context.Context, coin *domain.Coin, events ...domain.Event) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := deleteCoin(ctx, tx, coin.ID())
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvents(ctx, tx, events)
+	})
+}
+
+// SaveCoins implements coinrepository.CoinRepository.
+func (r *Repository) SaveCoins(ctx context.Context, changes *coinrepository.CoinChanges, events ...domain.Event) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, coin := range changes.Created {
+			err := insertCoin(ctx, tx, coin)
+			if err != nil {
+				return err
+			}
+		}
+		for _, coin := range changes.Updated {
+			err := updateCoin(ctx, tx, coin)
+			if err != nil {
+				return err
+			}
+		}
+		for _, coin := range changes.Deleted {
+			err := deleteCoin(ctx, tx, coin.ID())
+			if err != nil {
+				return err
+			}
+		}
+		return insertOutboxEvents(ctx, tx, events)
+	})
+}
+
+// SaveTrades implements coinrepository.CoinRepository.
+// 캔들은 한 트랜잭션으로 저장하며, 내용이 같은 캔들은 다시 쓰지 않는다.
+func (r *Repository) SaveTrades(ctx context.Context, trades *domain.Trades) ([]*domain.TradeChange, error) {
+	var ret []*domain.TradeChange
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		ret, err = saveTrades(ctx, tx, trades)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListTrades implements coinrepository.CoinRepository.
+func (r *Repository) ListTrades(ctx context.Context, id domain.CoinID, interval domain.Interval) (*domain.Trades, error) {
+	return queryTrades(ctx, r.db, id, interval, coinrepository.TradeRange{}) //nolint:exhaustruct
+}
+
+// ListTradesInRange implements coinrepository.CoinRepository.
+func (r *Repository) ListTradesInRange(
+	ctx context.Context,
+	id domain.CoinID,
+	interval domain.Interval,
+	tradeRange coinrepository.TradeRange,
+) (*domain.Trades, error) {
+	return queryTrades(ctx, r.db, id, interval, tradeRange)
+}
+
+// DeleteTrades implements coinrepository.CoinRepository.
+func (r *Repository) DeleteTrades(ctx context.Context, id domain.CoinID) ([]domain.Interval, error) {
+	var ret []domain.Interval
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		ret, err = deleteTrades(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CreateBannedCoin implements coinrepository.CoinRepository.
+func (r *Repository) CreateBannedCoin(
+	ctx context.Context,
+	bannedCoin *domain.BannedCoin,
+	events ...domain.Event,
+) (*domain.BannedCoin, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := insertBannedCoin(ctx, tx, bannedCoin)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvents(ctx, tx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bannedCoin, nil
+}
+
+// ListBannedCoins implements coinrepository.CoinRepository.
+func (r *Repository) ListBannedCoins(ctx context.Context) ([]*domain.BannedCoin, error) {
+	return queryBannedCoins(ctx, r.db, "")
+}
+
+// GetBannedCoin implements coinrepository.CoinRepository.
+func (r *Repository) GetBannedCoin(ctx context.Context, coinID domain.CoinID) (*domain.BannedCoin, error) {
+	coins, err := queryBannedCoins(ctx, r.db, ` WHERE coin_id = ?`, string(coinID))
+	if err != nil {
+		return nil, err
+	}
+	if len(coins) == 0 {
+		return nil, errors.WithStack(coinrepository.ErrBannedCoinNotFound)
+	}
+	return coins[0], nil
+}
+
+// DeleteBannedCoin implements coinrepository.CoinRepository.
+func (r *Repository) DeleteBannedCoin(ctx context.Context, bannedCoin *domain.BannedCoin, events ...domain.Event) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := deleteBannedCoin(ctx, tx, bannedCoin.CoinID())
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvents(ctx, tx, events)
+	})
+}
+
+// CreateAllowedCoin implements coinrepository.CoinRepository.
+func (r *Repository) CreateAllowedCoin(
+	ctx context.Context,
+	allowedCoin *domain.AllowedCoin,
+) (*domain.AllowedCoin, error) {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO allowed_coins (coin_id, allowed_at, note) VALUES (?, ?, ?)`,
+		string(allowedCoin.CoinID()), formatTime(allowedCoin.AllowedAt()), allowedCoin.Note(),
+	)
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrAllowedCoinNotFound, coinrepository.ErrAllowedCoinAlreadyExists)
+	}
+	return allowedCoin, nil
+}
+
+// ListAllowedCoins implements coinrepository.CoinRepository.
+func (r *Repository) ListAllowedCoins(ctx context.Context) ([]*domain.AllowedCoin, error) {
+	return queryAllowedCoins(ctx, r.db, "")
+}
+
+// GetAllowedCoin implements coinrepository.CoinRepository.
+func (r *Repository) GetAllowedCoin(ctx context.Context, coinID domain.CoinID) (*domain.AllowedCoin, error) {
+	coins, err := queryAllowedCoins(ctx, r.db, ` WHERE coin_id = ?`, string(coinID))
+	if err != nil {
+		return nil, err
+	}
+	if len(coins) == 0 {
+		return nil, errors.WithStack(coinrepository.ErrAllowedCoinNotFound)
+	}
+	return coins[0], nil
+}
+
+// DeleteAllowedCoin implements coinrepository.CoinRepository.
+func (r *Repository) DeleteAllowedCoin(ctx context.Context, allowedCoin *domain.AllowedCoin) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM allowed_coins WHERE coin_id = ?`, string(allowedCoin.CoinID()))
+	return affected(result, err, coinrepository.ErrAllowedCoinNotFound)
+}
+
+// AppendEvent implements coinrepository.CoinRepository.
+func (r *Repository) AppendEvent(
+	ctx context.Context,
+	event domain.Event,
+	recordedAt time.Time,
+) (*domain.LoggedEvent, error) {
+	var ret *domain.LoggedEvent
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		ret, err = appendEvent(ctx, tx, event, recordedAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListEventsAfter implements coinrepository.CoinRepository.
+func (r *Repository) ListEventsAfter(ctx context.Context, id uint64) ([]*domain.LoggedEvent, error) {
+	return queryEventsAfter(ctx, r.db, id)
+}
+
+// ListOutboxEvents implements coinrepository.CoinRepository.
+func (r *Repository) ListOutboxEvents(ctx context.Context, limit int) ([]*domain.Envelope, error) {
+	return queryOutboxEvents(ctx, r.db, limit)
+}
+
+// DeleteOutboxEvent implements coinrepository.CoinRepository. 이미 지워졌다면 무시한다.
+func (r *Repository) DeleteOutboxEvent(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE id = ?`, id)
+	return translate(err, coinrepository.ErrNotFound, coinrepository.ErrAlreadyExists)
+}
+
+// CreateDeadLetter implements coinrepository.CoinRepository.
+func (r *Repository) CreateDeadLetter(ctx context.Context, deadLetter *domain.DeadLetter) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return insertDeadLetter(ctx, tx, deadLetter)
+	})
+}
+
+// ListDeadLetters implements coinrepository.CoinRepository.
+func (r *Repository) ListDeadLetters(ctx context.Context) ([]*domain.DeadLetter, error) {
+	return queryDeadLetters(ctx, r.db, "")
+}
+
+// GetDeadLetter implements coinrepository.CoinRepository.
+func (r *Repository) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
+	deadLetters, err := queryDeadLetters(ctx, r.db, ` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(deadLetters) == 0 {
+		return nil, errors.WithStack(coinrepository.ErrDeadLetterNotFound)
+	}
+	return deadLetters[0], nil
+}
+
+// DeleteDeadLetter implements coinrepository.CoinRepository.
+func (r *Repository) DeleteDeadLetter(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM dead_letters WHERE id = ?`, id)
+	return affected(result, err, coinrepository.ErrDeadLetterNotFound)
+}
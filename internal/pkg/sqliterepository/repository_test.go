@@ -0,0 +1,109 @@
+package sqliterepository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository/coinrepositorytest"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/sqliterepository"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t *testing.T, path string) *sqliterepository.Repository {
+	t.Helper()
+	repo, err := sqliterepository.NewRepository(context.Background(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = repo.Close()
+	})
+	return repo
+}
+
+func TestRepository(t *testing.T) {
+	t.Parallel()
+	coinrepositorytest.Run(t, func(t *testing.T) coinrepository.CoinRepository {
+		return open(t, filepath.Join(t.TempDir(), "coins.db"))
+	})
+}
+
+func TestRepository_Coin(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "coins.db")
+	repo := open(t, path)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)
+	coin := domain.NewCoin("A", now).
+		SetNames("에이", "A coin").
+		SetCautions(domain.CautionTradingVolumeSoaring, domain.CautionPriceFluctuations)
+	_, err := repo.CreateCoin(ctx, coin, domain.NewCoinCreatedEvent(now, "A"))
+	require.NoError(t, err)
+	repo = open(t, path) // migration은 다시 적용되지 않는다.
+
+	coins, err := repo.ListCoins(ctx)
+
+	require.NoError(t, err)
+	require.Equal(t, []*domain.Coin{coin}, coins)
+	events, err := repo.ListOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, domain.CoinCreatedEventTopic, events[0].Topic())
+}
+
+func TestRepository_SaveTrades(t *testing.T) {
+	t.Parallel()
+	repo := open(t, filepath.Join(t.TempDir(), "coins.db"))
+	ctx := context.Background()
+	yesterday := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)
+	_, _ = repo.SaveTrades(ctx, domain.NewTrades("A", domain.IntervalDay, today, []*domain.Trade{
+		domain.NewTrade(yesterday, "100", "100", "100", "100"),
+		domain.NewTrade(today, "100", "100", "100", "100"),
+	}))
+
+	changes, err := repo.SaveTrades(ctx, domain.NewTrades("A", domain.IntervalDay, today, []*domain.Trade{
+		domain.NewTrade(yesterday, "100", "100", "100", "100"),
+		domain.NewTrade(today, "110", "100", "110", "100").SetVolume("1.5", "165"),
+		domain.NewTrade(today.AddDate(0, 0, 1), "110", "110", "110", "110"),
+	}))
+
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, domain.TradeChangeUpdated, changes[0].Kind())
+	require.Equal(t, domain.TradeChangeCreated, changes[1].Kind())
+	trades, err := repo.ListTrades(ctx, "A", domain.IntervalDay)
+	require.NoError(t, err)
+	require.Equal(t, 3, trades.Size())
+	require.Equal(t, domain.Volume("1.5"), trades.Trades()[1].Volume())
+	intervals, err := repo.DeleteTrades(ctx, "A")
+	require.NoError(t, err)
+	require.Equal(t, []domain.Interval{domain.IntervalDay}, intervals)
+	_, err = repo.ListTrades(ctx, "A", domain.IntervalDay)
+	require.ErrorIs(t, err, coinrepository.ErrTradesNotFound)
+}
+
+func TestRepository_Errors(t *testing.T) {
+	t.Parallel()
+	repo := open(t, filepath.Join(t.TempDir(), "coins.db"))
+	ctx := context.Background()
+	coin := domain.NewCoin("A", time.Now())
+	_, _ = repo.CreateCoin(ctx, coin)
+
+	_, err := repo.GetCoin(ctx, "B")
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	_, err = repo.UpdateCoin(ctx, domain.NewCoin("B", time.Now()))
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	_, err = repo.CreateCoin(ctx, coin)
+	require.ErrorIs(t, err, coinrepository.ErrCoinAlreadyExists)
+	err = repo.SaveCoins(ctx, &coinrepository.CoinChanges{
+		Created: []*domain.Coin{domain.NewCoin("C", time.Now())},
+		Updated: nil,
+		Deleted: []*domain.Coin{domain.NewCoin("B", time.Now())},
+	})
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+	_, err = repo.GetCoin(ctx, "C")
+	require.ErrorIs(t, err, coinrepository.ErrCoinNotFound)
+}
@@ -0,0 +1,215 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/coinrepository"
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/pkg/errors"
+)
+
+const selectCandles = `SELECT date, last_price, opening_price, max_price, min_price,
+       volume, traded_value, prev_closing_price, change_price, change_rate
+FROM candles`
+
+// saveTrades 캔들이 없으면 추가하고, 내용이 다르면 갱신한다. 바뀐 캔들만 돌려준다.
+func saveTrades(ctx context.Context, tx *sql.Tx, trades *domain.Trades) ([]*domain.TradeChange, error) {
+	coinID := string(trades.CoinID())
+	interval := string(trades.Interval())
+	var exists int
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM trades WHERE coin_id = ? AND interval = ?`,
+		coinID, interval,
+	).Scan(&exists)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if exists == 0 {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO trades (coin_id, interval, modified_at) VALUES (?, ?, ?)`,
+			coinID, interval, formatTime(trades.ModifiedAt()),
+		)
+		if err != nil {
+			return nil, translate(err, coinrepository.ErrTradesNotFound, coinrepository.ErrConflict)
+		}
+	}
+
+	var changes []*domain.TradeChange
+	for _, trade := range trades.Trades() {
+		change, err := saveCandle(ctx, tx, trades.CoinID(), trades.Interval(), trade)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+	if exists > 0 && len(changes) > 0 {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE trades SET modified_at = ? WHERE coin_id = ? AND interval = ?`,
+			formatTime(trades.ModifiedAt()), coinID, interval,
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return changes, nil
+}
+
+func saveCandle(
+	ctx context.Context,
+	tx *sql.Tx,
+	coinID domain.CoinID,
+	interval domain.Interval,
+	trade *domain.Trade,
+) (*domain.TradeChange, error) {
+	stored, err := queryCandles(
+		ctx, tx, ` WHERE coin_id = ? AND interval = ? AND date = ?`,
+		string(coinID), string(interval), formatTime(trade.Date()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) > 0 && stored[0].Equal(trade) {
+		return nil, nil //nolint:nilnil
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO candles (coin_id, interval, date, last_price, opening_price, max_price, min_price,
+                     volume, traded_value, prev_closing_price, change_price, change_rate)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (coin_id, interval, date) DO UPDATE SET
+    last_price = excluded.last_price,
+    opening_price = excluded.opening_price,
+    max_price = excluded.max_price,
+    min_price = excluded.min_price,
+    volume = excluded.volume,
+    traded_value = excluded.traded_value,
+    prev_closing_price = excluded.prev_closing_price,
+    change_price = excluded.change_price,
+    change_rate = excluded.change_rate`,
+		string(coinID), string(interval), formatTime(trade.Date()),
+		string(trade.LastPrice()), string(trade.OpeningPrice()), string(trade.MaxPrice()), string(trade.MinPrice()),
+		string(trade.Volume()), string(trade.TradedValue()),
+		string(trade.PrevClosingPrice()), string(trade.ChangePrice()), string(trade.ChangeRate()),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(stored) > 0 {
+		return domain.NewTradeChange(domain.TradeChangeUpdated, trade), nil
+	}
+	return domain.NewTradeChange(domain.TradeChangeCreated, trade), nil
+}
+
+// queryTrades 범위는 기본 키 (coin_id, interval, date)로 고르므로 범위 밖의 캔들은 읽지 않는다.
+func queryTrades(
+	ctx context.Context,
+	q querier,
+	coinID domain.CoinID,
+	interval domain.Interval,
+	tradeRange coinrepository.TradeRange,
+) (*domain.Trades, error) {
+	var modifiedAt string
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT modified_at FROM trades WHERE coin_id = ? AND interval = ?`,
+		string(coinID), string(interval),
+	).Scan(&modifiedAt)
+	if err != nil {
+		return nil, translate(err, coinrepository.ErrTradesNotFound, coinrepository.ErrConflict)
+	}
+	modified, err := parseTime(modifiedAt)
+	if err != nil {
+		return nil, err
+	}
+	where, args := candlesInRange(coinID, interval, tradeRange)
+	candles, err := queryCandles(ctx, q, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTrades(coinID, interval, modified, candles), nil
+}
+
+// candlesInRange Limit을 넘으면 최근 캔들을 남긴다.
+func candlesInRange(
+	coinID domain.CoinID,
+	interval domain.Interval,
+	tradeRange coinrepository.TradeRange,
+) (string, []any) {
+	where := ` WHERE coin_id = ? AND interval = ?`
+	args := []any{string(coinID), string(interval)}
+	if !tradeRange.From.IsZero() {
+		where += ` AND date >= ?`
+		args = append(args, formatTime(tradeRange.From))
+	}
+	if !tradeRange.To.IsZero() {
+		where += ` AND date <= ?`
+		args = append(args, formatTime(tradeRange.To))
+	}
+	if tradeRange.Limit > 0 {
+		where += ` AND date IN (SELECT date FROM candles` + where + ` ORDER BY date DESC LIMIT ?)`
+		args = append(args, args...)
+		args = append(args, tradeRange.Limit)
+	}
+	return where, args
+}
+
+// queryCandles 오래된 캔들부터 돌려준다.
+func queryCandles(ctx context.Context, q querier, where string, args ...any) ([]*domain.Trade, error) {
+	rows, err := q.QueryContext(ctx, selectCandles+where+` ORDER BY date`, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []*domain.Trade
+	for rows.Next() {
+		var date, last, opening, high, low, volume, tradedValue, prevClosing, change, changeRate string
+		err := rows.Scan(&date, &last, &opening, &high, &low, &volume, &tradedValue, &prevClosing, &change, &changeRate)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		parsed, err := parseTime(date)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, domain.NewTrade(
+			parsed,
+			domain.Price(last),
+			domain.Price(opening),
+			domain.Price(high),
+			domain.Price(low),
+		).SetVolume(
+			domain.Volume(volume),
+			domain.Price(tradedValue),
+		).SetChange(
+			domain.Price(prevClosing),
+			domain.Price(change),
+			domain.Rate(changeRate),
+		))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
+
+// deleteTrades 캔들은 ON DELETE CASCADE로 함께 지워진다.
+func deleteTrades(ctx context.Context, tx *sql.Tx, coinID domain.CoinID) ([]domain.Interval, error) {
+	rows, err := tx.QueryContext(ctx, `DELETE FROM trades WHERE coin_id = ? RETURNING interval`, string(coinID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var ret []domain.Interval
+	for rows.Next() {
+		var interval string
+		err := rows.Scan(&interval)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		ret = append(ret, domain.Interval(interval))
+	}
+	return ret, errors.WithStack(rows.Err())
+}
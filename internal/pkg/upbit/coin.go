@@ -48,14 +48,37 @@ func (m *MarketCaution) IsHazardous() bool {
 		m.CONCENTRATIONOFSMALLACCOUNTS
 }
 
-// ToDomain
+// Categories 켜져 있는 주의 사유.
+func (m *MarketCaution) Categories() []domain.CautionCategory {
+	if m == nil {
+		return nil
+	}
+	var ret []domain.CautionCategory
+	for _, category := range []struct {
+		on       bool
+		category domain.CautionCategory
+	}{
+		{m.PRICEFLUCTUATIONS, domain.CautionPriceFluctuations},
+		{m.TRADINGVOLUMESOARING, domain.CautionTradingVolumeSoaring},
+		{m.DEPOSITAMOUNTSOARING, domain.CautionDepositAmountSoaring},
+		{m.GLOBALPRICEDIFFERENCES, domain.CautionGlobalPriceDifferences},
+		{m.CONCENTRATIONOFSMALLACCOUNTS, domain.CautionConcentrationOfSmallAccounts},
+	} {
+		if category.on {
+			ret = append(ret, category.category)
+		}
+	}
+	return ret
+}
+
+// ToDomain now를 수정 시각으로 쓴다. market_event가 없으면 유의 종목이 아닌 것으로 본다.
 func (c *Coin) ToDomain(now time.Time) *domain.Coin {
 	if c == nil {
 		return nil
 	}
-	return domain.NewCoin(
-		domain.CoinID(c.Market),
-		c.IsHazardous(),
-		now,
-	)
+	ret := domain.NewCoin(domain.NewCoinID(domain.ExchangeUpbit, c.Market), now).SetNames(c.KoreanName, c.EnglishName)
+	if c.MarketEvent == nil {
+		return ret
+	}
+	return ret.SetWarning(c.MarketEvent.Warning).SetCautions(c.MarketEvent.Caution.Categories()...)
 }
@@ -0,0 +1,223 @@
+package upbit
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/pkg/errors"
+)
+
+const remainingReqHeader = "Remaining-Req"
+
+// RemainingReq 업비트가 응답마다 알려주는 그룹별 남은 요청 수. e.g. "group=candles; min=1800; sec=29"
+type RemainingReq struct {
+	Group string
+	Min   int
+	Sec   int
+}
+
+var ErrInvalidRemainingReq = errors.New("invalid Remaining-Req header")
+
+func ParseRemainingReq(header string) (*RemainingReq, error) {
+	var ret RemainingReq
+	var hasSec bool
+	for _, field := range strings.Split(header, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, errors.Wrap(ErrInvalidRemainingReq, header)
+		}
+		switch key {
+		case "group":
+			ret.Group = value
+		case "min":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidRemainingReq, header)
+			}
+			ret.Min = n
+		case "sec":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidRemainingReq, header)
+			}
+			ret.Sec = n
+			hasSec = true
+		}
+	}
+	if ret.Group == "" || !hasSec {
+		return nil, errors.Wrap(ErrInvalidRemainingReq, header)
+	}
+	return &ret, nil
+}
+
+// Limiter 업비트 요청을 그룹별로 초 단위 창과 분 단위 창에 맞춰 내보낸다.
+// 모든 코인의 작업이 하나의 Limiter를 공유하므로, 먼저 기다린 호출자가 먼저 요청한다.
+type Limiter struct {
+	tracer    tracer.Tracer
+	perSecond int
+
+	mu      sync.Mutex
+	groups  map[string]*limiterGroup
+	aliases map[string]string // 호출자가 붙인 그룹 이름을 응답 헤더가 알려준 그룹 이름으로 바꾼다.
+}
+
+func NewLimiter(tracer tracer.Tracer, perSecond int) *Limiter {
+	return &Limiter{
+		tracer:    tracer,
+		perSecond: perSecond,
+		mu:        sync.Mutex{},
+		groups:    make(map[string]*limiterGroup),
+		aliases:   make(map[string]string),
+	}
+}
+
+type limiterGroup struct {
+	turn    chan struct{} // 차례를 기다리는 호출자는 채널의 대기열 순서(FIFO)대로 들어간다.
+	waiting atomic.Int64
+
+	mu        sync.Mutex
+	remaining int
+	resetAt   time.Time
+	// 분당 한도는 미리 알 수 없으므로 헤더로 알게 된 창 안에서만 지킨다.
+	minuteKnown     bool
+	minuteRemaining int
+	minuteResetAt   time.Time
+}
+
+// group name이 응답 헤더로 다른 그룹에 속한다고 알려졌다면 그 그룹을 반환한다.
+func (l *Limiter) group(name string) *limiterGroup {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if alias, ok := l.aliases[name]; ok {
+		name = alias
+	}
+	g, ok := l.groups[name]
+	if !ok {
+		g = &limiterGroup{turn: make(chan struct{}, 1)} //nolint:exhaustruct
+		l.groups[name] = g
+	}
+	return g
+}
+
+func (l *Limiter) alias(name string, group string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if name == group {
+		delete(l.aliases, name)
+		return
+	}
+	l.aliases[name] = group
+}
+
+// Wait group에 요청을 보낼 수 있을 때까지 기다린다.
+func (l *Limiter) Wait(ctx context.Context, group string) error {
+	_, span := l.tracer.Start(ctx, "upbit.Limiter.Wait")
+	defer span.End()
+	span.String("group", group)
+
+	g := l.group(group)
+	span.Int64("queue_depth", g.waiting.Add(1)-1)
+	defer g.waiting.Add(-1)
+	start := time.Now()
+	defer func() {
+		span.Int64("wait_ms", time.Since(start).Milliseconds())
+	}()
+
+	select {
+	case g.turn <- struct{}{}:
+	case <-ctx.Done():
+		span.Error(ctx.Err())
+		return errors.WithStack(ctx.Err())
+	}
+	defer func() { <-g.turn }()
+
+	for {
+		delay := g.reserve(time.Now(), l.perSecond)
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			span.Error(ctx.Err())
+			return errors.WithStack(ctx.Err())
+		}
+	}
+}
+
+// Observe 응답의 Remaining-Req 헤더로 남은 요청 수를 맞춘다.
+// 헤더가 알려준 그룹을 따르므로, 이후 group으로 기다리는 요청은 그 그룹의 한도를 함께 쓴다.
+func (l *Limiter) Observe(group string, headers map[string][]string) {
+	values := headers[remainingReqHeader]
+	if len(values) == 0 {
+		return
+	}
+	remaining, err := ParseRemainingReq(values[0])
+	if err != nil {
+		return
+	}
+	l.alias(group, remaining.Group)
+	l.group(remaining.Group).observe(time.Now(), remaining, l.perSecond)
+}
+
+// Throttled 429를 받으면 다음 초 단위 창까지 group의 요청을 멈춘다.
+func (l *Limiter) Throttled(group string) {
+	l.group(group).throttled(time.Now(), l.perSecond)
+}
+
+func (g *limiterGroup) reserve(now time.Time, perSecond int) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refresh(now, perSecond)
+	if g.minuteKnown && g.minuteRemaining <= 0 {
+		return g.minuteResetAt.Sub(now)
+	}
+	if g.remaining <= 0 {
+		return g.resetAt.Sub(now)
+	}
+	g.remaining--
+	if g.minuteKnown {
+		g.minuteRemaining--
+	}
+	return 0
+}
+
+// observe 여러 응답이 순서가 바뀌어 도착할 수 있으므로 더 적은 남은 요청 수를 믿는다.
+func (g *limiterGroup) observe(now time.Time, remaining *RemainingReq, perSecond int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refresh(now, perSecond)
+	g.remaining = min(g.remaining, remaining.Sec)
+	if !g.minuteKnown {
+		g.minuteKnown = true
+		g.minuteRemaining = remaining.Min
+		g.minuteResetAt = now.Add(time.Minute)
+		return
+	}
+	g.minuteRemaining = min(g.minuteRemaining, remaining.Min)
+}
+
+func (g *limiterGroup) throttled(now time.Time, perSecond int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refresh(now, perSecond)
+	g.remaining = 0
+}
+
+func (g *limiterGroup) refresh(now time.Time, perSecond int) {
+	if g.minuteKnown && !now.Before(g.minuteResetAt) {
+		g.minuteKnown = false
+	}
+	if now.Before(g.resetAt) {
+		return
+	}
+	g.remaining = perSecond
+	g.resetAt = now.Add(time.Second)
+}
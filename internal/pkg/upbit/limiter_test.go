@@ -0,0 +1,90 @@
+package upbit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemainingReq(t *testing.T) {
+	t.Parallel()
+
+	ret, err := upbit.ParseRemainingReq("group=candles; min=1800; sec=29")
+
+	require.NoError(t, err)
+	require.Equal(t, &upbit.RemainingReq{Group: "candles", Min: 1800, Sec: 29}, ret)
+}
+
+func TestParseRemainingReq_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := upbit.ParseRemainingReq("group=candles; sec=abc")
+
+	require.ErrorIs(t, err, upbit.ErrInvalidRemainingReq)
+}
+
+func TestLimiter_WaitPacesRequestsPerSecond(t *testing.T) {
+	t.Parallel()
+	limiter := upbit.NewLimiter(noop.NewTracer(), 2)
+	ctx := context.Background()
+	start := time.Now()
+
+	for range 5 {
+		require.NoError(t, limiter.Wait(ctx, "candles"))
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 2*time.Second)
+}
+
+func TestLimiter_ObserveExhaustedGroup(t *testing.T) {
+	t.Parallel()
+	limiter := upbit.NewLimiter(noop.NewTracer(), 10)
+	ctx := context.Background()
+	require.NoError(t, limiter.Wait(ctx, "market"))
+	limiter.Observe("market", map[string][]string{"Remaining-Req": {"group=market; min=573; sec=0"}})
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Wait(ctx, "market")
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, limiter.Wait(context.Background(), "candles"))
+}
+
+func TestLimiter_ObserveExhaustedMinute(t *testing.T) {
+	t.Parallel()
+	limiter := upbit.NewLimiter(noop.NewTracer(), 10)
+	ctx := context.Background()
+	require.NoError(t, limiter.Wait(ctx, "candles"))
+	limiter.Observe("candles", map[string][]string{"Remaining-Req": {"group=candles; min=1; sec=9"}})
+	require.NoError(t, limiter.Wait(ctx, "candles"))
+	ctx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Wait(ctx, "candles")
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLimiter_ObserveFollowsHeaderGroup(t *testing.T) {
+	t.Parallel()
+	limiter := upbit.NewLimiter(noop.NewTracer(), 10)
+	ctx := context.Background()
+	require.NoError(t, limiter.Wait(ctx, "orderbook"))
+	limiter.Observe("orderbook", map[string][]string{"Remaining-Req": {"group=market; min=573; sec=0"}})
+	marketCtx, cancelMarket := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelMarket()
+	orderbookCtx, cancelOrderbook := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelOrderbook()
+
+	marketErr := limiter.Wait(marketCtx, "market")
+	orderbookErr := limiter.Wait(orderbookCtx, "orderbook")
+
+	require.ErrorIs(t, marketErr, context.DeadlineExceeded)
+	require.ErrorIs(t, orderbookErr, context.DeadlineExceeded)
+	require.NoError(t, limiter.Wait(ctx, "candles"))
+}
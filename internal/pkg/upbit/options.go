@@ -0,0 +1,89 @@
+package upbit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/biosvos/coin-cache-service/pkg/tracer/noop"
+)
+
+const (
+	defaultBaseURL      = "https://api.upbit.com"
+	defaultWebSocketURL = "wss://api.upbit.com/websocket/v1"
+
+	defaultMinReconnectBackoff = time.Second
+	defaultMaxReconnectBackoff = time.Minute
+
+	// defaultRequestsPerSecond 업비트 시세 조회 API는 그룹별로 초당 10회까지 허용한다.
+	defaultRequestsPerSecond = 10
+)
+
+type Options struct {
+	BaseURL           string
+	WebSocketURL      string
+	Quotes            []domain.Quote
+	Tracer            tracer.Tracer
+	RequestsPerSecond int
+
+	MinReconnectBackoff time.Duration
+	MaxReconnectBackoff time.Duration
+}
+
+func NewOptions() *Options {
+	return &Options{
+		BaseURL:           defaultBaseURL,
+		WebSocketURL:      defaultWebSocketURL,
+		Quotes:            []domain.Quote{domain.QuoteKRW},
+		Tracer:            noop.NewTracer(),
+		RequestsPerSecond: defaultRequestsPerSecond,
+
+		MinReconnectBackoff: defaultMinReconnectBackoff,
+		MaxReconnectBackoff: defaultMaxReconnectBackoff,
+	}
+}
+
+type Option func(*Options)
+
+// WithBaseURL 업비트 API 주소를 바꾼다. 테스트에서는 upbittest 서버 주소를 넣는다.
+func WithBaseURL(baseURL string) Option {
+	return func(o *Options) {
+		o.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithWebSocketURL 업비트 웹소켓 주소를 바꾼다.
+func WithWebSocketURL(webSocketURL string) Option {
+	return func(o *Options) {
+		o.WebSocketURL = webSocketURL
+	}
+}
+
+// WithReconnectBackoff 웹소켓 연결이 끊기면 minBackoff부터 두 배씩 maxBackoff까지 기다렸다가 다시 연결한다.
+func WithReconnectBackoff(minBackoff time.Duration, maxBackoff time.Duration) Option {
+	return func(o *Options) {
+		o.MinReconnectBackoff = minBackoff
+		o.MaxReconnectBackoff = maxBackoff
+	}
+}
+
+// WithQuotes quotes 화폐로 거래하는 마켓만 가져온다. 기본은 KRW이다.
+func WithQuotes(quotes ...domain.Quote) Option {
+	return func(o *Options) {
+		o.Quotes = quotes
+	}
+}
+
+func WithTracer(tracer tracer.Tracer) Option {
+	return func(o *Options) {
+		o.Tracer = tracer
+	}
+}
+
+// WithRequestsPerSecond Remaining-Req 헤더를 받기 전까지 그룹별로 허용할 초당 요청 수
+func WithRequestsPerSecond(requestsPerSecond int) Option {
+	return func(o *Options) {
+		o.RequestsPerSecond = requestsPerSecond
+	}
+}
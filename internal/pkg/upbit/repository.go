@@ -2,8 +2,8 @@ package upbit
 
 import (
 	"context"
+	"slices"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/coinservice"
@@ -15,39 +15,80 @@ import (
 var _ coinservice.CoinService = (*Service)(nil)
 
 type Service struct {
-	upbit *Upbit
+	upbit  *Upbit
+	quotes []domain.Quote
 }
 
-func NewService() *Service {
-	upbit := NewUpbit()
-	return &Service{upbit: upbit}
+func NewService(opts ...Option) *Service {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	upbit := NewUpbit(opts...)
+	return &Service{upbit: upbit, quotes: options.Quotes}
+}
+
+// Exchange implements coinservice.CoinService.
+func (s *Service) Exchange() domain.Exchange {
+	return domain.ExchangeUpbit
 }
 
 func (s *Service) ListCoins(ctx context.Context) ([]*domain.Coin, error) {
 	now := time.Now()
-	coins, err := s.upbit.ListCoins(ctx)
+	var coins []*Coin
+	err := retry(func() error {
+		var err error
+		coins, err = s.upbit.ListCoins(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	var ret []*domain.Coin
 	for _, coin := range coins {
-		if !strings.HasPrefix(coin.Market, "KRW-") {
-			// 한국 화폐만 허용한다.
+		domainCoin := coin.ToDomain(now)
+		if !slices.Contains(s.quotes, domainCoin.Quote()) {
+			// 설정한 기준 화폐의 마켓만 허용한다.
 			continue
 		}
-		ret = append(ret, coin.ToDomain(now))
+		ret = append(ret, domainCoin)
 	}
 	return ret, nil
 }
 
+// Intervals implements coinservice.CoinService. 업비트는 모든 단위의 캔들을 준다.
+func (s *Service) Intervals() []domain.Interval {
+	return domain.Intervals()
+}
+
 // ListTrades implements coinservice.CoinService.
-func (s *Service) ListTrades(ctx context.Context, coinID domain.CoinID) (*domain.Trades, error) {
+func (s *Service) ListTrades(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+) (*domain.Trades, error) {
+	const enoughSize = 20
+	return s.ListTradesBefore(ctx, coinID, interval, time.Time{}, enoughSize)
+}
+
+// ListTradesBefore implements coinservice.CoinService.
+func (s *Service) ListTradesBefore(
+	ctx context.Context,
+	coinID domain.CoinID,
+	interval domain.Interval,
+	to time.Time,
+	count int,
+) (*domain.Trades, error) {
+	unit, err := candleUnit(interval)
+	if err != nil {
+		return nil, err
+	}
+	count = min(count, MaxCandleCount)
 	var candles []*Candle
 	now := time.Now()
-	err := retry(func() error {
+	err = retry(func() error {
 		var err error
-		const enoughSize = 20
-		candles, err = s.upbit.ListDayCandles(ctx, string(coinID), enoughSize)
+		candles, err = s.upbit.ListCandlesBefore(ctx, unit, coinID.Market(), to, count)
 		if err != nil {
 			return err
 		}
@@ -63,29 +104,57 @@ func (s *Service) ListTrades(ctx context.Context, coinID domain.CoinID) (*domain
 			return nil, errors.WithStack(err)
 		}
 
-		tradePrice := strconv.FormatFloat(candle.TradePrice, 'f', -1, 64)
-		openingPrice := strconv.FormatFloat(candle.OpeningPrice, 'f', -1, 64)
-		highPrice := strconv.FormatFloat(candle.HighPrice, 'f', -1, 64)
-		lowPrice := strconv.FormatFloat(candle.LowPrice, 'f', -1, 64)
-
 		ret = append(ret, domain.NewTrade(
 			dateTime,
-			domain.Price(tradePrice),
-			domain.Price(openingPrice),
-			domain.Price(highPrice),
-			domain.Price(lowPrice),
+			domain.Price(formatFloat(candle.TradePrice)),
+			domain.Price(formatFloat(candle.OpeningPrice)),
+			domain.Price(formatFloat(candle.HighPrice)),
+			domain.Price(formatFloat(candle.LowPrice)),
+		).SetVolume(
+			domain.Volume(formatFloat(candle.CandleAccTradeVolume)),
+			domain.Price(formatFloat(candle.CandleAccTradePrice)),
+		).SetChange(
+			domain.Price(formatFloat(candle.PrevClosingPrice)),
+			domain.Price(formatFloat(candle.ChangePrice)),
+			domain.Rate(formatFloat(candle.ChangeRate)),
 		))
 	}
-	return domain.NewTrades(coinID, now, ret), nil
+	return domain.NewTrades(coinID, interval, now, ret), nil
+}
+
+func candleUnit(interval domain.Interval) (string, error) {
+	switch interval {
+	case domain.IntervalMinute1,
+		domain.IntervalMinute3,
+		domain.IntervalMinute5,
+		domain.IntervalMinute15,
+		domain.IntervalMinute30,
+		domain.IntervalMinute60,
+		domain.IntervalMinute240:
+		minutes := int64(interval.Duration() / time.Minute)
+		return "minutes/" + strconv.FormatInt(minutes, 10), nil
+	case domain.IntervalDay:
+		return "days", nil
+	case domain.IntervalWeek:
+		return "weeks", nil
+	case domain.IntervalMonth:
+		return "months", nil
+	default:
+		return "", errors.Wrap(domain.ErrUnknownInterval, string(interval))
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
 }
 
+// retry 429를 받으면 다시 요청한다. 다음 요청은 Limiter가 한도가 풀릴 때까지 기다린다.
 func retry(fn func() error) error {
 	const retryCount = 60
 	for range retryCount {
 		err := fn()
 		if err != nil {
 			if errors.Is(err, http.ErrTooManyRequests) {
-				time.Sleep(1 * time.Second)
 				continue
 			}
 			return errors.WithStack(err)
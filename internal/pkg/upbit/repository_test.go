@@ -0,0 +1,134 @@
+package upbit_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit/upbittest"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) *upbittest.Server {
+	t.Helper()
+	server := upbittest.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestService_ListCoins(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	server.SetMarkets(upbittest.NewMarket("KRW-BTC"), upbittest.NewMarket("BTC-ETH"))
+	service := upbit.NewService(upbit.WithBaseURL(server.URL()))
+
+	coins, err := service.ListCoins(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, coins, 1)
+	require.Equal(t, domain.CoinID("upbit:KRW-BTC"), coins[0].ID())
+}
+
+func TestService_ListCoinsWithQuotes(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	server.SetMarkets(
+		upbittest.NewMarket("KRW-BTC"),
+		upbittest.NewMarket("BTC-ETH"),
+		upbittest.NewMarket("USDT-ETH"),
+	)
+	service := upbit.NewService(
+		upbit.WithBaseURL(server.URL()),
+		upbit.WithQuotes(domain.QuoteBTC, domain.QuoteUSDT),
+	)
+
+	coins, err := service.ListCoins(context.Background())
+
+	require.NoError(t, err)
+	var quotes []domain.Quote
+	for _, coin := range coins {
+		require.Equal(t, "ETH", coin.Base())
+		quotes = append(quotes, coin.Quote())
+	}
+	require.ElementsMatch(t, []domain.Quote{domain.QuoteBTC, domain.QuoteUSDT}, quotes)
+}
+
+func TestService_ListTradesBefore(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	server.SetCandles("days", "KRW-BTC", upbittest.NewDailyCandles("KRW-BTC", today, 5, 1000)...)
+	service := upbit.NewService(upbit.WithBaseURL(server.URL()))
+
+	trades, err := service.ListTradesBefore(context.Background(), "KRW-BTC", domain.IntervalDay, today, 2)
+
+	require.NoError(t, err)
+	require.Len(t, trades.Trades(), 2)
+	require.Equal(t, today.AddDate(0, 0, -2), trades.OldestDate())
+}
+
+func TestService_ListTradesRetriesTooManyRequests(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	server.SetCandles("days", "KRW-BTC", upbittest.NewDailyCandles("KRW-BTC", time.Now(), 3, 1000)...)
+	server.FailNext(http.StatusTooManyRequests, 1)
+	service := upbit.NewService(upbit.WithBaseURL(server.URL()))
+
+	trades, err := service.ListTrades(context.Background(), "KRW-BTC", domain.IntervalDay)
+
+	require.NoError(t, err)
+	require.Len(t, trades.Trades(), 3)
+	require.Equal(t, 2, server.Requests("/v1/candles/days"))
+}
+
+func TestService_ListTradesMalformed(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	server.SetMalformed("/v1/candles/days", true)
+	service := upbit.NewService(upbit.WithBaseURL(server.URL()))
+
+	_, err := service.ListTrades(context.Background(), "KRW-BTC", domain.IntervalDay)
+
+	require.Error(t, err)
+}
+
+func TestService_ListTradesCandleUnit(t *testing.T) {
+	t.Parallel()
+	tests := map[domain.Interval]string{
+		domain.IntervalMinute1:   "minutes/1",
+		domain.IntervalMinute15:  "minutes/15",
+		domain.IntervalMinute60:  "minutes/60",
+		domain.IntervalMinute240: "minutes/240",
+		domain.IntervalDay:       "days",
+		domain.IntervalWeek:      "weeks",
+		domain.IntervalMonth:     "months",
+	}
+	for interval, unit := range tests {
+		t.Run(string(interval), func(t *testing.T) {
+			t.Parallel()
+			server := newServer(t)
+			server.SetCandles(unit, "KRW-BTC", upbittest.NewCandle("KRW-BTC", time.Now(), 1000))
+			service := upbit.NewService(upbit.WithBaseURL(server.URL()))
+
+			trades, err := service.ListTrades(context.Background(), "KRW-BTC", interval)
+
+			require.NoError(t, err)
+			require.Equal(t, 1, trades.Size())
+			require.Equal(t, 1, server.Requests("/v1/candles/"+unit))
+		})
+	}
+}
+
+func TestService_ListTradesUnknownInterval(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	service := upbit.NewService(upbit.WithBaseURL(server.URL()))
+
+	_, err := service.ListTrades(context.Background(), "KRW-BTC", "2h")
+
+	require.ErrorIs(t, err, domain.ErrUnknownInterval)
+	require.Zero(t, server.Requests("/v1/candles/"))
+}
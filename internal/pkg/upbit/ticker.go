@@ -0,0 +1,44 @@
+package upbit
+
+import (
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+)
+
+// Ticker 웹소켓 ticker 스트림의 메시지.
+type Ticker struct {
+	Type              string  `json:"type"`
+	Code              string  `json:"code"`
+	OpeningPrice      float64 `json:"opening_price"`
+	HighPrice         float64 `json:"high_price"`
+	LowPrice          float64 `json:"low_price"`
+	TradePrice        float64 `json:"trade_price"`
+	PrevClosingPrice  float64 `json:"prev_closing_price"`
+	Change            string  `json:"change"`
+	SignedChangePrice float64 `json:"signed_change_price"`
+	SignedChangeRate  float64 `json:"signed_change_rate"`
+	AccTradeVolume24h float64 `json:"acc_trade_volume_24h"`
+	AccTradePrice24h  float64 `json:"acc_trade_price_24h"`
+	TradeTimestamp    int64   `json:"trade_timestamp"`
+	Timestamp         int64   `json:"timestamp"`
+	StreamType        string  `json:"stream_type"`
+}
+
+func (t *Ticker) ToDomain() *domain.Ticker {
+	return domain.NewTicker(
+		domain.NewCoinID(domain.ExchangeUpbit, t.Code),
+		domain.Price(formatFloat(t.TradePrice)),
+		time.UnixMilli(t.TradeTimestamp).UTC(),
+	).SetChange(
+		domain.Price(formatFloat(t.SignedChangePrice)),
+		domain.Rate(formatFloat(t.SignedChangeRate)),
+	).SetVolume(
+		domain.Volume(formatFloat(t.AccTradeVolume24h)),
+		domain.Price(formatFloat(t.AccTradePrice24h)),
+	).SetRange(
+		domain.Price(formatFloat(t.OpeningPrice)),
+		domain.Price(formatFloat(t.HighPrice)),
+		domain.Price(formatFloat(t.LowPrice)),
+	)
+}
@@ -6,25 +6,60 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/biosvos/coin-cache-service/internal/pkg/http"
 	"github.com/pkg/errors"
 )
 
 type Upbit struct {
-	client *http.Client
+	baseURL string
+	client  *http.Client
+	limiter *Limiter
 }
 
-func NewUpbit() *Upbit {
+// 업비트는 API를 그룹으로 묶어 요청 수를 제한한다.
+const (
+	marketGroup  = "market"
+	candlesGroup = "candles"
+)
+
+func NewUpbit(opts ...Option) *Upbit {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
 	client := http.NewClient()
-	return &Upbit{client: client}
+	return &Upbit{
+		baseURL: options.BaseURL,
+		client:  client,
+		limiter: NewLimiter(options.Tracer, options.RequestsPerSecond),
+	}
+}
+
+// get group의 요청 한도에 맞춰 요청을 보낸다.
+func (u *Upbit) get(ctx context.Context, group string, url string) (*http.Response, error) {
+	err := u.limiter.Wait(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.Get(ctx, url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		u.limiter.Throttled(group)
+		return nil, http.ErrTooManyRequests
+	}
+	u.limiter.Observe(group, resp.Headers)
+	return resp, nil
 }
 
 // ListCoins implements coinservice.CoinService.
 func (u *Upbit) ListCoins(ctx context.Context) ([]*Coin, error) {
-	resp, err := u.client.Get(ctx, "https://api.upbit.com/v1/market/all?is_details=true")
+	resp, err := u.get(ctx, marketGroup, u.baseURL+"/v1/market/all?is_details=true")
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.New(string(resp.Body))
@@ -36,25 +71,39 @@ func (u *Upbit) ListCoins(ctx context.Context) ([]*Coin, error) {
 	return ret, nil
 }
 
-// ListTrades implements coinservice.CoinService.
-func (u *Upbit) ListDayCandles(ctx context.Context, market string, count int) ([]*Candle, error) {
-	if count < 1 || 200 < count {
+const MaxCandleCount = 200
+
+// ListCandles unit 단위의 캔들을 최신순으로 조회한다.
+// unit은 "minutes/1", "days", "weeks", "months" 같은 API 경로이다.
+func (u *Upbit) ListCandles(ctx context.Context, unit string, market string, count int) ([]*Candle, error) {
+	return u.ListCandlesBefore(ctx, unit, market, time.Time{}, count)
+}
+
+// ListCandlesBefore to 이전의 캔들을 최신순으로 조회한다. to가 zero value면 가장 최근 캔들부터 조회한다.
+func (u *Upbit) ListCandlesBefore(
+	ctx context.Context,
+	unit string,
+	market string,
+	to time.Time,
+	count int,
+) ([]*Candle, error) {
+	if count < 1 || MaxCandleCount < count {
 		return nil, errors.New("count must be between 1 and 200")
 	}
 	values := url.Values{}
 	values.Add("market", market)
 	countString := strconv.FormatInt(int64(count), 10)
 	values.Add("count", countString)
+	if !to.IsZero() {
+		values.Add("to", to.UTC().Format(time.RFC3339))
+	}
 	encodedValues := values.Encode()
-	url := fmt.Sprintf("https://api.upbit.com/v1/candles/days?%v", encodedValues)
-	resp, err := u.client.Get(ctx, url)
+	url := fmt.Sprintf("%v/v1/candles/%v?%v", u.baseURL, unit, encodedValues)
+	resp, err := u.get(ctx, candlesGroup, url)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, http.ErrTooManyRequests
-		}
 		return nil, errors.New(string(resp.Body))
 	}
 	candles, err := UnmarshalSlice[Candle](resp.Body)
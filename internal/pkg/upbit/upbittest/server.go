@@ -0,0 +1,307 @@
+// Package upbittest 인터넷 없이 업비트 API를 흉내내는 테스트 서버를 제공한다.
+package upbittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+)
+
+const (
+	marketPath  = "/v1/market/all"
+	candlesPath = "/v1/candles/"
+
+	candleDateTimeLayout = "2006-01-02T15:04:05"
+
+	// defaultRequestsPerSecond Remaining-Req 헤더로 알려줄 그룹별 초당 요청 수.
+	defaultRequestsPerSecond = 30
+)
+
+// Server /v1/market/all과 /v1/candles/*를 미리 넣어둔 데이터로 응답한다.
+// 429, 지연, 깨진 응답을 주입해 파이프라인 전체를 결정적으로 시험할 수 있다.
+type Server struct {
+	server *httptest.Server
+
+	mu                sync.Mutex
+	markets           []*upbit.Coin
+	candles           map[string]map[string][]*upbit.Candle // unit -> market -> candles
+	failures          []int
+	latency           time.Duration
+	malformed         map[string]bool
+	requestsPerSecond int
+	windows           map[string]*window
+	requests          map[string]int
+
+	webSockets *webSockets
+}
+
+type window struct {
+	resetAt time.Time
+	used    int
+}
+
+func NewServer() *Server {
+	s := &Server{
+		server:            nil,
+		mu:                sync.Mutex{},
+		markets:           nil,
+		candles:           map[string]map[string][]*upbit.Candle{},
+		failures:          nil,
+		latency:           0,
+		malformed:         map[string]bool{},
+		requestsPerSecond: defaultRequestsPerSecond,
+		windows:           map[string]*window{},
+		requests:          map[string]int{},
+		webSockets:        newWebSockets(),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL upbit.WithBaseURL에 넣을 주소.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) Close() {
+	s.webSockets.closeAll()
+	s.server.Close()
+}
+
+// SetMarkets /v1/market/all 응답을 바꾼다.
+func (s *Server) SetMarkets(markets ...*upbit.Coin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markets = markets
+}
+
+// SetCandles unit("days", "minutes/1" 등)과 market의 캔들을 바꾼다. 순서는 상관없다.
+func (s *Server) SetCandles(unit string, market string, candles ...*upbit.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sorted := append([]*upbit.Candle(nil), candles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CandleDateTimeUtc > sorted[j].CandleDateTimeUtc
+	})
+	if s.candles[unit] == nil {
+		s.candles[unit] = map[string][]*upbit.Candle{}
+	}
+	s.candles[unit][market] = sorted
+}
+
+// FailNext 다음 count개의 요청에 status로 응답한다. e.g. FailNext(http.StatusTooManyRequests, 3)
+func (s *Server) FailNext(status int, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range count {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// SetLatency 모든 응답을 latency만큼 늦춘다.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// SetMalformed path로 시작하는 요청에 JSON이 아닌 본문을 돌려준다. e.g. "/v1/candles/days"
+func (s *Server) SetMalformed(path string, malformed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed[path] = malformed
+}
+
+// SetRequestsPerSecond Remaining-Req 헤더로 알려줄 그룹별 초당 요청 수를 바꾼다.
+func (s *Server) SetRequestsPerSecond(perSecond int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestsPerSecond = perSecond
+}
+
+// Requests path로 시작하는 요청을 받은 횟수.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret int
+	for requestPath, count := range s.requests {
+		if strings.HasPrefix(requestPath, path) {
+			ret += count
+		}
+	}
+	return ret
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == webSocketPath {
+		s.webSockets.serve(w, r)
+		return
+	}
+	status, latency, malformed := s.prepare(w, r)
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if malformed {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"malformed":`))
+		return
+	}
+	switch {
+	case r.URL.Path == marketPath:
+		s.serveMarkets(w)
+	case strings.HasPrefix(r.URL.Path, candlesPath):
+		s.serveCandles(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// prepare 요청을 기록하고 Remaining-Req 헤더를 붙인 뒤 주입할 장애를 꺼낸다.
+// 주입한 실패가 없더라도 초당 요청 수를 넘기면 429로 응답한다.
+func (s *Server) prepare(w http.ResponseWriter, r *http.Request) (int, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[r.URL.Path]++
+
+	group := "market"
+	if strings.HasPrefix(r.URL.Path, candlesPath) {
+		group = "candles"
+	}
+	now := time.Now()
+	current, ok := s.windows[group]
+	if !ok || !now.Before(current.resetAt) {
+		current = &window{resetAt: now.Add(time.Second), used: 0}
+		s.windows[group] = current
+	}
+	current.used++
+	remaining := max(s.requestsPerSecond-current.used, 0)
+	w.Header().Set("Remaining-Req", fmt.Sprintf("group=%v; min=%v; sec=%v", group, s.requestsPerSecond*60, remaining))
+
+	var status int
+	if len(s.failures) > 0 {
+		status = s.failures[0]
+		s.failures = s.failures[1:]
+	} else if current.used > s.requestsPerSecond {
+		status = http.StatusTooManyRequests
+	}
+
+	var malformed bool
+	for path, ok := range s.malformed {
+		if ok && strings.HasPrefix(r.URL.Path, path) {
+			malformed = true
+		}
+	}
+	return status, s.latency, malformed
+}
+
+func (s *Server) serveMarkets(w http.ResponseWriter) {
+	s.mu.Lock()
+	markets := s.markets
+	s.mu.Unlock()
+	if markets == nil {
+		markets = []*upbit.Coin{}
+	}
+	writeJSON(w, markets)
+}
+
+// serveCandles market의 캔들 중 to 이전의 것을 최신순으로 count개 돌려준다.
+func (s *Server) serveCandles(w http.ResponseWriter, r *http.Request) {
+	unit := strings.TrimPrefix(r.URL.Path, candlesPath)
+	query := r.URL.Query()
+	market := query.Get("market")
+	count := 1
+	if value := query.Get("count"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || upbit.MaxCandleCount < n {
+			http.Error(w, "invalid count", http.StatusBadRequest)
+			return
+		}
+		count = n
+	}
+	var to time.Time
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+		to = parsed.UTC()
+	}
+
+	s.mu.Lock()
+	candles := s.candles[unit][market]
+	s.mu.Unlock()
+	ret := []*upbit.Candle{}
+	for _, candle := range candles {
+		if len(ret) == count {
+			break
+		}
+		if !to.IsZero() && candle.CandleDateTimeUtc >= to.Format(candleDateTimeLayout) {
+			continue
+		}
+		ret = append(ret, candle)
+	}
+	writeJSON(w, ret)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// NewMarket 경고가 없는 마켓.
+func NewMarket(market string) *upbit.Coin {
+	return &upbit.Coin{
+		Market:      market,
+		KoreanName:  market,
+		EnglishName: market,
+		MarketEvent: &upbit.MarketEvent{Warning: false, Caution: nil},
+	}
+}
+
+// NewCandle date에 시가, 고가, 저가, 종가가 모두 price인 캔들.
+func NewCandle(market string, date time.Time, price float64) *upbit.Candle {
+	date = date.UTC()
+	kst := date.In(time.FixedZone("KST", 9*60*60)) //nolint:mnd
+	return &upbit.Candle{
+		Market:               market,
+		CandleDateTimeUtc:    date.Format(candleDateTimeLayout),
+		CandleDateTimeKst:    kst.Format(candleDateTimeLayout),
+		OpeningPrice:         price,
+		HighPrice:            price,
+		LowPrice:             price,
+		TradePrice:           price,
+		Timestamp:            date.UnixMilli(),
+		CandleAccTradePrice:  0,
+		CandleAccTradeVolume: 0,
+		PrevClosingPrice:     price,
+		ChangePrice:          0,
+		ChangeRate:           0,
+	}
+}
+
+// NewDailyCandles today부터 하루씩 거슬러 올라가는 days개의 일봉.
+func NewDailyCandles(market string, today time.Time, days int, price float64) []*upbit.Candle {
+	today = today.UTC().Truncate(24 * time.Hour)
+	ret := make([]*upbit.Candle, 0, days)
+	for i := range days {
+		ret = append(ret, NewCandle(market, today.AddDate(0, 0, -i), price))
+	}
+	return ret
+}
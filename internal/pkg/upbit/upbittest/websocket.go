@@ -0,0 +1,126 @@
+package upbittest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/coder/websocket"
+)
+
+const webSocketPath = "/websocket/v1"
+
+// WebSocketURL upbit.WithWebSocketURL에 넣을 주소.
+func (s *Server) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http") + webSocketPath
+}
+
+// PublishTicker ticker.Code를 구독 중인 모든 연결에 ticker를 보낸다.
+func (s *Server) PublishTicker(ticker *upbit.Ticker) {
+	ticker.Type = "ticker"
+	payload, err := json.Marshal(ticker)
+	if err != nil {
+		panic(err)
+	}
+	for _, conn := range s.webSockets.subscribers(ticker.Code) {
+		_ = conn.Write(context.Background(), websocket.MessageBinary, payload)
+	}
+}
+
+// DropConnections 모든 웹소켓 연결을 끊는다. 재연결을 시험할 때 쓴다.
+func (s *Server) DropConnections() {
+	s.webSockets.closeAll()
+}
+
+// Subscriptions 지금까지 받은 구독 요청의 마켓 목록을 순서대로 돌려준다.
+func (s *Server) Subscriptions() [][]string {
+	return s.webSockets.history()
+}
+
+type webSockets struct {
+	mu            sync.Mutex
+	conns         map[*websocket.Conn][]string
+	subscriptions [][]string
+}
+
+func newWebSockets() *webSockets {
+	return &webSockets{
+		mu:            sync.Mutex{},
+		conns:         map[*websocket.Conn][]string{},
+		subscriptions: nil,
+	}
+}
+
+// serve 연결마다 마지막 구독 요청의 마켓만 보낸다. 업비트와 같다.
+func (w *webSockets) serve(rw http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(rw, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.CloseNow() }()
+	w.mu.Lock()
+	w.conns[conn] = nil
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.conns, conn)
+		w.mu.Unlock()
+	}()
+
+	for {
+		_, data, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+		var request []map[string]json.RawMessage
+		err = json.Unmarshal(data, &request)
+		if err != nil {
+			_ = conn.Close(websocket.StatusUnsupportedData, "invalid request")
+			return
+		}
+		for _, field := range request {
+			var codes []string
+			if json.Unmarshal(field["codes"], &codes) != nil {
+				continue
+			}
+			w.mu.Lock()
+			w.conns[conn] = codes
+			w.subscriptions = append(w.subscriptions, codes)
+			w.mu.Unlock()
+		}
+	}
+}
+
+func (w *webSockets) subscribers(code string) []*websocket.Conn {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var ret []*websocket.Conn
+	for conn, codes := range w.conns {
+		if slices.Contains(codes, code) {
+			ret = append(ret, conn)
+		}
+	}
+	return ret
+}
+
+func (w *webSockets) closeAll() {
+	w.mu.Lock()
+	conns := make([]*websocket.Conn, 0, len(w.conns))
+	for conn := range w.conns {
+		conns = append(conns, conn)
+	}
+	w.mu.Unlock()
+	for _, conn := range conns {
+		_ = conn.CloseNow()
+	}
+}
+
+func (w *webSockets) history() [][]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.subscriptions)
+}
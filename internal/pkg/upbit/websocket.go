@@ -0,0 +1,165 @@
+package upbit
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// TickerStream 업비트 웹소켓으로 ticker를 받는다. 연결이 끊기면 점점 길게 기다렸다가 다시 연결하고 구독을 복구한다.
+type TickerStream struct {
+	url        string
+	tracer     tracer.Tracer
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu      sync.Mutex
+	markets []string
+	changed chan struct{}
+}
+
+func NewTickerStream(opts ...Option) *TickerStream {
+	options := NewOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &TickerStream{
+		url:        options.WebSocketURL,
+		tracer:     options.Tracer,
+		minBackoff: options.MinReconnectBackoff,
+		maxBackoff: options.MaxReconnectBackoff,
+		mu:         sync.Mutex{},
+		markets:    nil,
+		changed:    make(chan struct{}, 1),
+	}
+}
+
+// Exchange 업비트 코인만 구독할 수 있다.
+func (s *TickerStream) Exchange() domain.Exchange {
+	return domain.ExchangeUpbit
+}
+
+// Subscribe 구독할 코인을 coinIDs로 바꾼다. 연결되어 있다면 즉시 다시 구독한다.
+func (s *TickerStream) Subscribe(coinIDs []domain.CoinID) {
+	markets := make([]string, 0, len(coinIDs))
+	for _, coinID := range coinIDs {
+		markets = append(markets, coinID.Market())
+	}
+	slices.Sort(markets)
+
+	s.mu.Lock()
+	s.markets = markets
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default: // 이미 알렸다.
+	}
+}
+
+func (s *TickerStream) subscribedMarkets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.markets
+}
+
+// Run ctx가 끝날 때까지 받은 ticker를 handle에 넘긴다.
+func (s *TickerStream) Run(ctx context.Context, handle func(*domain.Ticker)) {
+	backoff := s.minBackoff
+	for {
+		received, err := s.connect(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = s.minBackoff
+		}
+		_, span := s.tracer.Start(ctx, "upbit.TickerStream.reconnect")
+		span.Error(err)
+		span.Int64("backoff_ms", backoff.Milliseconds())
+		span.End()
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, s.maxBackoff) //nolint:mnd
+	}
+}
+
+// connect 연결 하나가 끊길 때까지 ticker를 받는다. 하나라도 받았다면 true를 반환한다.
+func (s *TickerStream) connect(ctx context.Context, handle func(*domain.Ticker)) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, s.url, nil) //nolint:bodyclose
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	defer func() { _ = conn.CloseNow() }()
+	const readLimit = 1 << 20
+	conn.SetReadLimit(readLimit)
+
+	go s.subscribeLoop(ctx, cancel, conn)
+
+	var received bool
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return received, errors.WithStack(err)
+		}
+		var ticker Ticker
+		err = json.Unmarshal(data, &ticker)
+		if err != nil || ticker.Type != "ticker" {
+			continue // 상태 메시지 등은 무시한다.
+		}
+		received = true
+		handle(ticker.ToDomain())
+	}
+}
+
+// subscribeLoop 연결 직후와 구독 대상이 바뀔 때마다 구독 요청을 보낸다.
+func (s *TickerStream) subscribeLoop(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	select {
+	case <-s.changed: // 연결 전에 바뀐 구독은 첫 요청에 이미 반영된다.
+	default:
+	}
+	for {
+		markets := s.subscribedMarkets()
+		if len(markets) > 0 {
+			err := conn.Write(ctx, websocket.MessageText, subscribeMessage(markets))
+			if err != nil {
+				cancel()
+				return
+			}
+		}
+		select {
+		case <-s.changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// subscribeMessage 새 요청은 같은 연결의 이전 구독을 대체한다.
+func subscribeMessage(markets []string) []byte {
+	message := []any{
+		map[string]string{"ticket": uuid.NewString()},
+		map[string]any{"type": "ticker", "codes": markets},
+		map[string]string{"format": "DEFAULT"},
+	}
+	ret, err := json.Marshal(message)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
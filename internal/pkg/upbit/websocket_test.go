@@ -0,0 +1,65 @@
+package upbit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/biosvos/coin-cache-service/internal/pkg/domain"
+	"github.com/biosvos/coin-cache-service/internal/pkg/upbit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickerStream_ReconnectsAndResubscribes(t *testing.T) {
+	t.Parallel()
+	server := newServer(t)
+	stream := upbit.NewTickerStream(
+		upbit.WithWebSocketURL(server.WebSocketURL()),
+		upbit.WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond),
+	)
+	stream.Subscribe([]domain.CoinID{"upbit:KRW-BTC"})
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var prices []domain.Price
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stream.Run(ctx, func(ticker *domain.Ticker) {
+			mu.Lock()
+			defer mu.Unlock()
+			prices = append(prices, ticker.Price())
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	received := func(count int) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(prices) >= count
+		}
+	}
+	publish := func(price float64) func() bool {
+		return func() bool {
+			server.PublishTicker(&upbit.Ticker{Code: "KRW-BTC", TradePrice: price}) //nolint:exhaustruct
+			return true
+		}
+	}
+
+	require.Eventually(t, func() bool { return len(server.Subscriptions()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return publish(100)() && received(1)() }, 5*time.Second, 10*time.Millisecond)
+	server.DropConnections()
+	require.Eventually(t, func() bool { return len(server.Subscriptions()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return publish(200)() && received(2)() }, 5*time.Second, 10*time.Millisecond)
+	stream.Subscribe([]domain.CoinID{"upbit:KRW-BTC", "upbit:KRW-ETH"})
+	require.Eventually(t, func() bool { return len(server.Subscriptions()) == 3 }, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, [][]string{{"KRW-BTC"}, {"KRW-BTC"}, {"KRW-BTC", "KRW-ETH"}}, server.Subscriptions())
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, domain.Price("100"), prices[0])
+	require.Equal(t, domain.Price("200"), prices[len(prices)-1])
+}
@@ -0,0 +1,47 @@
+package noop
+
+import (
+	"context"
+
+	"github.com/biosvos/coin-cache-service/pkg/tracer"
+)
+
+var (
+	_ tracer.Tracer = (*Tracer)(nil)
+	_ tracer.Span   = (*Span)(nil)
+)
+
+// Tracer 아무것도 기록하지 않는 tracer. 테스트 등 추적이 필요 없는 곳에서 사용한다.
+type Tracer struct{}
+
+func NewTracer() *Tracer {
+	return &Tracer{}
+}
+
+func (t *Tracer) Start(ctx context.Context, _ string) (context.Context, tracer.Span) {
+	return ctx, &Span{}
+}
+
+func (t *Tracer) Inject(context.Context) map[string]string {
+	return nil
+}
+
+func (t *Tracer) StartLinked(ctx context.Context, _ string, _ map[string]string) (context.Context, tracer.Span) {
+	return ctx, &Span{}
+}
+
+func (t *Tracer) Shutdown() {}
+
+type Span struct{}
+
+func (s *Span) End() {}
+
+func (s *Span) String(string, string) {}
+
+func (s *Span) Int64(string, int64) {}
+
+func (s *Span) Bool(string, bool) {}
+
+func (s *Span) Float64(string, float64) {}
+
+func (s *Span) Error(error) {}
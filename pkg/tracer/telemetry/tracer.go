@@ -10,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
@@ -79,6 +80,29 @@ func (t *Tracer) Start(ctx context.Context, name string) (context.Context, trace
 	return ctx, newSpan(span)            //nolint:spancheck
 }
 
+// Inject implements tracer.Tracer.
+func (t *Tracer) Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// StartLinked implements tracer.Tracer. 새 span은 ctx의 span을 부모로 하고 carrier의 span을 link로 가진다.
+func (t *Tracer) StartLinked(ctx context.Context, name string, carrier map[string]string) (context.Context, tracer.Span) {
+	linked := tracecontext.SpanContextFromContext(
+		propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(carrier)),
+	)
+	var opts []tracecontext.SpanStartOption
+	if linked.IsValid() {
+		opts = append(opts, tracecontext.WithLinks(tracecontext.Link{SpanContext: linked})) //nolint:exhaustruct
+	}
+	ctx, span := t.provider.Tracer(name).Start(ctx, name, opts...) //nolint:spancheck
+	return ctx, newSpan(span)                                      //nolint:spancheck
+}
+
 // Shutdown implements tracer.Tracer.
 func (t *Tracer) Shutdown() {
 	err := t.provider.Shutdown(context.Background())
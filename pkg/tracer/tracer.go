@@ -4,6 +4,11 @@ import "context"
 
 type Tracer interface {
 	Start(ctx context.Context, name string) (context.Context, Span)
+	// Inject ctx의 span을 W3C trace context(traceparent, tracestate)로 옮겨 담는다.
+	Inject(ctx context.Context) map[string]string
+	// StartLinked carrier가 가리키는 span에 link로 이어진 새 span을 시작한다.
+	// 이벤트처럼 다른 때, 다른 곳에서 이어지는 작업을 추적할 때 쓴다.
+	StartLinked(ctx context.Context, name string, carrier map[string]string) (context.Context, Span)
 	Shutdown()
 }
 